@@ -4,13 +4,11 @@ import (
 	"path/filepath"
 	"strconv"
 
-	"github.com/bogem/id3v2/v2"
 	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/downloader"
-	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/tag"
 	"github.com/streambinder/spotitube/lyrics"
 	"github.com/streambinder/spotitube/processor"
-	"github.com/streambinder/spotitube/spotify"
 	"github.com/streambinder/spotitube/util"
 )
 
@@ -29,15 +27,16 @@ func cmdAttach() *cobra.Command {
 				path   = args[0]
 				id     = args[1]
 				rename = util.ErrWrap(false)(cmd.Flags().GetBool("rename"))
+				like   = util.ErrWrap(false)(cmd.Flags().GetBool("like"))
 			)
 
-			localTrack, err := id3.Open(path, id3v2.Options{Parse: false})
+			localTrack, err := tag.Open(path, false)
 			if err != nil {
 				return err
 			}
 			defer localTrack.Close()
 
-			client, err := spotify.Authenticate(spotify.BrowserProcessor)
+			client, err := sessionClient()
 			if err != nil {
 				return err
 			}
@@ -47,14 +46,14 @@ func cmdAttach() *cobra.Command {
 				return err
 			}
 
-			uslt, err := lyrics.Search(spotifyTrack)
+			uslt, err := lyrics.Search(cmd.Context(), spotifyTrack)
 			if err != nil {
 				return err
 			}
 
 			artwork := make(chan []byte, 1)
 			defer close(artwork)
-			if err := downloader.Download(
+			if err := downloader.Download(cmd.Context(),
 				spotifyTrack.Artwork.URL, spotifyTrack.Path().Artwork(),
 				processor.Artwork{}, artwork); err != nil {
 				return err
@@ -63,19 +62,29 @@ func cmdAttach() *cobra.Command {
 			localTrack.SetSpotifyID(spotifyTrack.ID)
 			localTrack.SetTitle(spotifyTrack.Title)
 			localTrack.SetArtist(spotifyTrack.Artists[0])
+			localTrack.SetArtists(spotifyTrack.Artists)
 			localTrack.SetAlbum(spotifyTrack.Album)
 			localTrack.SetArtworkURL(spotifyTrack.Artwork.URL)
 			localTrack.SetAttachedPicture(<-artwork)
 			localTrack.SetDuration(strconv.Itoa(spotifyTrack.Duration))
 			localTrack.SetUnsynchronizedLyrics(spotifyTrack.Title, uslt)
 			localTrack.SetTrackNumber(strconv.Itoa(spotifyTrack.Number))
+			localTrack.SetDiscNumber(strconv.Itoa(spotifyTrack.Disc))
 			localTrack.SetYear(strconv.Itoa(spotifyTrack.Year))
 			localTrack.SetUpstreamURL(spotifyTrack.UpstreamURL)
 
-			if err := localTrack.Save(); err != nil {
+			if err := localTrack.Save(cmd.Context()); err != nil {
 				return err
 			}
 
+			// the next library synchronization
+			// gets to know about the track too
+			if like {
+				if err := client.Like(spotifyTrack.ID); err != nil {
+					return err
+				}
+			}
+
 			if rename {
 				return util.FileMoveOrCopy(path, filepath.Join(filepath.Dir(path), spotifyTrack.Path().Final()))
 			}
@@ -83,5 +92,6 @@ func cmdAttach() *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolP("rename", "r", false, "Rename local track to comply with Spotify counterpart")
+	cmd.Flags().Bool("like", false, "Save the Spotify counterpart into the library, i.e. among the liked songs")
 	return cmd
 }
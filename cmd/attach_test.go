@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestCmdAttach(t *testing.T) {
 		ApplyFunc(lyrics.Search, func() (string, error) {
 			return "", nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			ch[0] <- []byte{}
 			return nil
 		}).
@@ -160,7 +161,7 @@ func TestCmdAttachSaveFailure(t *testing.T) {
 		ApplyFunc(lyrics.Search, func() (string, error) {
 			return "", nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			ch[0] <- []byte{}
 			return nil
 		}).
@@ -190,7 +191,7 @@ func TestCmdAttachRenameFailure(t *testing.T) {
 		ApplyFunc(lyrics.Search, func() (string, error) {
 			return "", nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			ch[0] <- []byte{}
 			return nil
 		}).
@@ -205,3 +206,74 @@ func TestCmdAttachRenameFailure(t *testing.T) {
 	// testing
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdAttach(), "--rename", "/path", "spotifyid")), "ko")
 }
+
+func TestCmdAttachLike(t *testing.T) {
+	var (
+		_track = &entity.Track{ID: "TestCmdAttachLike", Title: "Title", Artists: []string{"Artist"}}
+		liked  []string
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+			return id3v2.NewEmptyTag(), nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
+			return _track, nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "", nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			ch[0] <- []byte{}
+			return nil
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Save", func() error {
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Like", func(_ *spotify.Client, ids ...string) error {
+			liked = append(liked, ids...)
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, util.ErrOnly(testExecute(cmdAttach(), "--like", "/path", "spotifyid")))
+	assert.Equal(t, []string{"TestCmdAttachLike"}, liked)
+}
+
+func TestCmdAttachLikeFailure(t *testing.T) {
+	_track := &entity.Track{ID: "TestCmdAttachLikeFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+			return id3v2.NewEmptyTag(), nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
+			return _track, nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "", nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			ch[0] <- []byte{}
+			return nil
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Save", func() error {
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Like", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdAttach(), "--like", "/path", "spotifyid")), "ko")
+}
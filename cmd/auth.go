@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"log"
-	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/spotify"
 	"github.com/streambinder/spotitube/util"
 )
 
+const authTimeFormat = "2006-01-02 15:04:05 MST"
+
 var printProcessor = func(url string) error {
 	log.Println("Authenticate at:", url)
 	return nil
@@ -29,9 +34,15 @@ func cmdAuth() *cobra.Command {
 			var (
 				remote    = util.ErrWrap(false)(cmd.Flags().GetBool("remote"))
 				logout    = util.ErrWrap(false)(cmd.Flags().GetBool("logout"))
+				list      = util.ErrWrap(false)(cmd.Flags().GetBool("list"))
+				check     = util.ErrWrap(false)(cmd.Flags().GetBool("check"))
 				callback  = "127.0.0.1"
 				processor = spotify.BrowserProcessor
 			)
+			if list {
+				return authList(cmd.Context(), cmd.OutOrStdout(), check)
+			}
+
 			if remote {
 				log.Println("In order for remote authentication to work, set DNS/hosts entry to make `spotitube.local` resolve to the Spotitube server")
 				callback = "spotitube.local"
@@ -39,7 +50,7 @@ func cmdAuth() *cobra.Command {
 			}
 
 			if logout {
-				if err := os.Remove(util.CacheFile(spotify.TokenBasename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				if err := spotify.Logout(); err != nil {
 					return err
 				}
 			}
@@ -49,5 +60,57 @@ func cmdAuth() *cobra.Command {
 	}
 	cmd.Flags().BoolP("remote", "r", false, "Spotitube server is remote")
 	cmd.Flags().BoolP("logout", "l", false, "Logout before starting authentication process")
+	cmd.Flags().Bool("list", false, "List profiles along with the status of their sessions")
+	cmd.Flags().Bool("check", false, "Check listed sessions against Spotify, refreshing expired tokens")
 	return cmd
 }
+
+// authList writes which profiles are logged in and until when their
+// stored token is valid: only if asked to check the sessions, tokens
+// get refreshed, if expired, for the user of each of them to be told
+func authList(ctx context.Context, out io.Writer, check bool) error {
+	profiles, err := util.Profiles()
+	if err != nil {
+		return err
+	}
+
+	current := util.Profile()
+	defer func() { util.ErrSuppress(util.SetProfile(current)) }()
+	for _, profile := range append([]string{""}, profiles...) {
+		if err := util.SetProfile(profile); err != nil {
+			return err
+		}
+
+		name := util.Fallback(profile, "default")
+		token, err := spotify.StoredToken()
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(out, "%s: logged out\n", name)
+			continue
+		} else if err != nil {
+			fmt.Fprintf(out, "%s: %s\n", name, err)
+			continue
+		}
+
+		if !check {
+			if token.Expiry.Before(time.Now()) {
+				fmt.Fprintf(out, "%s: logged in, token expired on %s (refreshed on next use)\n", name, token.Expiry.Format(authTimeFormat))
+			} else {
+				fmt.Fprintf(out, "%s: logged in, token expiring on %s\n", name, token.Expiry.Format(authTimeFormat))
+			}
+			continue
+		}
+
+		client, err := spotify.NewFlow(nil).Recover()
+		if err != nil {
+			fmt.Fprintf(out, "%s: %s\n", name, err)
+			continue
+		}
+		session, err := client.Session(ctx)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %s\n", name, err)
+			continue
+		}
+		fmt.Fprintf(out, "%s: logged in as %s, token expiring on %s\n", name, session.User, session.Expiry.Format(authTimeFormat))
+	}
+	return nil
+}
@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io/fs"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/streambinder/spotitube/spotify"
 	"github.com/streambinder/spotitube/util"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 func BenchmarkAuth(b *testing.B) {
@@ -52,3 +57,97 @@ func TestCmdAuthLogoutFailure(t *testing.T) {
 	// testing
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdAuth(), "--logout")), "ko")
 }
+
+func TestCmdAuthList(t *testing.T) {
+	var out bytes.Buffer
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.Profiles, func() ([]string, error) {
+			return []string{"alice", "bob", "carol"}, nil
+		}).
+		ApplyFunc(spotify.StoredToken, func() (*oauth2.Token, error) {
+			switch util.Profile() {
+			case "":
+				return nil, fs.ErrNotExist
+			case "bob":
+				return nil, errors.New("ko")
+			case "carol":
+				return &oauth2.Token{Expiry: time.Date(2023, 4, 15, 12, 0, 0, 0, time.UTC)}, nil
+			}
+			return &oauth2.Token{Expiry: time.Date(2999, 4, 15, 12, 0, 0, 0, time.UTC)}, nil
+		}).
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			t.Error("sessions are not to be recovered unless checked")
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdAuth(), "--list"))
+	assert.Nil(t, authList(context.Background(), &out, false))
+	assert.Equal(t, "default: logged out\n"+
+		"alice: logged in, token expiring on 2999-04-15 12:00:00 UTC\n"+
+		"bob: ko\n"+
+		"carol: logged in, token expired on 2023-04-15 12:00:00 UTC (refreshed on next use)\n", out.String())
+	assert.Empty(t, util.Profile())
+}
+
+func TestCmdAuthListCheck(t *testing.T) {
+	var out bytes.Buffer
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.Profiles, func() ([]string, error) {
+			return []string{"alice", "bob", "carol"}, nil
+		}).
+		ApplyFunc(spotify.StoredToken, func() (*oauth2.Token, error) {
+			if util.Profile() == "" {
+				return nil, fs.ErrNotExist
+			}
+			return &oauth2.Token{}, nil
+		}).
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			if util.Profile() == "bob" {
+				return nil, errors.New("ko")
+			}
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Session", func() (*spotify.Session, error) {
+			if util.Profile() == "carol" {
+				return nil, errors.New("ko")
+			}
+			return &spotify.Session{User: "Alice", Expiry: time.Date(2023, 4, 15, 12, 0, 0, 0, time.UTC)}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdAuth(), "--list", "--check"))
+	assert.Nil(t, authList(context.Background(), &out, true))
+	assert.Equal(t, "default: logged out\n"+
+		"alice: logged in as Alice, token expiring on 2023-04-15 12:00:00 UTC\n"+
+		"bob: ko\n"+
+		"carol: ko\n", out.String())
+	assert.Empty(t, util.Profile())
+}
+
+func TestCmdAuthListFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.Profiles, func() ([]string, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdAuth(), "--list"), "ko")
+}
+
+func TestCmdAuthListProfileFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.Profiles, func() ([]string, error) {
+		return []string{"../alice"}, nil
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdAuth(), "--list"), "invalid profile name: ../alice")
+	assert.Empty(t, util.Profile())
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/util"
+)
+
+const (
+	daemonHealthBasename = "health.json"
+	daemonLibrary        = "library"
+
+	healthSyncing = "syncing"
+	healthOK      = "ok"
+	healthFailing = "failing"
+)
+
+// health is the status of a daemon, as of its latest synchronization
+type health struct {
+	Status   string        `json:"status"` // syncing, ok or failing
+	Updated  time.Time     `json:"updated"`
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout,omitempty"` // longest a synchronization may take
+	Error    string        `json:"error,omitempty"`
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdDaemon())
+}
+
+func cmdDaemon() *cobra.Command {
+	// a daemon is a synchronization repeated over and
+	// over again, hence it understands the very same flags
+	cmd := cmdSync()
+	cmd.Use = "daemon"
+	cmd.Short = "Keep collections synchronized, polling them for changes"
+	runSync := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var (
+			path            = outputPath(cmd, "output")
+			library         = util.ErrWrap(false)(cmd.Flags().GetBool("library"))
+			playlists       = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist"))
+			playlistsTracks = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist-tracks"))
+			archives        = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("archive"))
+			dryRun          = util.ErrWrap(false)(cmd.Flags().GetBool("dry-run"))
+			interval        = util.ErrWrap(15 * time.Minute)(cmd.Flags().GetDuration("interval"))
+			timeout         = util.ErrWrap(6 * time.Hour)(cmd.Flags().GetDuration("sync-timeout"))
+			healthcheck     = util.ErrWrap(false)(cmd.Flags().GetBool("healthcheck"))
+			snapshots       = make(map[string]string) // by collection
+			synced          = false                   // whether every collection got synchronized once
+		)
+
+		if healthcheck {
+			return daemonHealthcheck()
+		}
+		if dryRun {
+			return errors.New("daemon cannot be dry-run")
+		}
+		if interval <= 0 {
+			return errors.New("interval must be positive")
+		}
+		if timeout <= 0 {
+			return errors.New("synchronization timeout must be positive")
+		}
+
+		// every synchronization moves into the output path
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		util.ErrSuppress(cmd.Flags().Set("output", path))
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		cmd.SetContext(ctx)
+
+		// a daemon which is gone leaves no health behind
+		syncAlive = true
+		defer func() {
+			syncAlive = false
+			util.ErrSuppress(os.Remove(daemonHealthPath()))
+		}()
+		if spotifyClient, err = syncAuthenticate(util.ErrWrap(false)(cmd.Flags().GetBool("client-credentials"))); err != nil {
+			return err
+		}
+
+		for cycle := 0; ; cycle++ {
+			if cycle > 0 {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(interval):
+				}
+			}
+
+			changes, err := daemonPoll(library, append(playlists, append(playlistsTracks, archives...)...), snapshots)
+			if ctx.Err() != nil {
+				return nil
+			} else if err != nil {
+				tui.AnchorPrintf("polling failed: %s", err)
+				util.ErrSuppress(daemonHealth(healthFailing, interval, timeout, err))
+				continue
+			}
+
+			// saved albums, new releases, albums, artists, tracks and fixes are not polled: they get
+			// synchronized once, just like resuming and pruning take place only until a cycle
+			// synchronizing every collection succeeds
+			if synced {
+				if len(changes) == 0 {
+					util.ErrSuppress(daemonHealth(healthOK, interval, timeout, nil))
+					continue
+				}
+				daemonSetCollections(cmd, len(changes[daemonLibrary]) > 0, daemonChanged(playlists, changes),
+					daemonChanged(playlistsTracks, changes), daemonChanged(archives, changes))
+				for _, flag := range []string{"resume", "prune"} {
+					util.ErrSuppress(cmd.Flags().Set(flag, "false"))
+				}
+			}
+
+			util.ErrSuppress(daemonHealth(healthSyncing, interval, timeout, nil))
+			if err := cmd.PreRunE(cmd, args); err != nil {
+				return err
+			}
+			err = runSync(cmd, args)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// partial synchronizations are not worth
+			// repeating until collections change again
+			var exitErr *exitError
+			if err == nil || errors.As(err, &exitErr) {
+				for collection, snapshot := range changes {
+					snapshots[collection] = snapshot
+				}
+				synced = true
+				util.ErrSuppress(daemonHealth(healthOK, interval, timeout, nil))
+			} else {
+				tui.AnchorPrintf("synchronization failed: %s", err)
+				util.ErrSuppress(daemonHealth(healthFailing, interval, timeout, err))
+			}
+
+			// tracks which could not be synchronized
+			// are given another chance on the next cycle
+			indexData.Forget(index.Online)
+		}
+	}
+	cmd.Flags().Duration("interval", 15*time.Minute, "Time to wait between polls for collection changes")
+	cmd.Flags().Duration("sync-timeout", 6*time.Hour, "Time a synchronization may take before the daemon is reported as stuck")
+	cmd.Flags().Bool("healthcheck", false, "Check the health of the running daemon and exit")
+	return cmd
+}
+
+// daemonPoll returns the current snapshot of those among the
+// given collections which changed since the given snapshots
+func daemonPoll(library bool, playlists []string, snapshots map[string]string) (map[string]string, error) {
+	changes := make(map[string]string)
+	if library {
+		snapshot, err := spotifyClient.LibrarySnapshot()
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != snapshots[daemonLibrary] {
+			changes[daemonLibrary] = snapshot
+		}
+	}
+
+	for _, playlist := range playlists {
+		snapshot, err := spotifyClient.PlaylistSnapshot(playlist)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != snapshots["playlist:"+playlist] {
+			changes["playlist:"+playlist] = snapshot
+		}
+	}
+	return changes, nil
+}
+
+// daemonChanged returns those among the given playlists which changed
+func daemonChanged(playlists []string, changes map[string]string) (changed []string) {
+	changed = []string{}
+	for _, playlist := range playlists {
+		if _, ok := changes["playlist:"+playlist]; ok {
+			changed = append(changed, playlist)
+		}
+	}
+	return
+}
+
+// daemonSetCollections sets the collections the next synchronization
+// is going to go through, i.e. the library and the given playlists only
+func daemonSetCollections(cmd *cobra.Command, library bool, playlists, playlistsTracks, archives []string) {
+	util.ErrSuppress(cmd.Flags().Set("library", strconv.FormatBool(library)))
+	for _, flag := range []string{"saved-albums", "new-releases"} {
+		util.ErrSuppress(cmd.Flags().Set(flag, "false"))
+	}
+	for flag, values := range map[string][]string{
+		"playlist":        playlists,
+		"playlist-tracks": playlistsTracks,
+		"archive":         archives,
+		"album":           {},
+		"artist":          {},
+		"track":           {},
+		"fix":             {},
+	} {
+		util.ErrSuppress(cmd.Flags().Lookup(flag).Value.(pflag.SliceValue).Replace(values))
+	}
+}
+
+// daemonHealthPath returns the path the health
+// of the daemon of the profile in use is kept at
+func daemonHealthPath() string {
+	return util.ProfileFile(daemonHealthBasename)
+}
+
+// daemonHealth persists the health of the daemon
+func daemonHealth(status string, interval, timeout time.Duration, err error) error {
+	health := health{status, time.Now(), interval, timeout, ""}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	data, err := json.Marshal(health)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(daemonHealthPath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(daemonHealthPath(), data, 0o644)
+}
+
+// daemonHealthcheck tells whether the daemon is healthy, i.e. its latest
+// synchronization went fine, or is still going on for no longer than it
+// may, and it is still polling for changes: with no daemon health of the
+// profile in use, there is no daemon to check, e.g. as a container runs
+// any other subcommand, and nothing is reported
+func daemonHealthcheck() error {
+	data, err := os.ReadFile(daemonHealthPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var health health
+	if err := json.Unmarshal(data, &health); err != nil {
+		return err
+	}
+
+	switch {
+	case health.Status == healthFailing:
+		return errors.New("daemon failing: " + health.Error)
+	case health.Status == healthSyncing && health.Timeout > 0 && time.Since(health.Updated) > health.Timeout:
+		return fmt.Errorf("daemon synchronizing since %s", health.Updated.Format(time.RFC3339))
+	case health.Status == healthOK && time.Since(health.Updated) > 2*health.Interval+time.Minute:
+		return fmt.Errorf("daemon stuck since %s", health.Updated.Format(time.RFC3339))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/spf13/cobra"
+	"github.com/streambinder/spotitube/downloader"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/processor"
+	"github.com/streambinder/spotitube/provider"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkDaemon(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestCmdDaemon(&testing.T{})
+	}
+}
+
+// testHealth writes the given health for the daemon
+func testHealth(t *testing.T, health health) {
+	data, err := json.Marshal(health)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(daemonHealthPath(), data, 0o644))
+}
+
+func TestCmdDaemon(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache     = t.TempDir()
+		_track    = &entity.Track{ID: "TestCmdDaemon", Title: "Title", Artists: []string{"Artist"}}
+		_playlist = &playlist.Playlist{Name: "TestCmdDaemon", Tracks: []*entity.Track{_track}}
+		cancels   []context.CancelFunc
+		snapshots = []string{"a", "a", "b", "b"}
+		polls     int
+		fetches   int
+		builds    int
+		health    health
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			cancels = append(cancels, cancel)
+			return ctx, cancel
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			builds++
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			polls++
+			if polls == len(snapshots) {
+				assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(cache, daemonHealthBasename))), &health))
+				assert.Nil(t, daemonHealthcheck())
+				cancels[0]() // the daemon one
+			}
+			return snapshots[polls-1], nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			fetches++
+			for _, c := range ch {
+				c <- _track
+			}
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdDaemon(), "--interval", "1ms", "-p", "123"))
+	assert.Equal(t, len(snapshots), polls)
+	assert.Equal(t, 2, fetches)
+	assert.Equal(t, 2, builds) // every synchronization builds the index again
+	assert.False(t, syncAlive)
+	assert.Equal(t, healthOK, health.Status)
+	assert.NoFileExists(t, filepath.Join(cache, daemonHealthBasename))
+	assert.Nil(t, testExecute(cmdDaemon(), "--healthcheck"))
+}
+
+func TestCmdDaemonFirstCycleFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache     = t.TempDir()
+		_playlist = &playlist.Playlist{Name: "TestCmdDaemonFirstCycleFailure", Tracks: []*entity.Track{}}
+		cancels   []context.CancelFunc
+		polls     int
+		albums    int
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			cancels = append(cancels, cancel)
+			return ctx, cancel
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			polls++
+			switch polls {
+			case 1:
+				return "", errors.New("ko")
+			case 3:
+				cancels[0]() // the daemon one
+			}
+			return "a", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return _playlist, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Album", func() (*entity.Album, error) {
+			albums++
+			return &entity.Album{Tracks: []*entity.Track{}}, nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdDaemon(), "--interval", "1ms", "-p", "123", "-a", "456"))
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, 1, albums)
+}
+
+func TestCmdDaemonHealthcheck(t *testing.T) {
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.Nil(t, daemonHealthcheck())
+	testHealth(t, health{healthSyncing, time.Now().Add(-time.Hour), time.Minute, 2 * time.Hour, ""})
+	assert.Nil(t, daemonHealthcheck())
+	testHealth(t, health{healthSyncing, time.Now().Add(-3 * time.Hour), time.Minute, 2 * time.Hour, ""})
+	assert.ErrorContains(t, daemonHealthcheck(), "daemon synchronizing since")
+	testHealth(t, health{healthOK, time.Now(), time.Minute, time.Hour, ""})
+	assert.Nil(t, daemonHealthcheck())
+	testHealth(t, health{healthOK, time.Now().Add(-time.Hour), time.Minute, time.Hour, ""})
+	assert.ErrorContains(t, daemonHealthcheck(), "daemon stuck since")
+	testHealth(t, health{healthFailing, time.Now(), time.Minute, time.Hour, "ko"})
+	assert.EqualError(t, daemonHealthcheck(), "daemon failing: ko")
+	assert.Nil(t, os.WriteFile(daemonHealthPath(), []byte("{"), 0o644))
+	assert.Error(t, daemonHealthcheck())
+}
+
+func TestCmdDaemonFailure(t *testing.T) {
+	assert.EqualError(t, testExecute(cmdDaemon(), "-n"), "daemon cannot be dry-run")
+	assert.EqualError(t, testExecute(cmdDaemon(), "--interval", "0s"), "interval must be positive")
+	assert.EqualError(t, testExecute(cmdDaemon(), "--sync-timeout", "0s"), "synchronization timeout must be positive")
+}
+
+func TestCmdDaemonAuthFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdDaemon(), "-p", "123"), "ko")
+	assert.False(t, syncAlive)
+}
+
+func TestCmdDaemonPollFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var cancel context.CancelFunc
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(parent)
+			return ctx, cancel
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "LibrarySnapshot", func() (string, error) {
+			return "", errors.New("ko")
+		}).
+		ApplyFunc(daemonHealth, func(status string, _, _ time.Duration, err error) error {
+			assert.Equal(t, healthFailing, status)
+			assert.EqualError(t, err, "ko")
+			cancel()
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdDaemon(), "-l"))
+}
+
+func TestCmdDaemonLibrary(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache     = t.TempDir()
+		cancels   []context.CancelFunc
+		libraries int
+		health    health
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			cancels = append(cancels, cancel)
+			return ctx, cancel
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "LibrarySnapshot", func() (string, error) {
+			return "a", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func() error {
+			libraries++
+			if libraries == 2 {
+				assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(cache, daemonHealthBasename))), &health))
+				cancels[0]() // the daemon one
+			}
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdDaemon(), "--interval", "1ms", "-o", t.TempDir(), "-l"))
+	assert.Equal(t, 2, libraries) // failed synchronizations get repeated
+	assert.Equal(t, healthSyncing, health.Status)
+}
+
+func TestCmdDaemonPathFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.Abs, func() (string, error) {
+		return "", errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdDaemon(), "-p", "123"), "ko")
+}
+
+func TestCmdDaemonPreRunFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache     = t.TempDir()
+		_playlist = &playlist.Playlist{Name: "TestCmdDaemonPreRunFailure", Tracks: []*entity.Track{}}
+		polls     int
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.AuthenticateApp, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			polls++
+			return strconv.Itoa(polls), nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return _playlist, nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
+		ApplyFunc(daemonSetCollections, func(cmd *cobra.Command, _ bool, _, _, _ []string) {
+			assert.Nil(t, cmd.Flags().Set("library", "true"))
+		}).
+		Reset()
+
+	// testing
+	assert.ErrorIs(t, testExecute(cmdDaemon(), "--interval", "1ms", "-o", t.TempDir(), "-p", "123", "--client-credentials"), spotify.ErrUserRequired)
+	assert.Equal(t, 2, polls)
+}
+
+func TestCmdDaemonHealthFailure(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "file")
+	assert.Nil(t, os.WriteFile(cache, []byte{}, 0o644))
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.Error(t, daemonHealth(healthOK, time.Minute, time.Hour, nil))
+	cache = t.TempDir()
+	assert.Nil(t, os.Mkdir(daemonHealthPath(), 0o755))
+	assert.Error(t, daemonHealthcheck())
+}
+
+func TestCmdDaemonHealthMarshalFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+	}).Reset()
+
+	// testing
+	assert.Error(t, daemonHealth(healthOK, time.Minute, time.Hour, nil))
+}
@@ -12,11 +12,10 @@ import (
 	"strings"
 
 	"github.com/adrg/xdg"
-	"github.com/bogem/id3v2/v2"
 	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/downloader"
-	"github.com/streambinder/spotitube/entity/id3"
 	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/tag"
 	"github.com/streambinder/spotitube/processor"
 	spotitubify "github.com/streambinder/spotitube/spotify"
 	"github.com/streambinder/spotitube/util"
@@ -33,7 +32,10 @@ func cmdInit() *cobra.Command {
 		Short: "Init ID3v2 data for local library",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var dir = util.ErrWrap(xdg.UserDirs.Music)(cmd.Flags().GetString("library"))
+			var (
+				dir  = outputPath(cmd, "library")
+				like = util.ErrWrap(false)(cmd.Flags().GetBool("like"))
+			)
 
 			client, err := authenticateSpotify()
 
@@ -41,7 +43,7 @@ func cmdInit() *cobra.Command {
 				log.Fatalf("Failed to authenticate with Spotify: %v", err)
 			}
 
-			err = processDirectory(dir, client)
+			err = processDirectory(dir, client, like)
 			if err != nil {
 				log.Fatalf("Error processing directory: %v", err)
 			}
@@ -50,6 +52,7 @@ func cmdInit() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringP("library", "l", xdg.UserDirs.Music, "Path to music library")
+	cmd.Flags().Bool("like", false, "Save matched tracks into the Spotify library, i.e. among the liked songs")
 	return cmd
 }
 
@@ -61,7 +64,7 @@ func authenticateSpotify() (*spotitubify.Client, error) {
 	return client, nil
 }
 
-func processDirectory(dir string, client *spotitubify.Client) error {
+func processDirectory(dir string, client *spotitubify.Client, like bool) error {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %v", err)
@@ -75,7 +78,7 @@ func processDirectory(dir string, client *spotitubify.Client) error {
 		filePath := filepath.Join(dir, entry.Name())
 		fmt.Printf("Processing file: %s\n", filePath)
 
-		err := processFile(filePath, client)
+		err := processFile(filePath, client, like)
 		if err != nil {
 			log.Printf("Error processing file %s: %v\n", filePath, err)
 		}
@@ -83,9 +86,9 @@ func processDirectory(dir string, client *spotitubify.Client) error {
 	return nil
 }
 
-func processFile(filePath string, client *spotitubify.Client) error {
+func processFile(filePath string, client *spotitubify.Client, like bool) error {
 	// Open the MP3 file to check for existing ID3v2 tags
-	mp3File, err := id3.Open(filePath, id3v2.Options{Parse: true})
+	mp3File, err := tag.Open(filePath, true)
 	if err != nil {
 		return fmt.Errorf("failed to open mp3 file: %v", err)
 	}
@@ -131,7 +134,17 @@ func processFile(filePath string, client *spotitubify.Client) error {
 	}
 
 	// Update MP3 tags
-	return updateMP3Tags(client, filePath, track)
+	if err := updateMP3Tags(client, filePath, track); err != nil {
+		return err
+	}
+
+	// Save the track into the library, for the next sync to know about it
+	if like {
+		if err := client.Like(track.ID.String()); err != nil {
+			return fmt.Errorf("failed to like track: %v", err)
+		}
+	}
+	return nil
 }
 
 func removeFeaturingInfo(title string) string {
@@ -187,7 +200,7 @@ func promptForTrack(client *spotify.Client, artist, title string, results []*spo
 }
 
 func updateMP3Tags(client *spotitubify.Client, filePath string, track *spotify.FullTrack) error {
-	mp3File, err := id3.Open(filePath, id3v2.Options{Parse: true})
+	mp3File, err := tag.Open(filePath, true)
 	if err != nil {
 		return fmt.Errorf("failed to open mp3 file: %v", err)
 	}
@@ -200,7 +213,7 @@ func updateMP3Tags(client *spotitubify.Client, filePath string, track *spotify.F
 
 	artwork := make(chan []byte, 1)
 	defer close(artwork)
-	if err := downloader.Download(
+	if err := downloader.Download(context.Background(),
 		spotifyTrack.Artwork.URL, spotifyTrack.Path().Artwork(),
 		processor.Artwork{}, artwork); err != nil {
 		return err
@@ -209,15 +222,17 @@ func updateMP3Tags(client *spotitubify.Client, filePath string, track *spotify.F
 	mp3File.SetSpotifyID(spotifyTrack.ID)
 	mp3File.SetTitle(spotifyTrack.Title)
 	mp3File.SetArtist(spotifyTrack.Artists[0])
+	mp3File.SetArtists(spotifyTrack.Artists)
 	mp3File.SetAlbum(spotifyTrack.Album)
 	mp3File.SetArtworkURL(spotifyTrack.Artwork.URL)
 	mp3File.SetAttachedPicture(<-artwork)
 	mp3File.SetDuration(strconv.Itoa(spotifyTrack.Duration))
 	mp3File.SetTrackNumber(strconv.Itoa(spotifyTrack.Number))
+	mp3File.SetDiscNumber(strconv.Itoa(spotifyTrack.Disc))
 	mp3File.SetYear(strconv.Itoa(spotifyTrack.Year))
 	mp3File.SetUpstreamURL(spotifyTrack.UpstreamURL)
 
-	if err := mp3File.Save(); err != nil {
+	if err := mp3File.Save(context.Background()); err != nil {
 		return fmt.Errorf("failed to save mp3 file: %v", err)
 	}
 
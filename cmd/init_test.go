@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/downloader"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/processor"
+	spotitubify "github.com/streambinder/spotitube/spotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/zmb3/spotify/v2"
+)
+
+// testInitTrack returns a track as found upstream
+func testInitTrack(id, name string) spotify.FullTrack {
+	return spotify.FullTrack{SimpleTrack: spotify.SimpleTrack{
+		ID:      spotify.ID(id),
+		Name:    name,
+		Artists: []spotify.SimpleArtist{{Name: "Artist"}},
+	}}
+}
+
+// testInitStdin makes the given input the one typed by the user
+func testInitStdin(t *testing.T, input string) {
+	path := filepath.Join(t.TempDir(), "stdin")
+	assert.Nil(t, os.WriteFile(path, []byte(input), 0o644))
+	stdin, err := os.Open(path)
+	assert.Nil(t, err)
+
+	original := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() {
+		os.Stdin = original
+		stdin.Close()
+	})
+}
+
+// testInit runs the given test with anything an initialization goes through
+// stubbed out: upstream, the given results are found and the given stub fails
+func testInit(results *[]spotify.FullTrack, failing *string, test func()) {
+	fail := func(stub string) error {
+		if *failing == stub {
+			return errors.New("ko")
+		}
+		return nil
+	}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotitubify.Authenticate, func() (*spotitubify.Client, error) {
+			return &spotitubify.Client{Client: &spotify.Client{}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+			if results == nil {
+				return &spotify.SearchResult{}, fail("Search")
+			}
+			return &spotify.SearchResult{Tracks: &spotify.FullTrackPage{Tracks: *results}}, fail("Search")
+		}).
+		ApplyMethod(&spotitubify.Client{}, "Track", func(_ *spotitubify.Client, id string) (*entity.Track, error) {
+			return &entity.Track{ID: id, Title: "Title", Artists: []string{"Artist"}}, fail("Track")
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return fail("Download")
+		}).
+		ApplyMethod(&id3.Tag{}, "SpotifyID", func() string {
+			if fail("SpotifyID") != nil {
+				return "TestInit"
+			}
+			return ""
+		}).
+		ApplyMethod(&id3.Tag{}, "Save", func() error {
+			return fail("Save")
+		}).
+		ApplyMethod(&spotitubify.Client{}, "Like", func() error {
+			return fail("Like")
+		}).
+		Reset()
+
+	// testing
+	test()
+}
+
+func TestCmdInit(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		dir     = t.TempDir()
+		results = []spotify.FullTrack{testInitTrack("TestCmdInit", "Title")}
+		failing = ""
+	)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Artist - Title.mp3"), []byte{}, 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Artist - Cover.jpg"), []byte{}, 0o644))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "Artist - Folder.mp3"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Title.mp3"), []byte{}, 0o644))
+
+	// testing
+	testInit(&results, &failing, func() {
+		assert.Nil(t, testExecute(cmdInit(), "-l", dir, "--like"))
+	})
+	assert.Equal(t, 1, indexData.Size())
+}
+
+func TestCmdInitFailure(t *testing.T) {
+	var failures []string
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotitubify.Authenticate, func() (*spotitubify.Client, error) {
+			return nil, errors.New("ko")
+		}).
+		ApplyFunc(log.Fatalf, func(format string, v ...interface{}) {
+			failures = append(failures, fmt.Sprintf(format, v...))
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdInit(), "-l", filepath.Join(t.TempDir(), "missing")))
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "Failed to authenticate with Spotify: ko", failures[0])
+	assert.Contains(t, failures[1], "Error processing directory: failed to read directory")
+}
+
+func TestProcessFile(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		dir     = t.TempDir()
+		path    = filepath.Join(dir, "Artist - Title (feat. Other).mp3")
+		client  = &spotitubify.Client{Client: &spotify.Client{}}
+		results = []spotify.FullTrack{}
+		failing = ""
+	)
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Title.mp3"), []byte{}, 0o644))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "Artist - Folder.mp3"), 0o755))
+
+	testInit(&results, &failing, func() {
+		// testing: unexpected files
+		assert.ErrorContains(t, processFile(filepath.Join(dir, "Artist - Folder.mp3"), client, false), "failed to open mp3 file")
+		assert.EqualError(t, processFile(filepath.Join(dir, "Title.mp3"), client, false), "invalid file name format: Title.mp3")
+
+		// testing: no results, skipped or wrongly chosen by the user
+		testInitStdin(t, "\n")
+		assert.Nil(t, processFile(path, client, false))
+		testInitStdin(t, "1\n")
+		assert.EqualError(t, processFile(path, client, false), "error during manual track selection: invalid choice")
+
+		// testing: one inexact result, picked
+		results = []spotify.FullTrack{testInitTrack("TestProcessFileOne", "Title (Remastered)")}
+		assert.Nil(t, processFile(path, client, false))
+
+		// testing: several inexact results, chosen by the user, then liked
+		results = append(results, testInitTrack("TestProcessFileTwo", "Title (Live)"))
+		testInitStdin(t, "2\n")
+		failing = "Like"
+		assert.EqualError(t, processFile(path, client, true), "failed to like track: ko")
+
+		// testing: tags failing to get updated
+		results = results[:1]
+		failing = "Track"
+		assert.EqualError(t, processFile(path, client, false), "ko")
+
+		// testing: search failure
+		failing = "Search"
+		assert.EqualError(t, processFile(path, client, false), "error searching Spotify: ko")
+
+		// testing: files tagged already
+		failing = "SpotifyID"
+		assert.Nil(t, processFile(path, client, false))
+	})
+}
+
+func TestSearchSpotify(t *testing.T) {
+	failing := ""
+
+	// testing
+	testInit(nil, &failing, func() {
+		tracks, err := searchSpotify(&spotify.Client{}, "Artist", "Title")
+		assert.Nil(t, err)
+		assert.Empty(t, tracks)
+	})
+}
+
+func TestUpdateMP3TagsFailure(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		path    = filepath.Join(dir, "Artist - Title.mp3")
+		client  = &spotitubify.Client{Client: &spotify.Client{}}
+		track   = testInitTrack("TestUpdateMP3TagsFailure", "Title")
+		failing = "Save"
+	)
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+
+	// testing
+	testInit(nil, &failing, func() {
+		assert.ErrorContains(t, updateMP3Tags(client, dir, &track), "failed to open mp3 file")
+		assert.EqualError(t, updateMP3Tags(client, path, &track), "failed to save mp3 file: ko")
+		failing = "Download"
+		assert.EqualError(t, updateMP3Tags(client, path, &track), "ko")
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/arunsworld/nursery"
 	"github.com/spf13/cobra"
@@ -39,7 +41,7 @@ func cmdLookup() *cobra.Command {
 			}
 
 			var authErr error
-			spotifyClient, authErr = spotify.Authenticate(spotify.BrowserProcessor)
+			spotifyClient, authErr = sessionClient()
 			if authErr != nil {
 				return authErr
 			}
@@ -50,8 +52,8 @@ func cmdLookup() *cobra.Command {
 			)
 			return nursery.RunConcurrently(
 				routineLookupFetch(random, library, randomSize, libraryLimit, args, providerChannel, lyricsChannel),
-				routineLookupProvider(providerChannel),
-				routineLookupLyrics(lyricsChannel),
+				routineLookupProvider(cmd.OutOrStdout(), providerChannel),
+				routineLookupLyrics(cmd.OutOrStdout(), lyricsChannel),
 			)
 		},
 	}
@@ -74,7 +76,7 @@ func routineLookupFetch(random, library bool, randomSize, libraryLimit int, ids
 				return
 			}
 		case library:
-			if err := spotifyClient.Library(libraryLimit, providerChannel, lyricsChannel); err != nil {
+			if err := spotifyClient.Library(libraryLimit, time.Time{}, providerChannel, lyricsChannel); err != nil {
 				ch <- err
 				return
 			}
@@ -89,37 +91,37 @@ func routineLookupFetch(random, library bool, randomSize, libraryLimit int, ids
 	}
 }
 
-func routineLookupProvider(providerChannel chan interface{}) func(context.Context, chan error) {
-	return func(context.Context, chan error) {
+func routineLookupProvider(out io.Writer, providerChannel chan interface{}) func(context.Context, chan error) {
+	return func(ctx context.Context, _ chan error) {
 		prefix := "[P]"
 		for event := range providerChannel {
 			track := event.(*entity.Track)
-			matches, err := provider.Search(track)
+			matches, err := provider.Search(ctx, track)
 			switch {
 			case err != nil:
-				fmt.Println(colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), err, colorReset)
+				fmt.Fprintln(out, colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), err, colorReset)
 			case len(matches) == 0:
-				fmt.Println(colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), "no result", colorReset)
+				fmt.Fprintln(out, colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), "no result", colorReset)
 			default:
-				fmt.Println(prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), matches[0].URL, matches[0].Score)
+				fmt.Fprintln(out, prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), matches[0].URL, matches[0].Score)
 			}
 		}
 	}
 }
 
-func routineLookupLyrics(lyricsChannel chan interface{}) func(context.Context, chan error) {
-	return func(context.Context, chan error) {
+func routineLookupLyrics(out io.Writer, lyricsChannel chan interface{}) func(context.Context, chan error) {
+	return func(ctx context.Context, _ chan error) {
 		prefix := "[L]"
 		for event := range lyricsChannel {
 			track := event.(*entity.Track)
-			lyrics, err := lyrics.Search(track)
+			lyrics, err := lyrics.Search(ctx, track)
 			switch {
 			case err != nil:
-				fmt.Println(colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), err, colorReset)
+				fmt.Fprintln(out, colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), err, colorReset)
 			case len(lyrics) == 0:
-				fmt.Println(colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), "no result", colorReset)
+				fmt.Fprintln(out, colorRed+prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), "no result", colorReset)
 			default:
-				fmt.Println(prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), util.Excerpt(lyrics, 80))
+				fmt.Fprintln(out, prefix, track.ID, util.Pad(track.Artists[0]), util.Pad(track.Title), util.Excerpt(lyrics, 80))
 			}
 		}
 	}
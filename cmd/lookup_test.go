@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/streambinder/spotitube/entity"
@@ -27,7 +28,7 @@ func TestCmdLookup(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			ch[1] <- _track
 			return nil
@@ -110,7 +111,7 @@ func TestCmdLookupLibraryFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, _ ...chan interface{}) error {
 			return errors.New("ko")
 		}).
 		Reset()
@@ -155,7 +156,7 @@ func TestCmdLookupSearchFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			ch[1] <- _track
 			return nil
@@ -180,7 +181,7 @@ func TestCmdLookupSearchNotFound(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			ch[1] <- _track
 			return nil
@@ -205,7 +206,7 @@ func TestCmdLookupLyricsFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			ch[1] <- _track
 			return nil
@@ -230,7 +231,7 @@ func TestCmdLookupLyricsNotFound(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			ch[1] <- _track
 			return nil
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util"
+)
+
+const (
+	planDownload  = "download"  // to be downloaded from the chosen match
+	planResume    = "resume"    // journaled by an interrupted synchronization
+	planInstalled = "installed" // already on disk, with a matching ID
+	planRetag     = "retag"     // already on disk, with a different ID to be updated
+	planIndexed   = "indexed"   // already synchronized, somewhere else on disk
+	planDuplicate = "duplicate" // already handled earlier during the synchronization
+	planNotFound  = "not found" // no match found upstream
+)
+
+var planFormats = []string{"table", "json"}
+
+// plan is what a synchronization would do,
+// as collected during a dry run of it
+type plan struct {
+	Tracks    []planTrack    `json:"tracks"`
+	Playlists []planPlaylist `json:"playlists"`
+	Prune     []string       `json:"prune"`
+	installs  map[string]bool
+	lock      sync.Mutex
+}
+
+type planTrack struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+	Path   string `json:"path"` // relative to the output path
+	URL    string `json:"url,omitempty"`
+	Score  int    `json:"score,omitempty"`
+}
+
+type planPlaylist struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"` // relative to the output path
+	Tracks int    `json:"tracks"`
+}
+
+func newPlan() *plan {
+	return &plan{
+		Tracks:    []planTrack{},
+		Playlists: []planPlaylist{},
+		Prune:     []string{},
+		installs:  make(map[string]bool),
+	}
+}
+
+// add takes note of the action the synchronization would take for
+// the given track: all the plan methods are no-op on a nil plan,
+// i.e. whenever the synchronization is not a dry run
+func (plan *plan) add(action string, track *entity.Track) {
+	if plan == nil {
+		return
+	}
+
+	entry := planTrack{action, track.ID, track.Title, track.Artists[0], track.Path().Final(), track.UpstreamURL, 0}
+	if action == planDownload && len(track.Matches) > 0 {
+		entry.Score = track.Matches[0].Score
+	}
+
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	plan.Tracks = append(plan.Tracks, entry)
+	if action == planDownload || action == planResume {
+		plan.installs[track.ID] = true
+	}
+}
+
+// installed tells whether the given track would get installed
+func (plan *plan) installed(track *entity.Track) bool {
+	if plan == nil {
+		return false
+	}
+
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	return plan.installs[track.ID]
+}
+
+func (plan *plan) addPlaylist(name, path string, tracks int) {
+	if plan == nil {
+		return
+	}
+
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	plan.Playlists = append(plan.Playlists, planPlaylist{name, path, tracks})
+}
+
+func (plan *plan) addPrune(path string) {
+	if plan == nil {
+		return
+	}
+
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	plan.Prune = append(plan.Prune, path)
+}
+
+// print writes the plan to the given output,
+// either as a table or as JSON
+func (plan *plan) print(output io.Writer, format string) error {
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		return util.ErrOnly(fmt.Fprintln(output, string(data)))
+	case "table":
+		table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ACTION\tTRACK\tPATH\tURL\tSCORE")
+		for _, track := range plan.Tracks {
+			score := ""
+			if track.Score > 0 {
+				score = fmt.Sprint(track.Score)
+			}
+			fmt.Fprintf(table, "%s\t%s by %s\t%s\t%s\t%s\n",
+				track.Action, track.Title, track.Artist, track.Path, track.URL, score)
+		}
+		for _, playlist := range plan.Playlists {
+			fmt.Fprintf(table, "mix\t%s (%d tracks)\t%s\t\t\n", playlist.Name, playlist.Tracks, playlist.Path)
+		}
+		for _, path := range plan.Prune {
+			fmt.Fprintf(table, "prune\t\t%s\t\t\n", path)
+		}
+		return table.Flush()
+	default:
+		return errors.New("unsupported plan format: " + format)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkPlan(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestPlanTable(&testing.T{})
+		TestPlanJSON(&testing.T{})
+	}
+}
+
+// testPlan returns a plan with a bit of everything in it
+func testPlan() *plan {
+	var (
+		plan  = newPlan()
+		track = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}}
+	)
+	track.UpstreamURL = "http://localhost/"
+	track.Matches = []*entity.Match{{URL: track.UpstreamURL, Score: 42}}
+	plan.add(planDownload, track)
+	plan.add(planInstalled, &entity.Track{ID: "other", Title: "Other", Artists: []string{"Artist"}})
+	plan.addPlaylist("Playlist", "playlist.m3u", 2)
+	plan.addPrune("Artist - Stale.mp3")
+	return plan
+}
+
+// testPlanPrint returns what the given plan prints in the given format
+func testPlanPrint(t *testing.T, plan *plan, format string) string {
+	var output bytes.Buffer
+	assert.Nil(t, plan.print(&output, format))
+	return output.String()
+}
+
+func TestPlanTable(t *testing.T) {
+	output := testPlanPrint(t, testPlan(), "table")
+	assert.Regexp(t, `download +Title by Artist +Artist - Title.mp3 +http://localhost/ +42`, output)
+	assert.Regexp(t, `installed +Other by Artist +Artist - Other.mp3`, output)
+	assert.Regexp(t, `mix +Playlist \(2 tracks\) +playlist.m3u`, output)
+	assert.Regexp(t, `prune +Artist - Stale.mp3`, output)
+}
+
+func TestPlanJSON(t *testing.T) {
+	var plan plan
+	assert.Nil(t, json.Unmarshal([]byte(testPlanPrint(t, testPlan(), "json")), &plan))
+	assert.Equal(t, testPlan().Tracks, plan.Tracks)
+	assert.Equal(t, testPlan().Playlists, plan.Playlists)
+	assert.Equal(t, []string{"Artist - Stale.mp3"}, plan.Prune)
+}
+
+func TestPlanFormatFailure(t *testing.T) {
+	assert.EqualError(t, newPlan().print(io.Discard, "xml"), "unsupported plan format: xml")
+}
+
+func TestPlanNil(t *testing.T) {
+	var plan *plan
+	plan.add(planDownload, &entity.Track{Artists: []string{"Artist"}})
+	plan.addPlaylist("Playlist", "playlist.m3u", 0)
+	plan.addPrune("path")
+	assert.False(t, plan.installed(&entity.Track{}))
+}
+
+func TestPlanMarshalFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(json.MarshalIndent, func() ([]byte, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, newPlan().print(io.Discard, "json"), "ko")
+}
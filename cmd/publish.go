@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/entity/tag"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+)
+
+func init() {
+	cmdRoot.AddCommand(cmdPublish())
+}
+
+func cmdPublish() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "publish",
+		Short:        "Publish a local playlist, or a directory of tracks, to a Spotify playlist",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				path   = args[0]
+				name   = util.ErrWrap("")(cmd.Flags().GetString("name"))
+				dryRun = util.ErrWrap(false)(cmd.Flags().GetBool("dry-run"))
+				out    = cmd.OutOrStdout()
+			)
+			if len(name) == 0 {
+				name = util.FileBaseStem(filepath.Base(util.ErrWrap(path)(filepath.Abs(path))))
+			}
+
+			paths, err := publishEntries(path)
+			if err != nil {
+				return err
+			}
+
+			if spotifyClient, err = spotify.Authenticate(spotify.BrowserProcessor); err != nil {
+				return err
+			}
+
+			// entries which cannot be resolved are reported,
+			// not standing in the way of the other ones
+			var (
+				ids        []string
+				unresolved int
+			)
+			for _, path := range paths {
+				id, err := publishResolve(path)
+				if err != nil {
+					unresolved++
+					fmt.Fprintf(out, "%s (unresolved: %s)\n", path, err)
+					continue
+				}
+				ids = append(ids, id)
+				fmt.Fprintf(out, "%s ⟶ %s\n", path, id)
+			}
+
+			if !dryRun {
+				published, err := spotifyClient.Publish(name, ids)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%d tracks published to %s (%s)\n", len(ids), published.Name, published.ID)
+			}
+
+			if unresolved > 0 {
+				return &exitError{fmt.Errorf("publication partially complete: %d entries not resolved", unresolved), exitPartial}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("name", "", "Name (or ID) of the Spotify playlist to publish to, created if missing (defaults to the local one)")
+	cmd.Flags().BoolP("dry-run", "n", false, "Only show how local tracks resolve, with no playlist published")
+	return cmd
+}
+
+// publishEntries returns the paths of the tracks to be published, in order:
+// the entries of the given M3U or PLS playlist file or the track files
+// within the given directory
+func publishEntries(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return playlist.Entries(path)
+	}
+
+	paths := []string{}
+	if err := filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && entity.IsTrackFile(path) {
+			paths = append(paths, path)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// publishResolve returns the Spotify ID of the track at the given path: the one
+// it is tagged with, if any, or the one of the track its title and artist lead to
+func publishResolve(path string) (string, error) {
+	tag, err := tag.Open(path, true)
+	if err != nil {
+		return "", err
+	}
+	defer tag.Close()
+
+	if id := tag.SpotifyID(); len(id) > 0 {
+		return id, nil
+	}
+
+	title, artist := tag.Title(), tag.Artist()
+	if len(title) == 0 {
+		// untagged tracks are usually named after their artist and title
+		stem := util.FileBaseStem(filepath.Base(path))
+		if before, after, ok := strings.Cut(stem, " - "); ok {
+			artist, title = before, after
+		} else {
+			title = stem
+		}
+	}
+
+	track, err := spotifyClient.SearchTrack(title, artist)
+	if err != nil {
+		return "", err
+	}
+	if track == nil {
+		return "", errors.New("no track found")
+	}
+	return track.ID, nil
+}
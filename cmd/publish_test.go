@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/bogem/id3v2/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPublishLibrary writes a tagged track, an untagged one, a track
+// which cannot be found and a playlist pointing to them all
+func testPublishLibrary(t *testing.T) string {
+	library := t.TempDir()
+	for name, id := range map[string]string{
+		"Tagged.mp3":           "tagged",
+		"Artist - Title.mp3":   "",
+		"Artist - Unknown.mp3": "",
+	} {
+		path := filepath.Join(library, name)
+		assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+		tag, err := id3.Open(path, id3v2.Options{Parse: true})
+		assert.Nil(t, err)
+		tag.SetSpotifyID(id)
+		assert.Nil(t, tag.Save(context.Background()))
+		assert.Nil(t, tag.Close())
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(library, "playlist.m3u"), []byte(`#EXTM3U
+#PLAYLIST:Playlist
+Artist - Title.mp3
+Tagged.mp3
+Missing.mp3
+Artist - Unknown.mp3
+`), 0o644))
+	return library
+}
+
+func BenchmarkPublish(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestCmdPublish(&testing.T{})
+	}
+}
+
+func TestCmdPublish(t *testing.T) {
+	var (
+		library   = testPublishLibrary(t)
+		published = make(map[string][]string)
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "SearchTrack", func(_ *spotify.Client, title, artist string) (*entity.Track, error) {
+			assert.Equal(t, "Artist", artist)
+			if title == "Title" {
+				return &entity.Track{ID: "searched"}, nil
+			}
+			return nil, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Publish", func(_ *spotify.Client, name string, ids []string) (*playlist.Playlist, error) {
+			published[name] = append([]string{}, ids...)
+			return &playlist.Playlist{ID: "123", Name: name}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdPublish(), filepath.Join(library, "playlist.m3u")),
+		"publication partially complete: 2 entries not resolved")
+	assert.Equal(t, []string{"searched", "tagged"}, published["playlist"])
+	assert.EqualError(t, testExecute(cmdPublish(), library, "--name", "Library"),
+		"publication partially complete: 1 entries not resolved")
+	assert.Equal(t, []string{"searched", "tagged"}, published["Library"])
+	assert.NotNil(t, testExecute(cmdPublish(), library, "--name", "Dry", "--dry-run"))
+	assert.NotContains(t, published, "Dry")
+	assert.Nil(t, os.Remove(filepath.Join(library, "Artist - Unknown.mp3")))
+	assert.Nil(t, testExecute(cmdPublish(), library, "--name", "Complete"))
+	assert.Equal(t, []string{"searched", "tagged"}, published["Complete"])
+}
+
+func TestCmdPublishUntagged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Title.mp3")
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "SearchTrack", func(_ *spotify.Client, title, artist string) (*entity.Track, error) {
+		assert.Equal(t, "Title", title)
+		assert.Empty(t, artist)
+		return &entity.Track{ID: "searched"}, nil
+	}).Reset()
+
+	// testing
+	spotifyClient = &spotify.Client{}
+	id, err := publishResolve(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "searched", id)
+}
+
+func TestCmdPublishPathFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.txt")
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+
+	// testing
+	assert.Error(t, testExecute(cmdPublish(), filepath.Join(t.TempDir(), "missing.m3u")))
+	assert.EqualError(t, testExecute(cmdPublish(), path), "unsupported encoding")
+}
+
+func TestCmdPublishAuthFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdPublish(), t.TempDir()), "ko")
+}
+
+func TestCmdPublishSearchFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "SearchTrack", func() (*entity.Track, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	spotifyClient = &spotify.Client{}
+	assert.EqualError(t, util.ErrOnly(publishResolve(filepath.Join(testPublishLibrary(t), "Artist - Title.mp3"))), "ko")
+}
+
+func TestCmdPublishFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Publish", func() (*playlist.Playlist, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdPublish(), t.TempDir()), "ko")
+}
+
+func TestCmdPublishWalkFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdPublish(), t.TempDir()), "ko")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+)
+
+func init() {
+	cmdRoot.AddCommand(cmdReconcile())
+}
+
+func cmdReconcile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "reconcile",
+		Short:        "Compare local tracks with the ones saved into the Spotify library",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var (
+				path         = outputPath(cmd, "output")
+				pathTemplate = util.ErrWrap("")(cmd.Flags().GetString("path-template"))
+				like         = util.ErrWrap(false)(cmd.Flags().GetBool("like"))
+				out          = cmd.OutOrStdout()
+			)
+
+			if err := entity.SetPathTemplate(pathTemplate); err != nil {
+				return err
+			}
+
+			if err := indexData.Load(util.ProfileFile(index.Basename)); err != nil {
+				return err
+			}
+			if err := indexData.Build(path); err != nil {
+				return err
+			}
+			if err := indexData.Persist(util.ProfileFile(index.Basename)); err != nil {
+				return err
+			}
+
+			var err error
+			if spotifyClient, err = spotify.Authenticate(spotify.BrowserProcessor); err != nil {
+				return err
+			}
+
+			library, err := reconcileLibrary()
+			if err != nil {
+				return err
+			}
+
+			localOnly, spotifyOnly := reconcile(indexData.Tagged(), library)
+			fmt.Fprintf(out, "Local only (%d):\n", len(localOnly))
+			for _, entry := range localOnly {
+				fmt.Fprintf(out, "  %s (%s)\n", entry.Path, entry.ID)
+			}
+			fmt.Fprintf(out, "Spotify only (%d):\n", len(spotifyOnly))
+			for _, track := range spotifyOnly {
+				fmt.Fprintf(out, "  %s by %s (%s)\n", track.Title, track.Artists[0], track.ID)
+			}
+			if !like || len(localOnly) == 0 {
+				return nil
+			}
+
+			ids := []string{}
+			for _, entry := range localOnly {
+				ids = append(ids, entry.ID)
+			}
+			if err := spotifyClient.Like(ids...); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%d tracks liked\n", len(ids))
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", xdg.UserDirs.Music, "Library path")
+	cmd.Flags().String("path-template", "", "Template of tracks path, relative to library path and with no extension (same as sync)")
+	cmd.Flags().Bool("like", false, "Save local only tracks into the library, i.e. among the liked songs")
+	return cmd
+}
+
+// reconcileLibrary returns the tracks saved
+// into the library, the latest saved first
+func reconcileLibrary() ([]*entity.Track, error) {
+	var (
+		tracks = []*entity.Track{}
+		saved  = make(chan interface{}, 10000)
+		waiter sync.WaitGroup
+	)
+	waiter.Add(1)
+	go func() {
+		defer waiter.Done()
+		for event := range saved {
+			tracks = append(tracks, event.(*entity.Track))
+		}
+	}()
+
+	err := spotifyClient.Library(0, time.Time{}, saved)
+	close(saved)
+	waiter.Wait()
+	return tracks, err
+}
+
+// reconcile returns the local tracks which are not saved into the
+// library and the tracks saved into the library which are not local:
+// several local tracks tagged with the same ID are all reported
+func reconcile(local []index.Entry, library []*entity.Track) (localOnly []index.Entry, spotifyOnly []*entity.Track) {
+	var (
+		localIDs   = make(map[string]bool)
+		libraryIDs = make(map[string]bool)
+	)
+	for _, entry := range local {
+		localIDs[entry.ID] = true
+	}
+	for _, track := range library {
+		libraryIDs[track.ID] = true
+	}
+
+	for _, entry := range local {
+		if !libraryIDs[entry.ID] {
+			localOnly = append(localOnly, entry)
+		}
+	}
+	for _, track := range library {
+		if !localIDs[track.ID] {
+			spotifyOnly = append(spotifyOnly, track)
+		}
+	}
+	return
+}
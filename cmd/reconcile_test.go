@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/bogem/id3v2/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/stretchr/testify/assert"
+)
+
+var reconcileLiked = []*entity.Track{
+	{ID: "liked", Title: "Liked", Artists: []string{"Artist"}},
+	{ID: "remote", Title: "Remote", Artists: []string{"Artist"}},
+}
+
+// testReconcileLibrary writes a track which is liked, one
+// which is not and an untagged one, which is not considered
+func testReconcileLibrary(t *testing.T) string {
+	t.Cleanup(cleanup)
+	library := t.TempDir()
+	for name, id := range map[string]string{
+		"Artist - Liked.mp3":    "liked",
+		"Artist - Local.mp3":    "local",
+		"Artist - Untagged.mp3": "",
+	} {
+		path := filepath.Join(library, name)
+		assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+		tag, err := id3.Open(path, id3v2.Options{Parse: true})
+		assert.Nil(t, err)
+		tag.SetSpotifyID(id)
+		assert.Nil(t, tag.Save(context.Background()))
+		assert.Nil(t, tag.Close())
+	}
+	return library
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestCmdReconcile(&testing.T{})
+	}
+}
+
+func TestCmdReconcile(t *testing.T) {
+	var (
+		library = testReconcileLibrary(t)
+		liked   []string
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, channels ...chan interface{}) error {
+			for _, track := range reconcileLiked {
+				for _, ch := range channels {
+					ch <- track
+				}
+			}
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Like", func(_ *spotify.Client, ids ...string) error {
+			liked = append(liked, ids...)
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReconcile(), "-o", library))
+	assert.Empty(t, liked)
+	assert.Nil(t, testExecute(cmdReconcile(), "-o", library, "--like"))
+	assert.Equal(t, []string{"local"}, liked)
+}
+
+func TestCmdReconcileBuildFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile()), "ko")
+}
+
+func TestCmdReconcileTemplateFailure(t *testing.T) {
+	assert.Error(t, testExecute(cmdReconcile(), "--path-template", "{{.Unknown}}"))
+}
+
+func TestCmdReconcileLoadFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&index.Index{}, "Load", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile()), "ko")
+}
+
+func TestCmdReconcilePersistFailure(t *testing.T) {
+	library := testReconcileLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile(), "-o", library), "ko")
+}
+
+func TestCmdReconcileAuthFailure(t *testing.T) {
+	library := testReconcileLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile(), "-o", library), "ko")
+}
+
+func TestCmdReconcileLibraryFailure(t *testing.T) {
+	library := testReconcileLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile(), "-o", library), "ko")
+}
+
+func TestCmdReconcileLikeFailure(t *testing.T) {
+	library := testReconcileLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func() error {
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Like", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReconcile(), "-o", library, "--like"), "ko")
+}
+
+func TestReconcile(t *testing.T) {
+	// testing
+	localOnly, spotifyOnly := reconcile([]index.Entry{
+		{ID: "liked", Path: "Artist - Liked.mp3"},
+		{ID: "local", Path: "Artist - Local.mp3"},
+		{ID: "local", Path: "Artist - Local (Copy).mp3"},
+	}, reconcileLiked)
+	assert.Equal(t, []string{"Artist - Local.mp3", "Artist - Local (Copy).mp3"}, []string{localOnly[0].Path, localOnly[1].Path})
+	assert.Equal(t, []*entity.Track{reconcileLiked[1]}, spotifyOnly)
+}
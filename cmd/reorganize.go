@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+)
+
+// move is the relocation of a track file,
+// with the reason why it cannot take place, if any
+type move struct {
+	source      string
+	destination string
+	collision   string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdReorganize())
+}
+
+func cmdReorganize() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "reorganize",
+		Short:        "Move local tracks to the path the current naming rules give them",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var (
+				path         = outputPath(cmd, "output")
+				pathTemplate = util.ErrWrap("")(cmd.Flags().GetString("path-template"))
+				dryRun       = util.ErrWrap(false)(cmd.Flags().GetBool("dry-run"))
+				out          = cmd.OutOrStdout()
+			)
+
+			if err := entity.SetPathTemplate(pathTemplate); err != nil {
+				return err
+			}
+
+			if err := indexData.Load(util.ProfileFile(index.Basename)); err != nil {
+				return err
+			}
+			// tracks may be laid out other than the current template tells
+			if err := indexData.BuildTree(path); err != nil {
+				return err
+			}
+
+			// dry runs do not look tracks up on Spotify:
+			// those needing it are only listed as unresolved
+			entries, unresolved := reorganizeUnresolved(indexData.Tagged(), dryRun)
+			if err := reorganizeResolve(entries); err != nil {
+				return err
+			}
+
+			if err := os.Chdir(path); err != nil {
+				return err
+			}
+
+			moves, err := reorganizePlan(path, entries)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, move := range moves {
+				if len(move.collision) > 0 {
+					failed++
+					fmt.Fprintf(out, "%s ⟶ %s (skipped: %s)\n", move.source, move.destination, move.collision)
+				} else {
+					fmt.Fprintf(out, "%s ⟶ %s\n", move.source, move.destination)
+				}
+			}
+			for _, entry := range unresolved {
+				fmt.Fprintf(out, "%s ⟶ ? (unresolved: artists to be looked up on Spotify)\n",
+					util.ErrWrap(entry.Path)(filepath.Rel(path, entry.Path)))
+			}
+			if dryRun || len(moves) == 0 {
+				return nil
+			}
+
+			// files are first moved aside, so that a track
+			// can take the place of another one being moved
+			var pending []*move
+			for _, move := range moves {
+				if len(move.collision) > 0 {
+					continue
+				}
+				if err := os.Rename(move.source, move.source+".reorganize"); err != nil {
+					reorganizeRestore(pending)
+					return err
+				}
+				pending = append(pending, move)
+			}
+
+			var (
+				moved   = make(map[string]string)
+				moveErr error
+			)
+			for i, move := range pending {
+				if err := util.FileMoveOrCopy(move.source+".reorganize", move.destination); err != nil {
+					// whatever was not moved yet goes back in place,
+					// whatever was still gets playlists and index updated
+					reorganizeRestore(pending[i:])
+					moveErr = err
+					break
+				}
+				moved[move.source] = move.destination
+				indexData.Move(filepath.Join(path, move.source), filepath.Join(path, move.destination))
+				reorganizePrune(filepath.Dir(move.source))
+			}
+
+			if err := filepath.WalkDir(".", func(path string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return err
+				}
+				if ext := strings.ToLower(filepath.Ext(path)); ext != ".m3u" && ext != ".pls" {
+					return nil
+				}
+				counter, err := playlist.Relocate(path, moved)
+				if err != nil {
+					return err
+				}
+				if counter > 0 {
+					fmt.Fprintf(out, "%s: %d entries updated\n", path, counter)
+				}
+				return nil
+			}); err != nil {
+				return errors.Join(moveErr, err)
+			}
+
+			// moved tracks need not be parsed again
+			if err := indexData.Build(path); err != nil {
+				return errors.Join(moveErr, err)
+			}
+			if err := indexData.Persist(util.ProfileFile(index.Basename)); err != nil {
+				return errors.Join(moveErr, err)
+			}
+
+			if moveErr != nil {
+				return moveErr
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d tracks could not be moved", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", xdg.UserDirs.Music, "Library path")
+	cmd.Flags().String("path-template", "", "Template of tracks path, relative to library path and with no extension (same as sync)")
+	cmd.Flags().BoolP("dry-run", "n", false, "Only show which tracks would be moved where")
+	return cmd
+}
+
+// reorganizePlan computes, for every given entry of a track file found
+// at the given root, the path it should be moved to, according to the
+// track it has been synchronized from: moves clashing with other files
+// or moves are marked as colliding
+func reorganizePlan(root string, entries []index.Entry) ([]*move, error) {
+	var (
+		moves        []*move
+		destinations = make(map[string]*move)
+	)
+	// the first file found walking the library keeps
+	// its claim on a destination other ones collide on
+	entries = slices.Clone(entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return slices.Compare(
+			strings.Split(entries[i].Path, string(filepath.Separator)),
+			strings.Split(entries[j].Path, string(filepath.Separator))) < 0
+	})
+	for _, entry := range entries {
+		source, err := filepath.Rel(root, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		// files keep their own format
+		destination := util.FileBaseStem(entry.Track.Path().Final()) + filepath.Ext(source)
+		if destination == source {
+			continue
+		}
+
+		move := &move{source, destination, ""}
+		if other, ok := destinations[strings.ToLower(destination)]; ok {
+			move.collision = "same path as " + other.source
+		} else if info, err := os.Stat(destination); err == nil && !reorganizeSameFile(source, info) {
+			move.collision = "path already taken"
+		}
+		destinations[strings.ToLower(destination)] = move
+		moves = append(moves, move)
+	}
+
+	// an existing file is no collision if it is moved away too
+	for _, move := range moves {
+		if move.collision != "path already taken" {
+			continue
+		}
+		for _, other := range moves {
+			if other.source == move.destination && len(other.collision) == 0 {
+				move.collision = ""
+				break
+			}
+		}
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		return moves[i].source < moves[j].source
+	})
+	return moves, nil
+}
+
+// reorganizeUnresolved splits off those entries whose files have been tagged
+// before every artist got written, unless they are to be looked up
+func reorganizeUnresolved(entries []index.Entry, split bool) (resolved, unresolved []index.Entry) {
+	if !split {
+		return entries, nil
+	}
+	for _, entry := range entries {
+		if len(entry.Track.Artists) > 0 {
+			resolved = append(resolved, entry)
+		} else {
+			unresolved = append(unresolved, entry)
+		}
+	}
+	return
+}
+
+// reorganizeResolve completes the tracks of those entries whose files have
+// been tagged before every artist got written, looking them up on Spotify
+func reorganizeResolve(entries []index.Entry) error {
+	var client *spotify.Client
+	for i, entry := range entries {
+		if len(entry.Track.Artists) > 0 {
+			continue
+		}
+
+		if client == nil {
+			var err error
+			if client, err = spotify.Authenticate(spotify.BrowserProcessor); err != nil {
+				return err
+			}
+		}
+		upstream, err := client.Track(entry.ID)
+		if err != nil {
+			return err
+		}
+
+		// the index holds the track as read from tags
+		track := *entry.Track
+		track.Artists = upstream.Artists
+		track.Disc = upstream.Disc
+		entries[i].Track = &track
+	}
+	return nil
+}
+
+// reorganizeSameFile tells whether the given path and info refer to the same
+// file, as on case-insensitive filesystems a file can be renamed to itself
+func reorganizeSameFile(path string, info fs.FileInfo) bool {
+	source, err := os.Stat(path)
+	return err == nil && os.SameFile(source, info)
+}
+
+// reorganizeRestore moves back the given
+// files which have been moved aside
+func reorganizeRestore(moves []*move) {
+	for _, move := range moves {
+		util.ErrSuppress(os.Rename(move.source+".reorganize", move.source))
+	}
+}
+
+// reorganizePrune removes the given directory and its parents,
+// as long as they are left empty by moving tracks away
+func reorganizePrune(dir string) {
+	for dir != "." && dir != string(filepath.Separator) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
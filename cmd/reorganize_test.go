@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/entity/tag"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+const reorganizeTemplate = `{{index .Artists 0}}/{{.Album}}/{{printf "%02d" .Number}} {{.Title}}`
+
+var reorganizeTags = map[string]map[string]string{
+	"Artist - Title.opus":                {"spotify_id": "1", "title": "Title", "artist": "Artist", "artists": "Artist", "album": "Album", "track": "1"},
+	"Artist - Twin.opus":                 {"spotify_id": "2", "title": "Title", "artist": "Artist", "artists": "Artist", "album": "Album", "track": "1"},
+	"Artist - Swap.opus":                 {"spotify_id": "3", "title": "Swap", "artist": "Artist", "artists": "Artist", "album": "Album", "track": "3"},
+	"03 Swap.opus":                       {"spotify_id": "4", "title": "Swapped", "artist": "Artist", "artists": "Artist", "album": "Album", "track": "4"},
+	"04 Swapped.opus":                    {"spotify_id": "4", "title": "Swapped", "artist": "Artist", "artists": "Artist", "album": "Album", "track": "4"},
+	"Artist - Featuring (ft Guest).opus": {"spotify_id": "5", "title": "Featuring", "artist": "Artist", "artists": "Artist; Guest", "album": "Album", "track": "5"},
+	"Artist - Untracked (ft Guest).opus": {"spotify_id": "6", "title": "Untracked", "artist": "Artist", "album": "Album", "track": "6"},
+	"Untagged.opus":                      {},
+}
+
+func testReorganizeLibrary(t *testing.T) string {
+	var (
+		root = t.TempDir()
+		wd   = util.ErrWrap("")(os.Getwd())
+	)
+	t.Cleanup(func() {
+		util.ErrSuppress(os.Chdir(wd))
+		util.ErrSuppress(entity.SetPathTemplate(""))
+		cleanup()
+	})
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "Artist", "Album"), 0o755))
+	for _, path := range []string{
+		"Artist - Title.opus",
+		"Artist - Twin.opus",
+		"Untagged.opus",
+		filepath.Join("Artist", "Album", "Artist - Swap.opus"),
+		filepath.Join("Artist", "Album", "03 Swap.opus"),
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(root, path), []byte(path), 0o644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "playlist.m3u"), []byte("#EXTM3U\nArtist - Title.opus\nArtist - Twin.opus\n"), 0o644))
+	return root
+}
+
+// testReorganizeProbe reads the tags of a track file out of its
+// content, i.e. the path it was created at, wherever it has been moved
+func testReorganizeProbe(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &cmd.Probe{Tags: maps.Clone(reorganizeTags[filepath.Base(string(content))])}, nil
+}
+
+func BenchmarkReorganize(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestCmdReorganize(&testing.T{})
+	}
+}
+
+func TestCmdReorganize(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "1 tracks could not be moved")
+	for path, content := range map[string]string{
+		filepath.Join("Artist", "Album", "01 Title.opus"):   "Artist - Title.opus",
+		"Artist - Twin.opus":                                "Artist - Twin.opus",
+		"Untagged.opus":                                     "Untagged.opus",
+		filepath.Join("Artist", "Album", "03 Swap.opus"):    filepath.Join("Artist", "Album", "Artist - Swap.opus"),
+		filepath.Join("Artist", "Album", "04 Swapped.opus"): filepath.Join("Artist", "Album", "03 Swap.opus"),
+	} {
+		assert.Equal(t, content, string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(root, path)))))
+	}
+	assert.NoFileExists(t, filepath.Join(root, "Artist - Title.opus"))
+	assert.NoFileExists(t, filepath.Join(root, "Artist", "Album", "Artist - Swap.opus"))
+	assert.Equal(t, "#EXTM3U\nArtist/Album/01 Title.opus\nArtist - Twin.opus\n",
+		string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(root, "playlist.m3u")))))
+	entry, ok := indexData.Entry(&entity.Track{ID: "1"})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "Artist", "Album", "01 Title.opus"), entry.Path)
+
+	// back to flat, leaving no empty directory behind
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "1 tracks could not be moved")
+	assert.FileExists(t, filepath.Join(root, "Artist - Title.opus"))
+	assert.FileExists(t, filepath.Join(root, "Artist - Swapped.opus"))
+	assert.NoDirExists(t, filepath.Join(root, "Artist"))
+}
+
+func TestCmdReorganizeDryRun(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			panic("nothing to be moved")
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReorganize(), "-o", root, "-n", "--path-template", reorganizeTemplate))
+	assert.FileExists(t, filepath.Join(root, "Artist - Title.opus"))
+}
+
+func TestCmdReorganizeNothing(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+			return &cmd.Probe{}, nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			panic("nothing to be moved")
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReorganize(), "-o", root))
+}
+
+func TestCmdReorganizeFeaturing(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist - Featuring (ft Guest).opus"), []byte("Artist - Featuring (ft Guest).opus"), 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "1 tracks could not be moved")
+	assert.FileExists(t, filepath.Join(root, "Artist - Featuring (ft Guest).opus"))
+	assert.NoFileExists(t, filepath.Join(root, "Artist - Featuring.opus"))
+}
+
+func TestCmdReorganizeResolve(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist - Untracked (ft Guest).opus"), []byte("Artist - Untracked (ft Guest).opus"), 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func(_ *spotify.Client, id string, _ ...chan interface{}) (*entity.Track, error) {
+			return &entity.Track{ID: id, Artists: []string{"Artist", "Guest"}, Disc: 2}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReorganize(), "-o", root, "-n", "--path-template", "{{.Disc}}/{{.Title}}"))
+	entries := indexData.Tagged()
+	assert.Nil(t, reorganizeResolve(entries))
+	moves, err := reorganizePlan(root, entries)
+	assert.Nil(t, err)
+	assert.Contains(t, moves, &move{"Artist - Untracked (ft Guest).opus", filepath.Join("2", "Untracked.opus"), ""})
+	entry, ok := indexData.Entry(&entity.Track{ID: "6"})
+	assert.True(t, ok)
+	assert.Empty(t, entry.Track.Artists)
+}
+
+func TestCmdReorganizeResolveFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist - Untracked (ft Guest).opus"), []byte("Artist - Untracked (ft Guest).opus"), 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "ko")
+	assert.FileExists(t, filepath.Join(root, "Artist - Untracked (ft Guest).opus"))
+}
+
+func TestCmdReorganizeResolveDryRun(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist - Untracked (ft Guest).opus"), []byte("Artist - Untracked (ft Guest).opus"), 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			panic("nothing to be looked up")
+		}).
+		Reset()
+
+	// testing
+	var (
+		out     bytes.Buffer
+		command = cmdReorganize()
+	)
+	command.SetOut(&out)
+	command.SetArgs([]string{"-o", root, "-n"})
+	assert.Nil(t, command.Execute())
+	assert.Contains(t, out.String(), "Artist - Untracked (ft Guest).opus ⟶ ? (unresolved")
+	assert.Contains(t, out.String(), "Artist - Twin.opus ⟶ Artist - Title.opus (skipped")
+	assert.FileExists(t, filepath.Join(root, "Artist - Untracked (ft Guest).opus"))
+}
+
+func TestCmdReorganizeAuthFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist - Untracked (ft Guest).opus"), []byte("Artist - Untracked (ft Guest).opus"), 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "ko")
+}
+
+func TestCmdReorganizeTemplateFailure(t *testing.T) {
+	assert.Error(t, testExecute(cmdReorganize(), "--path-template", "{{.Unknown}}"))
+}
+
+func TestCmdReorganizePathFailure(t *testing.T) {
+	assert.Error(t, testExecute(cmdReorganize(), "-o", filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestCmdReorganizeOpenFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(tag.Open, func() (tag.Tag, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "ko")
+}
+
+func TestCmdReorganizeMoveFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+	assert.FileExists(t, filepath.Join(root, "Artist - Title.opus"))
+	assert.NoFileExists(t, filepath.Join(root, "Artist - Title.opus.reorganize"))
+}
+
+func TestCmdReorganizeMovePartialFailure(t *testing.T) {
+	var (
+		root      = testReorganizeLibrary(t)
+		persisted bool
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			persisted = true
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func(source, destination string, _ ...bool) error {
+			// only the first track gets moved
+			if !strings.HasPrefix(source, "Artist - Title.opus") {
+				return errors.New("ko")
+			}
+			if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+				return err
+			}
+			return os.Rename(source, destination)
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+	assert.FileExists(t, filepath.Join(root, "Artist", "Album", "01 Title.opus"))
+	assert.FileExists(t, filepath.Join(root, "Artist", "Album", "03 Swap.opus"))
+	assert.NoFileExists(t, filepath.Join(root, "Artist", "Album", "03 Swap.opus.reorganize"))
+	assert.Equal(t, "#EXTM3U\nArtist/Album/01 Title.opus\nArtist - Twin.opus\n",
+		string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(root, "playlist.m3u")))))
+	entry, ok := indexData.Entry(&entity.Track{ID: "1"})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "Artist", "Album", "01 Title.opus"), entry.Path)
+	assert.True(t, persisted)
+}
+
+func TestCmdReorganizeRenameFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(os.Rename, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+	assert.FileExists(t, filepath.Join(root, "Artist - Title.opus"))
+}
+
+func TestCmdReorganizeComplete(t *testing.T) {
+	root := testReorganizeLibrary(t)
+	assert.Nil(t, os.Remove(filepath.Join(root, "Artist - Twin.opus")))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate))
+	assert.FileExists(t, filepath.Join(root, "Artist", "Album", "01 Title.opus"))
+}
+
+func TestCmdReorganizeLoadFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&index.Index{}, "Load", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize()), "ko")
+}
+
+func TestCmdReorganizeChdirFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(os.Chdir, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "ko")
+}
+
+func TestCmdReorganizePlanFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(filepath.Rel, func() (string, error) {
+			return "", errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root), "ko")
+}
+
+func TestCmdReorganizeRelocateFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyFunc(playlist.Relocate, func() (int, error) {
+			return 0, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+	assert.FileExists(t, filepath.Join(root, "Artist", "Album", "01 Title.opus"))
+}
+
+func TestCmdReorganizeBuildFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+}
+
+func TestCmdReorganizePersistFailure(t *testing.T) {
+	root := testReorganizeLibrary(t)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", testReorganizeProbe).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdReorganize(), "-o", root, "--path-template", reorganizeTemplate), "ko")
+}
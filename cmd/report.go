@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/playlist"
+)
+
+const (
+	reportInstalled = "installed" // downloaded and installed during the synchronization
+	reportSkipped   = "skipped"   // already on disk, or not to be synchronized
+	reportMissing   = "missing"   // already synchronized, somewhere else on disk
+	reportNotFound  = "not-found" // no match found upstream
+	reportFailed    = "failed"    // none of the matches could be synchronized
+
+	reportComplete = "complete"
+	reportPartial  = "partial"
+)
+
+// report is the outcome of a synchronization, track by track
+type report struct {
+	Started   time.Time         `json:"started"`
+	Finished  time.Time         `json:"finished"`
+	Outcome   string            `json:"outcome"` // complete, partial or failed
+	Error     string            `json:"error,omitempty"`
+	Tracks    []*reportTrack    `json:"tracks"`
+	Playlists []*reportPlaylist `json:"playlists"`
+	tracks    map[string]*reportTrack
+	lock      sync.Mutex
+}
+
+type reportTrack struct {
+	ID          string           `json:"id"`
+	Title       string           `json:"title"`
+	Artist      string           `json:"artist"`
+	Collections []string         `json:"collections"` // e.g. library, saved-albums, new-releases, playlist:ID, album:ID, artist:ID, track:ID or journal
+	Outcome     string           `json:"outcome"`
+	Path        string           `json:"path,omitempty"` // relative to the output path
+	URL         string           `json:"url,omitempty"`
+	Score       int              `json:"score,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Timings     map[string]int64 `json:"timings"` // milliseconds spent by stage
+}
+
+type reportPlaylist struct {
+	ID     string         `json:"id"`
+	Name   string         `json:"name"`
+	Path   string         `json:"path"` // relative to the output path
+	Tracks int            `json:"tracks"`
+	Totals map[string]int `json:"totals"` // number of tracks by outcome
+	ids    []string
+}
+
+func newReport() *report {
+	return &report{
+		Started:   time.Now(),
+		Tracks:    []*reportTrack{},
+		Playlists: []*reportPlaylist{},
+		tracks:    make(map[string]*reportTrack),
+	}
+}
+
+// fetch takes note of the given track being part of the given collection:
+// as for the plan, all the report methods are no-op on a nil report,
+// i.e. whenever the synchronization is a dry run
+func (report *report) fetch(track *entity.Track, collection string) {
+	if report == nil {
+		return
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	entry := report.entry(track)
+	entry.Collections = append(entry.Collections, collection)
+}
+
+// entry returns the entry of the given track, adding it if
+// not there yet: it expects the report to be locked already
+func (report *report) entry(track *entity.Track) *reportTrack {
+	entry, ok := report.tracks[track.ID]
+	if !ok {
+		entry = &reportTrack{
+			ID:          track.ID,
+			Title:       track.Title,
+			Artist:      track.Artists[0],
+			Collections: []string{},
+			Timings:     make(map[string]int64),
+		}
+		report.tracks[track.ID] = entry
+		report.Tracks = append(report.Tracks, entry)
+	}
+	return entry
+}
+
+// set takes note of the outcome of the synchronization of the given track
+func (report *report) set(track *entity.Track, outcome string, errs ...error) {
+	if report == nil {
+		return
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	entry := report.entry(track)
+	entry.Outcome = outcome
+	entry.Path = track.Path().Final()
+	entry.URL = track.UpstreamURL
+	for _, match := range track.Matches {
+		if match.URL == track.UpstreamURL {
+			entry.Score = match.Score
+		}
+	}
+	if len(errs) > 0 && errs[0] != nil {
+		entry.Error = errs[0].Error()
+	}
+}
+
+// elapsed takes note of the time spent on the given track
+// by the given stage, which started at the given time
+func (report *report) elapsed(track *entity.Track, stage string, start time.Time) {
+	if report == nil {
+		return
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	report.entry(track).Timings[stage] += time.Since(start).Milliseconds()
+}
+
+func (report *report) mix(playlist *playlist.Playlist, path string) {
+	if report == nil {
+		return
+	}
+
+	entry := &reportPlaylist{playlist.ID, playlist.Name, path, len(playlist.Tracks), make(map[string]int), nil}
+	for _, track := range playlist.Tracks {
+		entry.ids = append(entry.ids, track.ID)
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	report.Playlists = append(report.Playlists, entry)
+}
+
+// finish wraps up the report of the synchronization which ended with
+// the given error (if any), and returns the error to end the command with
+func (report *report) finish(err error) error {
+	if report == nil {
+		return err
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	report.Finished = time.Now()
+
+	failures := 0
+	for _, entry := range report.Tracks {
+		// tracks still in flight when the synchronization ended
+		if len(entry.Outcome) == 0 && err != nil {
+			entry.Outcome = reportFailed
+			entry.Error = err.Error()
+		} else if len(entry.Outcome) == 0 {
+			entry.Outcome = reportSkipped
+		}
+		if entry.Outcome == reportFailed || entry.Outcome == reportNotFound {
+			failures++
+		}
+	}
+
+	for _, playlist := range report.Playlists {
+		for _, id := range playlist.ids {
+			if entry, ok := report.tracks[id]; ok {
+				playlist.Totals[entry.Outcome]++
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		report.Outcome = reportFailed
+		report.Error = err.Error()
+		return err
+	case failures > 0:
+		report.Outcome = reportPartial
+		return &exitError{fmt.Errorf("synchronization partially complete: %d tracks not synchronized", failures), exitPartial}
+	default:
+		report.Outcome = reportComplete
+		return nil
+	}
+}
+
+// write dumps the report as JSON to the file at
+// the given path, or to the standard output if "-"
+func (report *report) write(path string) error {
+	if report == nil {
+		return nil
+	}
+
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkReport(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestReport(&testing.T{})
+	}
+}
+
+func TestReport(t *testing.T) {
+	var (
+		report = newReport()
+		track  = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}}
+		other  = &entity.Track{ID: "other", Title: "Other", Artists: []string{"Artist"}}
+	)
+	report.fetch(track, "library")
+	report.fetch(track, "playlist:123")
+	report.fetch(other, "playlist:123")
+	report.set(track, reportSkipped)
+	report.mix(&playlist.Playlist{ID: "123", Tracks: []*entity.Track{track, other}}, "playlist.m3u")
+	assert.Nil(t, report.finish(nil))
+	assert.Equal(t, reportComplete, report.Outcome)
+	assert.Equal(t, []string{"library", "playlist:123"}, report.Tracks[0].Collections)
+	assert.Equal(t, reportSkipped, report.Tracks[1].Outcome)
+	assert.Equal(t, map[string]int{reportSkipped: 2}, report.Playlists[0].Totals)
+}
+
+func TestReportInFlight(t *testing.T) {
+	var (
+		report = newReport()
+		track  = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}}
+	)
+	report.fetch(track, "library")
+	assert.EqualError(t, report.finish(errors.New("ko")), "ko")
+	assert.Equal(t, reportFailed, report.Outcome)
+	assert.Equal(t, reportFailed, report.Tracks[0].Outcome)
+	assert.Equal(t, "ko", report.Tracks[0].Error)
+}
+
+func TestReportWrite(t *testing.T) {
+	assert.Nil(t, newReport().write("-"))
+	assert.Error(t, newReport().write(t.TempDir()))
+	assert.Error(t, (&report{Started: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}).write("-"))
+}
+
+func TestReportNil(t *testing.T) {
+	var (
+		report *report
+		track  = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}}
+	)
+	report.fetch(track, "library")
+	report.set(track, reportInstalled)
+	report.elapsed(track, "decide", time.Now())
+	report.mix(&playlist.Playlist{}, "playlist.m3u")
+	assert.EqualError(t, report.finish(errors.New("ko")), "ko")
+	assert.Nil(t, report.write("-"))
+}
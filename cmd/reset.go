@@ -22,8 +22,9 @@ func cmdReset() *cobra.Command {
 		Args:         cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			var (
-				session        = util.ErrWrap(false)(cmd.Flags().GetBool("session"))
-				cacheDirectory = util.CacheDirectory()
+				session           = util.ErrWrap(false)(cmd.Flags().GetBool("session"))
+				cacheDirectory    = util.CacheDirectory()
+				profilesDirectory = util.CacheFile(util.ProfilesDirname)
 			)
 			return filepath.WalkDir(cacheDirectory, func(path string, entry fs.DirEntry, err error) error {
 				if err != nil {
@@ -34,7 +35,15 @@ func cmdReset() *cobra.Command {
 					return nil
 				}
 
-				return os.RemoveAll(path)
+				// profiles directories are walked through, as they hold sessions too
+				if entry.IsDir() && !session && (path == profilesDirectory || filepath.Dir(path) == profilesDirectory) {
+					return nil
+				}
+
+				if err := os.RemoveAll(path); err != nil || !entry.IsDir() {
+					return err
+				}
+				return fs.SkipDir
 			})
 		},
 	}
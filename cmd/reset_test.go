@@ -57,3 +57,32 @@ func TestCmdReset(t *testing.T) {
 	// testing
 	assert.Nil(t, util.ErrOnly(testExecute(cmdReset())))
 }
+
+func TestCmdResetProfiles(t *testing.T) {
+	cache := t.TempDir()
+	for _, path := range []string{
+		spotify.TokenBasename,
+		"index.gob",
+		filepath.Join("playlists", "123.json"),
+		filepath.Join(util.ProfilesDirname, "alice", spotify.TokenBasename),
+		filepath.Join(util.ProfilesDirname, "alice", "index.gob"),
+	} {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(cache, path)), 0o755))
+		assert.Nil(t, os.WriteFile(filepath.Join(cache, path), []byte{}, 0o644))
+	}
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdReset()))
+	assert.FileExists(t, filepath.Join(cache, spotify.TokenBasename))
+	assert.FileExists(t, filepath.Join(cache, util.ProfilesDirname, "alice", spotify.TokenBasename))
+	assert.NoFileExists(t, filepath.Join(cache, "index.gob"))
+	assert.NoFileExists(t, filepath.Join(cache, util.ProfilesDirname, "alice", "index.gob"))
+	assert.NoDirExists(t, filepath.Join(cache, "playlists"))
+	assert.Nil(t, testExecute(cmdReset(), "--session"))
+	assert.NoDirExists(t, filepath.Join(cache, util.ProfilesDirname))
+}
@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/journal"
 	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+)
+
+const (
+	exitFailure = 1
+	exitPartial = 2 // only some of the tracks could be synchronized
 )
 
 var (
@@ -14,13 +22,53 @@ var (
 	cmdRoot       = &cobra.Command{
 		Use:   "spotitube",
 		Short: "Synchronize Spotify collections downloading from external providers",
+		// errors get printed once, by Execute
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			profile := util.Fallback(util.ErrWrap("")(cmd.Flags().GetString("profile")), os.Getenv("SPOTITUBE_PROFILE"))
+			if err := util.SetProfile(profile); err != nil {
+				return err
+			}
+			journalData = journal.New(util.ProfileFile(journal.Basename))
+			return nil
+		},
 	}
-	indexData = index.New()
+	indexData   = index.New()
+	journalData = journal.New(util.ProfileFile(journal.Basename))
 )
 
+// exitError is an error which asks for a
+// specific exit code, rather than the generic one
+type exitError struct {
+	error
+	code int
+}
+
+func (err *exitError) Unwrap() error {
+	return err.error
+}
+
+func init() {
+	cmdRoot.PersistentFlags().String("profile", "", "Spotify account profile, each with its own session, cache and output path (defaults to $SPOTITUBE_PROFILE)")
+}
+
+// outputPath returns the path given through the flag of the given name,
+// defaulting to the output path of the profile in use
+func outputPath(cmd *cobra.Command, name string) string {
+	if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return util.MusicDirectory()
+}
+
 func Execute() {
 	if err := cmdRoot.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		// the standard output may carry the report
+		fmt.Fprintln(cmdRoot.ErrOrStderr(), err)
+		code := exitFailure
+		if exitErr := new(exitError); errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		os.Exit(code)
 	}
 }
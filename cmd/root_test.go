@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/adrg/xdg"
+	"github.com/agiledragon/gomonkey/v2"
 	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
 )
 
 func BenchmarkRoot(b *testing.B) {
@@ -32,3 +38,59 @@ func TestExecute(_ *testing.T) {
 	cmdRoot.SetOutput(io.Discard)
 	Execute()
 }
+
+func TestExecuteFailure(t *testing.T) {
+	var (
+		codes   []int
+		partial = &cobra.Command{Use: "partial", RunE: func(*cobra.Command, []string) error {
+			return &exitError{errors.New("ko"), exitPartial}
+		}}
+	)
+	cmdRoot.AddCommand(partial)
+	t.Cleanup(func() {
+		cmdRoot.RemoveCommand(partial)
+		cmdRoot.SetArgs(nil)
+	})
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.Exit, func(code int) {
+		codes = append(codes, code)
+	}).Reset()
+
+	// testing
+	cmdRoot.SetOut(io.Discard)
+	cmdRoot.SetErr(io.Discard)
+	cmdRoot.SetArgs([]string{"unknown"})
+	Execute()
+	cmdRoot.SetArgs([]string{"partial"})
+	Execute()
+	assert.Equal(t, []int{exitFailure, exitPartial}, codes)
+}
+
+func TestProfile(t *testing.T) {
+	t.Cleanup(func() { util.ErrSuppress(util.SetProfile("")) })
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("profile", "", "")
+	cmd.Flags().String("output", "", "")
+
+	// testing
+	assert.Equal(t, xdg.UserDirs.Music, outputPath(cmd, "output"))
+	assert.Nil(t, cmd.Flags().Set("profile", "alice"))
+	assert.Nil(t, cmdRoot.PersistentPreRunE(cmd, nil))
+	assert.Equal(t, "alice", util.Profile())
+	assert.Equal(t, filepath.Join(xdg.UserDirs.Music, "alice"), outputPath(cmd, "output"))
+	assert.Nil(t, cmd.Flags().Set("output", "/music"))
+	assert.Equal(t, "/music", outputPath(cmd, "output"))
+	assert.Nil(t, cmd.Flags().Set("profile", "../bob"))
+	assert.EqualError(t, cmdRoot.PersistentPreRunE(cmd, nil), "invalid profile name: ../bob")
+
+	// the environment tells the profile, unless given
+	t.Setenv("SPOTITUBE_PROFILE", "carol")
+	assert.Nil(t, cmd.Flags().Set("profile", ""))
+	assert.Nil(t, cmdRoot.PersistentPreRunE(cmd, nil))
+	assert.Equal(t, "carol", util.Profile())
+	assert.Nil(t, cmd.Flags().Set("profile", "alice"))
+	assert.Nil(t, cmdRoot.PersistentPreRunE(cmd, nil))
+	assert.Equal(t, "alice", util.Profile())
+}
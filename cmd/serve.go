@@ -0,0 +1,576 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/anchor"
+)
+
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobPartial   = "partial" // only some of the tracks could be synchronized
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+
+	serveQueueSize       = 100
+	serveShutdownTimeout = 5 * time.Second
+)
+
+var (
+	// serveCommands are the commands jobs can run, by name
+	serveCommands = map[string]func() *cobra.Command{
+		"sync":   cmdSync,
+		"attach": cmdAttach,
+		"lookup": cmdLookup,
+	}
+	serveIndexStatuses = map[int]string{
+		index.Offline:   "offline",
+		index.Online:    "online",
+		index.Flush:     "flush",
+		index.Installed: "installed",
+	}
+)
+
+// job is a command run on behalf of an API client
+type job struct {
+	ID       string    `json:"id"`
+	Command  string    `json:"command"`
+	Args     []string  `json:"args"`
+	Status   string    `json:"status"` // queued, running, done, partial, failed or cancelled
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Error    string    `json:"error,omitempty"`
+	events   []anchor.Event
+	notify   chan struct{} // closed on any change, if anyone is waiting
+	ctx      context.Context
+	cancel   context.CancelFunc
+	lock     sync.Mutex
+}
+
+type jobRequest struct {
+	Command string   `json:"command"` // sync, if not given
+	Args    []string `json:"args"`    // as given on the command line
+}
+
+type indexTrack struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path,omitempty"`
+	UpstreamURL string    `json:"upstream_url,omitempty"`
+	Status      string    `json:"status"`
+	Synced      time.Time `json:"synced"`
+}
+
+// scheduler runs the jobs it is given one at a time,
+// as they all go through the very same pipeline
+type scheduler struct {
+	output  string
+	flow    *spotify.Flow
+	jobs    []*job
+	queue   chan *job
+	stopped bool
+	lock    sync.RWMutex
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdServe())
+}
+
+func cmdServe() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "serve",
+		Short:        "Serve a REST API to run and follow synchronizations",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := filepath.Abs(outputPath(cmd, "output"))
+			if err != nil {
+				return err
+			}
+
+			var (
+				remote   = util.ErrWrap(false)(cmd.Flags().GetBool("remote"))
+				token    = util.Fallback(util.ErrWrap("")(cmd.Flags().GetString("token")), os.Getenv("SPOTITUBE_TOKEN"))
+				callback = "127.0.0.1"
+			)
+			// anyone reaching a remote server could otherwise
+			// run jobs on its filesystem and read its index
+			if remote && len(token) == 0 {
+				return errors.New("remote server requires a token (--token or SPOTITUBE_TOKEN)")
+			}
+			if remote {
+				tui.Printf("in order for remote authentication to work, set DNS/hosts entry to make `spotitube.local` resolve to the Spotitube server")
+				callback = "spotitube.local"
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// the session is established through the landing page, if not yet,
+			// while the index is built upfront for it to be queried before any synchronization
+			syncAlive = true
+			defer func() { syncAlive = false }()
+			flow := spotify.NewFlow(func(client *spotify.Client, err error) {
+				if err == nil {
+					err = client.Persist()
+				}
+				if err != nil {
+					tui.Printf("authentication failed: %s", err)
+				}
+			}, callback)
+			_, authErr := flow.Recover()
+			if err := indexData.Load(util.ProfileFile(index.Basename)); err != nil {
+				tui.Printf("index cache unreadable, rebuilding: %s", err)
+			}
+			if err := indexData.Build(path); err != nil {
+				return err
+			}
+
+			var (
+				scheduler = newScheduler(path, flow)
+				server    = spotify.Server(serveMux(scheduler, token), remote)
+				waiter    sync.WaitGroup
+			)
+			waiter.Add(1)
+			go func() {
+				defer waiter.Done()
+				scheduler.run()
+			}()
+			defer waiter.Wait()
+			defer scheduler.stop()
+
+			serverErr := make(chan error, 1)
+			defer close(serverErr)
+			go func() {
+				if err := server.ListenAndServe(); err != http.ErrServerClosed {
+					serverErr <- err
+				}
+			}()
+			tui.Printf("serving on %s", server.Addr)
+			if authErr != nil {
+				tui.Printf("not authenticated yet, authenticate through the landing page on %s", server.Addr)
+			}
+
+			select {
+			case err := <-serverErr:
+				return err
+			case <-ctx.Done():
+				// running jobs get cancelled, which ends their event streams
+				scheduler.stop()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+				defer cancel()
+				return server.Shutdown(shutdownCtx)
+			}
+		},
+	}
+	cmd.Flags().StringP("output", "o", xdg.UserDirs.Music, "Output synchronization path")
+	cmd.Flags().BoolP("remote", "r", false, "Spotitube server is remote (listen on all interfaces, requiring a token)")
+	cmd.Flags().String("token", "", "Token every request has to carry, required if remote (defaults to $SPOTITUBE_TOKEN)")
+	return cmd
+}
+
+// serveMux routes the requests to the API and to the authentication
+// pages, all of which require the given token, if any
+func serveMux(scheduler *scheduler, token string) *http.ServeMux {
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+		mux.HandleFunc(pattern, serveAuthorize(token, handler))
+	}
+	scheduler.flow.Handle(handle)
+	handle("POST /jobs", func(writer http.ResponseWriter, request *http.Request) {
+		var jobRequest jobRequest
+		if err := json.NewDecoder(request.Body).Decode(&jobRequest); err != nil {
+			serveError(writer, http.StatusBadRequest, err)
+			return
+		}
+		job, err := scheduler.submit(util.Fallback(jobRequest.Command, "sync"), jobRequest.Args)
+		if err != nil {
+			serveError(writer, http.StatusBadRequest, err)
+			return
+		}
+		serveJSON(writer, http.StatusAccepted, job)
+	})
+	handle("GET /jobs", func(writer http.ResponseWriter, _ *http.Request) {
+		serveJSON(writer, http.StatusOK, scheduler.list())
+	})
+	handle("GET /jobs/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		if job, ok := serveJob(writer, request, scheduler); ok {
+			serveJSON(writer, http.StatusOK, job)
+		}
+	})
+	handle("DELETE /jobs/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		job, ok := serveJob(writer, request, scheduler)
+		if !ok {
+			return
+		}
+		if !job.abort() {
+			serveError(writer, http.StatusConflict, errors.New("job already finished"))
+			return
+		}
+		serveJSON(writer, http.StatusAccepted, job)
+	})
+	handle("GET /jobs/{id}/events", func(writer http.ResponseWriter, request *http.Request) {
+		if job, ok := serveJob(writer, request, scheduler); ok {
+			serveEvents(writer, request, job)
+		}
+	})
+	handle("GET /index", func(writer http.ResponseWriter, request *http.Request) {
+		status := request.URL.Query().Get("status")
+		tracks := []indexTrack{}
+		for _, entry := range indexData.Entries() {
+			if track := serveIndexTrack(entry); len(status) == 0 || track.Status == status {
+				tracks = append(tracks, track)
+			}
+		}
+		serveJSON(writer, http.StatusOK, tracks)
+	})
+	handle("GET /index/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		entry, ok := indexData.Entry(&entity.Track{ID: request.PathValue("id")})
+		if !ok {
+			serveError(writer, http.StatusNotFound, errors.New("track not indexed"))
+			return
+		}
+		serveJSON(writer, http.StatusOK, serveIndexTrack(entry))
+	})
+	return mux
+}
+
+// serveAuthorize lets the given handler serve only those requests carrying
+// the given token, if any, either as bearer or as the password of a basic
+// authentication, which browsers prompt for and then send along by themselves
+func serveAuthorize(token string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if len(token) == 0 {
+			handler(writer, request)
+			return
+		}
+
+		given, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			_, given, ok = request.BasicAuth()
+		}
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writer.Header().Add("WWW-Authenticate", "Bearer")
+			writer.Header().Add("WWW-Authenticate", `Basic realm="Spotitube"`)
+			serveError(writer, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		handler(writer, request)
+	}
+}
+
+// serveJob returns the job the request refers to, replying
+// on its own if there is no such job
+func serveJob(writer http.ResponseWriter, request *http.Request, scheduler *scheduler) (*job, bool) {
+	job, ok := scheduler.get(request.PathValue("id"))
+	if !ok {
+		serveError(writer, http.StatusNotFound, errors.New("job not found"))
+	}
+	return job, ok
+}
+
+// serveEvents streams the events of the given job as Server-Sent Events,
+// from the very first one, until the job finishes or the client goes away
+func serveEvents(writer http.ResponseWriter, request *http.Request, job *job) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		serveError(writer, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+
+	for sent := 0; ; {
+		events, notify, finished := job.since(sent)
+		for _, event := range events {
+			fmt.Fprintf(writer, "data: %s\n\n", util.ErrWrap([]byte{})(json.Marshal(event)))
+		}
+		sent += len(events)
+		if finished {
+			fmt.Fprintf(writer, "event: end\ndata: %s\n\n", util.ErrWrap([]byte{})(json.Marshal(job)))
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-request.Context().Done():
+			return
+		case <-notify:
+		}
+	}
+}
+
+func serveIndexTrack(entry index.Entry) indexTrack {
+	return indexTrack{entry.ID, entry.Path, entry.UpstreamURL, serveIndexStatuses[entry.Status], entry.Synced}
+}
+
+// serveJSON replies with the given value as JSON
+func serveJSON(writer http.ResponseWriter, code int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+	util.ErrSuppress(json.NewEncoder(writer).Encode(value))
+}
+
+func serveError(writer http.ResponseWriter, code int, err error) {
+	serveJSON(writer, code, map[string]string{"error": err.Error()})
+}
+
+func newScheduler(output string, flow *spotify.Flow) *scheduler {
+	return &scheduler{
+		output: output,
+		flow:   flow,
+		jobs:   []*job{},
+		queue:  make(chan *job, serveQueueSize),
+	}
+}
+
+// submit queues a job running the given command with the given args,
+// as long as they make sense: the output path is the server one
+func (scheduler *scheduler) submit(command string, args []string) (*job, error) {
+	newCmd, ok := serveCommands[command]
+	if !ok {
+		return nil, errors.New("unsupported command: " + command)
+	}
+	cmd := newCmd()
+	if err := cmd.ParseFlags(args); err != nil {
+		return nil, err
+	}
+	if flag := cmd.Flags().Lookup("output"); flag != nil && flag.Changed {
+		return nil, errors.New("output path is set by the server")
+	}
+	// jobs run unattended and share the output of the server
+	if flag := cmd.Flags().Lookup("manual"); flag != nil && flag.Changed {
+		return nil, errors.New("manual mode requires an interactive session")
+	}
+	if flag := cmd.Flags().Lookup("log-format"); flag != nil && flag.Changed {
+		return nil, errors.New("log format is set by the server")
+	}
+	if flag := cmd.Flags().Lookup("report"); flag != nil && flag.Value.String() == "-" {
+		return nil, errors.New("report cannot be written to standard output")
+	}
+
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	if scheduler.stopped {
+		return nil, errors.New("server shutting down")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &job{
+		ID:      strconv.Itoa(len(scheduler.jobs) + 1),
+		Command: command,
+		Args:    append([]string{}, args...),
+		Status:  jobQueued,
+		Created: time.Now(),
+		events:  []anchor.Event{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	select {
+	case scheduler.queue <- job:
+	default:
+		cancel()
+		return nil, errors.New("too many jobs queued")
+	}
+	scheduler.jobs = append(scheduler.jobs, job)
+	return job, nil
+}
+
+func (scheduler *scheduler) get(id string) (*job, bool) {
+	scheduler.lock.RLock()
+	defer scheduler.lock.RUnlock()
+	for _, job := range scheduler.jobs {
+		if job.ID == id {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+func (scheduler *scheduler) list() []*job {
+	scheduler.lock.RLock()
+	defer scheduler.lock.RUnlock()
+	return append([]*job{}, scheduler.jobs...)
+}
+
+// run executes the queued jobs, one after the other, until stopped
+func (scheduler *scheduler) run() {
+	for job := range scheduler.queue {
+		scheduler.execute(job)
+	}
+}
+
+// stop cancels every job, refusing any further one
+func (scheduler *scheduler) stop() {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	if scheduler.stopped {
+		return
+	}
+	scheduler.stopped = true
+	close(scheduler.queue)
+	for _, job := range scheduler.jobs {
+		job.abort()
+	}
+}
+
+// execute runs the command of the given job, taking note of whatever
+// the window shows meanwhile and of whatever the command outputs
+func (scheduler *scheduler) execute(job *job) {
+	if !job.start() {
+		return
+	}
+
+	// jobs run on behalf of the session established at the time
+	if spotifyClient = scheduler.flow.Client(); spotifyClient == nil {
+		job.finish(errors.New("not authenticated, authenticate through the landing page"))
+		return
+	}
+
+	var (
+		cmd    = serveCommands[job.Command]()
+		args   = job.Args
+		parent = &cobra.Command{SilenceErrors: true, SilenceUsage: true}
+	)
+	if cmd.Flags().Lookup("output") != nil {
+		args = append([]string{"--output", scheduler.output}, args...)
+	}
+	parent.AddCommand(cmd)
+	parent.SetArgs(append([]string{cmd.Use}, args...))
+	parent.SetOut(job)
+	parent.SetErr(job)
+
+	unlisten := tui.Listen(job.add)
+	err := parent.ExecuteContext(job.ctx)
+	unlisten()
+
+	// tracks which could not be synchronized
+	// are given another chance by the next job
+	indexData.Forget(index.Online)
+	job.finish(err)
+}
+
+// jobJSON is a job, as encoded by the default encoder
+type jobJSON job
+
+// MarshalJSON encodes the job as it is at the time of calling
+func (job *job) MarshalJSON() ([]byte, error) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	return json.Marshal((*jobJSON)(job))
+}
+
+// Write takes note of each line of the given output as an event
+func (job *job) Write(data []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		job.add(anchor.Event{Time: time.Now().Format(time.RFC3339), Level: "info", Lot: job.Command, Message: line})
+	}
+	return len(data), nil
+}
+
+func (job *job) add(event anchor.Event) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	job.events = append(job.events, event)
+	job.broadcast()
+}
+
+// since returns the events of the job following the first given ones,
+// along with a channel closed on change and whether the job finished:
+// it is meant to be called until finished, waiting for changes in between
+func (job *job) since(offset int) ([]anchor.Event, <-chan struct{}, bool) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	if job.notify == nil {
+		job.notify = make(chan struct{})
+	}
+	return append([]anchor.Event{}, job.events[offset:]...), job.notify, job.finished()
+}
+
+// start marks the job as running, unless cancelled while queued
+func (job *job) start() bool {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	if job.Status != jobQueued {
+		return false
+	}
+	job.Status = jobRunning
+	job.Started = time.Now()
+	job.broadcast()
+	return true
+}
+
+func (job *job) finish(err error) {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	job.Finished = time.Now()
+	if err != nil {
+		job.Error = err.Error()
+	}
+
+	var exitErr *exitError
+	switch {
+	case job.ctx.Err() != nil:
+		job.Status = jobCancelled
+	case err == nil:
+		job.Status = jobDone
+	case errors.As(err, &exitErr) && exitErr.code == exitPartial:
+		job.Status = jobPartial
+	default:
+		job.Status = jobFailed
+	}
+	job.cancel()
+	job.broadcast()
+}
+
+// abort cancels the job, telling whether it was not finished yet
+func (job *job) abort() bool {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	if job.finished() {
+		return false
+	}
+	if job.Status == jobQueued {
+		job.Status = jobCancelled
+		job.Finished = time.Now()
+		job.broadcast()
+	}
+	job.cancel()
+	return true
+}
+
+// finished tells whether the job is over:
+// it expects the job to be locked already
+func (job *job) finished() bool {
+	return job.Status != jobQueued && job.Status != jobRunning
+}
+
+// broadcast wakes up whoever is waiting for the job to change:
+// it expects the job to be locked already
+func (job *job) broadcast() {
+	if job.notify != nil {
+		close(job.notify)
+		job.notify = nil
+	}
+}
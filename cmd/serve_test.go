@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/lyrics"
+	"github.com/streambinder/spotitube/provider"
+	"github.com/streambinder/spotitube/spotify"
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/anchor"
+	"github.com/stretchr/testify/assert"
+	"github.com/thanhpk/randstr"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+func BenchmarkServe(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestServeJobs(&testing.T{})
+	}
+}
+
+// testFlow returns an authentication flow, already authenticated
+func testFlow() *spotify.Flow {
+	defer gomonkey.ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+		return &spotify.Client{}, nil
+	}).Reset()
+
+	flow := spotify.NewFlow(nil)
+	util.ErrSuppress(util.ErrOnly(flow.Recover()))
+	return flow
+}
+
+// testServe issues the given request to the API of the given scheduler
+func testServe(scheduler *scheduler, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	serveMux(scheduler, "").ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func TestCmdServe(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		interrupt context.CancelFunc
+		persisted int
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			interrupt = cancel
+			return ctx, cancel
+		}).
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyFunc(randstr.String, func() string {
+			return "state"
+		}).
+		ApplyMethod(spotifyauth.Authenticator{}, "Token", func() (*oauth2.Token, error) {
+			return &oauth2.Token{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Persist", func() error {
+			persisted++
+			return errors.New("ko")
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Server, func(handler http.Handler, _ bool) *http.Server {
+			// authentication attempts get through the flow of the server
+			for _, state := range []string{"state", "forged"} {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/callback?code=C0D3&state="+state, nil))
+			}
+			interrupt()
+			return &http.Server{Addr: "127.0.0.1:0", Handler: handler, ReadHeaderTimeout: time.Second}
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdServe()))
+	assert.Equal(t, 1, persisted)
+	assert.False(t, syncAlive)
+}
+
+func TestCmdServePathFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.Abs, func() (string, error) {
+		return "", errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdServe()), "ko")
+}
+
+func TestCmdServeUnauthenticated(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var interrupt context.CancelFunc
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			interrupt = cancel
+			return ctx, cancel
+		}).
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			return nil, errors.New("ko")
+		}).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return errors.New("ko")
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Server, func(handler http.Handler, _ bool) *http.Server {
+			interrupt()
+			return &http.Server{Addr: "127.0.0.1:0", Handler: handler, ReadHeaderTimeout: time.Second}
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdServe(), "--remote", "--token", "secret"))
+}
+
+func TestCmdServeRemoteFailure(t *testing.T) {
+	t.Setenv("SPOTITUBE_TOKEN", "")
+
+	// testing
+	assert.EqualError(t, testExecute(cmdServe(), "--remote"), "remote server requires a token (--token or SPOTITUBE_TOKEN)")
+}
+
+func TestCmdServeIndexFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdServe()), "ko")
+}
+
+func TestCmdServeListenFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotify.Recover, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Server, func(handler http.Handler, _ bool) *http.Server {
+			return &http.Server{Addr: "localhost", Handler: handler, ReadHeaderTimeout: time.Second}
+		}).
+		Reset()
+
+	// testing
+	assert.Error(t, testExecute(cmdServe()))
+}
+
+func TestServeJobs(t *testing.T) {
+	var (
+		_track    = &entity.Track{ID: "TestServeJobs", Title: "Title", Artists: []string{"Artist"}}
+		scheduler = newScheduler(t.TempDir(), testFlow())
+		done      = make(chan bool, 1)
+	)
+	defer close(done)
+	syncAlive = true
+	defer func() { syncAlive = false }()
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			panic("jobs run on behalf of the server session")
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func(_ *spotify.Client, _ string, ch ...chan interface{}) (*entity.Track, error) {
+			for _, c := range ch {
+				c <- _track
+			}
+			return _track, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 42}}, nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		Reset()
+
+	// testing
+	go func() {
+		scheduler.run()
+		done <- true
+	}()
+	response := testServe(scheduler, http.MethodPost, "/jobs", `{"command": "lookup", "args": ["123"]}`)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Contains(t, response.Body.String(), `"id":"1"`)
+
+	response = testServe(scheduler, http.MethodGet, "/jobs/1/events", "")
+	assert.Equal(t, "text/event-stream", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "http://localhost/ 42")
+	assert.Contains(t, response.Body.String(), "event: end")
+
+	var jobs []map[string]interface{}
+	response = testServe(scheduler, http.MethodGet, "/jobs", "")
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &jobs))
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, jobDone, jobs[0]["status"])
+	assert.Equal(t, http.StatusOK, testServe(scheduler, http.MethodGet, "/jobs/1", "").Code)
+	assert.Equal(t, http.StatusConflict, testServe(scheduler, http.MethodDelete, "/jobs/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, testServe(scheduler, http.MethodGet, "/jobs/2", "").Code)
+	assert.Equal(t, http.StatusNotFound, testServe(scheduler, http.MethodDelete, "/jobs/2", "").Code)
+	assert.Equal(t, http.StatusNotFound, testServe(scheduler, http.MethodGet, "/jobs/2/events", "").Code)
+
+	scheduler.stop()
+	assert.True(t, <-done)
+}
+
+func TestServeJobsCancel(t *testing.T) {
+	scheduler := newScheduler(t.TempDir(), spotify.NewFlow(nil))
+
+	// testing
+	assert.Equal(t, http.StatusAccepted, testServe(scheduler, http.MethodPost, "/jobs", `{"args": ["-p", "123"]}`).Code)
+	assert.Equal(t, http.StatusAccepted, testServe(scheduler, http.MethodDelete, "/jobs/1", "").Code)
+	job, ok := scheduler.get("1")
+	assert.True(t, ok)
+	assert.Equal(t, "sync", job.Command)
+	assert.Equal(t, jobCancelled, job.Status)
+	assert.Contains(t, testServe(scheduler, http.MethodGet, "/jobs/1/events", "").Body.String(), "event: end")
+
+	scheduler.stop()
+	scheduler.run()
+	assert.Equal(t, jobCancelled, job.Status)
+	assert.Contains(t, testServe(scheduler, http.MethodPost, "/jobs", `{}`).Body.String(), "server shutting down")
+}
+
+func TestServeJobsOutput(t *testing.T) {
+	t.Cleanup(cleanup)
+	scheduler := newScheduler(t.TempDir(), testFlow())
+
+	// testing
+	job, err := scheduler.submit("sync", []string{"--format", "wav"})
+	assert.Nil(t, err)
+	scheduler.execute(job)
+	assert.Equal(t, jobFailed, job.Status)
+	assert.Equal(t, "unsupported format: wav", job.Error)
+}
+
+func TestServeJobsQueueFailure(t *testing.T) {
+	scheduler := newScheduler(t.TempDir(), spotify.NewFlow(nil))
+
+	// testing
+	for range serveQueueSize {
+		assert.Nil(t, util.ErrOnly(scheduler.submit("lookup", []string{})))
+	}
+	assert.EqualError(t, util.ErrOnly(scheduler.submit("lookup", []string{})), "too many jobs queued")
+	assert.Equal(t, serveQueueSize, len(scheduler.jobs))
+}
+
+func TestServeEvents(t *testing.T) {
+	var (
+		recorder    = httptest.NewRecorder()
+		ctx, cancel = context.WithCancel(context.Background())
+		job         = &job{Command: "sync", Status: jobRunning, events: []anchor.Event{}, ctx: ctx, cancel: cancel}
+		done        = make(chan bool)
+	)
+
+	// testing: events are streamed as they come, until the job finishes
+	go func() {
+		serveEvents(recorder, httptest.NewRequest(http.MethodGet, "/jobs/1/events", nil), job)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		job.lock.Lock()
+		defer job.lock.Unlock()
+		return job.notify != nil
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, util.ErrOnly(job.Write([]byte("streamed\n"))))
+	job.finish(nil)
+	<-done
+	assert.Contains(t, recorder.Body.String(), "streamed")
+	assert.Contains(t, recorder.Body.String(), "event: end")
+}
+
+func TestServeEventsFailure(t *testing.T) {
+	var (
+		recorder    = httptest.NewRecorder()
+		ctx, cancel = context.WithCancel(context.Background())
+		job         = &job{Status: jobRunning, events: []anchor.Event{}}
+	)
+
+	// testing
+	serveEvents(struct{ http.ResponseWriter }{recorder}, httptest.NewRequest(http.MethodGet, "/jobs/1/events", nil), job)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// clients going away stop following the job
+	cancel()
+	recorder = httptest.NewRecorder()
+	serveEvents(recorder, httptest.NewRequest(http.MethodGet, "/jobs/1/events", nil).WithContext(ctx), job)
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+}
+
+func TestServeJobsUnauthenticated(t *testing.T) {
+	scheduler := newScheduler(t.TempDir(), spotify.NewFlow(nil))
+
+	// testing
+	assert.Contains(t, testServe(scheduler, http.MethodGet, "/", "").Body.String(), "Authenticate with Spotify")
+	assert.Equal(t, http.StatusAccepted, testServe(scheduler, http.MethodPost, "/jobs", `{"args": ["-p", "123"]}`).Code)
+	job, ok := scheduler.get("1")
+	assert.True(t, ok)
+	scheduler.execute(job)
+	assert.Equal(t, jobFailed, job.Status)
+	assert.Contains(t, job.Error, "not authenticated")
+}
+
+func TestServeJobsFailure(t *testing.T) {
+	scheduler := newScheduler(t.TempDir(), spotify.NewFlow(nil))
+
+	// testing
+	for body, message := range map[string]string{
+		`{`:                                      "unexpected EOF",
+		`{"command": "reset"}`:                   "unsupported command: reset",
+		`{"args": ["--unknown"]}`:                "unknown flag: --unknown",
+		`{"args": ["-o", "/"]}`:                  "output path is set by the server",
+		`{"args": ["-m"]}`:                       "manual mode requires an interactive session",
+		`{"args": ["--log-format", "json"]}`:     "log format is set by the server",
+		`{"args": ["--report", "-"]}`:            "report cannot be written to standard output",
+		`{"args": ["--report", "report.json"]}`:  "",
+		`{"command": "lookup", "args": ["-x"]}`:  "unknown shorthand flag: 'x' in -x",
+		`{"command": "attach", "args": ["-r"]}`:  "",
+		`{"command": "sync", "args": ["-l"]}`:    "",
+		`{"command": "lookup", "args": ["123"]}`: "",
+	} {
+		response := testServe(scheduler, http.MethodPost, "/jobs", body)
+		if len(message) > 0 {
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Contains(t, response.Body.String(), message)
+		} else {
+			assert.Equal(t, http.StatusAccepted, response.Code)
+		}
+	}
+}
+
+func TestServeIndex(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		_track    = &entity.Track{ID: "TestServeIndex", Title: "Title", Artists: []string{"Artist"}}
+		scheduler = newScheduler(t.TempDir(), spotify.NewFlow(nil))
+		tracks    []indexTrack
+	)
+	indexData.Set(_track, index.Online)
+
+	// testing
+	assert.Nil(t, json.Unmarshal(testServe(scheduler, http.MethodGet, "/index", "").Body.Bytes(), &tracks))
+	assert.Equal(t, []indexTrack{{ID: _track.ID, Status: "online"}}, tracks)
+	assert.Nil(t, json.Unmarshal(testServe(scheduler, http.MethodGet, "/index?status=installed", "").Body.Bytes(), &tracks))
+	assert.Empty(t, tracks)
+	assert.Equal(t, http.StatusOK, testServe(scheduler, http.MethodGet, "/index/TestServeIndex", "").Code)
+	assert.Equal(t, http.StatusNotFound, testServe(scheduler, http.MethodGet, "/index/missing", "").Code)
+}
+
+func TestServeToken(t *testing.T) {
+	var (
+		scheduler = newScheduler(t.TempDir(), spotify.NewFlow(nil))
+		mux       = serveMux(scheduler, "secret")
+	)
+
+	// testing
+	for authorization, code := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		for _, target := range []string{"/jobs", "/index", "/"} {
+			recorder, request := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil)
+			if len(authorization) > 0 {
+				request.Header.Set("Authorization", authorization)
+			}
+			mux.ServeHTTP(recorder, request)
+			assert.Equal(t, code, recorder.Code)
+		}
+	}
+
+	// authentication pages are not reachable without either
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/callback", nil),
+		httptest.NewRequest(http.MethodPost, "/logout", nil),
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, []string{"Bearer", `Basic realm="Spotitube"`}, recorder.Header().Values("WWW-Authenticate"))
+	}
+
+	// browsers send the token as password
+	for password, code := range map[string]int{
+		"wrong":  http.StatusUnauthorized,
+		"secret": http.StatusOK,
+	} {
+		recorder, request := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+		request.SetBasicAuth("user", password)
+		mux.ServeHTTP(recorder, request)
+		assert.Equal(t, code, recorder.Code)
+		if code == http.StatusOK {
+			assert.Contains(t, recorder.Body.String(), "Authenticate with Spotify")
+		}
+	}
+}
+
+func TestJobFinish(t *testing.T) {
+	for err, status := range map[error]string{
+		nil:                             jobDone,
+		errors.New("ko"):                jobFailed,
+		&exitError{errors.New("ko"), 2}: jobPartial,
+		&exitError{errors.New("ko"), 1}: jobFailed,
+		context.Canceled:                jobCancelled,
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		if errors.Is(err, context.Canceled) {
+			cancel()
+		}
+		job := &job{Status: jobRunning, ctx: ctx, cancel: cancel}
+		job.finish(err)
+		assert.Equal(t, status, job.Status)
+		assert.False(t, job.abort())
+	}
+}
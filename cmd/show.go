@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"text/tabwriter"
 
-	"github.com/bogem/id3v2/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/tag"
 	"github.com/streambinder/spotitube/util"
 )
 
@@ -24,17 +22,17 @@ func cmdShow() *cobra.Command {
 		Short:        "Show local tracks data",
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			bold := color.New(color.Bold)
 			for _, path := range args {
 				if err := func() error {
-					tag, err := id3.Open(path, id3v2.Options{Parse: true})
+					tag, err := tag.Open(path, true)
 					if err != nil {
 						return err
 					}
 					defer tag.Close()
 
-					table := tabwriter.NewWriter(os.Stdout, 0, 0, 0, ' ', tabwriter.AlignRight)
+					table := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 0, ' ', tabwriter.AlignRight)
 					fmt.Fprintln(table, "Path\t", bold.Sprint(path))
 					fmt.Fprintln(table, "Spotify ID\t", util.Fallback(tag.SpotifyID(), fallback))
 					fmt.Fprintln(table, "Title\t", util.Fallback(tag.Title(), fallback))
@@ -42,9 +40,14 @@ func cmdShow() *cobra.Command {
 					fmt.Fprintln(table, "Album\t", util.Fallback(tag.Album(), fallback))
 					fmt.Fprintln(table, "Year\t", util.Fallback(tag.Year(), fallback))
 					fmt.Fprintln(table, "Track number\t", util.Fallback(tag.TrackNumber(), fallback))
+					fmt.Fprintln(table, "Disc number\t", util.Fallback(tag.DiscNumber(), fallback))
 					fmt.Fprintln(table, "Artwork URL\t", util.Fallback(tag.ArtworkURL(), fallback))
 					fmt.Fprintln(table, "Duration\t", util.Fallback(fmt.Sprintf("%ss", tag.Duration()), fallback))
 					fmt.Fprintln(table, "Upstream URL\t", util.Fallback(tag.UpstreamURL(), fallback))
+					fmt.Fprintln(table, "Added date\t", util.Fallback(tag.AddedDate(), fallback))
+					if descriptionFields(tag) {
+						fmt.Fprintln(table, "Custom fields\t", "description atom (no freeform atoms)")
+					}
 					fmt.Fprintln(table, "Lyrics\t", util.Fallback(util.Excerpt(tag.UnsynchronizedLyrics(), 64), fallback))
 					fmt.Fprintln(table, "Artwork\t", func(mimeType string, data []byte) string {
 						if len(data) > 0 {
@@ -61,3 +64,10 @@ func cmdShow() *cobra.Command {
 		},
 	}
 }
+
+// descriptionFields tells whether the given tag keeps custom fields
+// as lines of its description, as M4A ones do
+func descriptionFields(data tag.Tag) bool {
+	_, ok := data.(*tag.MP4)
+	return ok
+}
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/streambinder/spotitube/entity/id3"
 	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,3 +59,28 @@ func TestCmdShowPictureFallback(t *testing.T) {
 	// testing
 	assert.Nil(t, util.ErrOnly(testExecute(cmdShow(), "path/to/track")))
 }
+
+func TestCmdShowMP4(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: map[string]string{"description": "spotify_id: 123"}}, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, nil
+		}).
+		Reset()
+
+	// testing
+	var (
+		output bytes.Buffer
+		show   = cmdShow()
+		path   = filepath.Join(t.TempDir(), "track.m4a")
+	)
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	show.SetArgs([]string{path})
+	show.SetOut(&output)
+	assert.Nil(t, show.Execute())
+	assert.Contains(t, output.String(), "123")
+	assert.Contains(t, output.String(), "description atom")
+}
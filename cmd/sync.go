@@ -2,25 +2,33 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/arunsworld/nursery"
-	"github.com/bogem/id3v2/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/streambinder/spotitube/downloader"
 	"github.com/streambinder/spotitube/entity"
-	"github.com/streambinder/spotitube/entity/id3"
 	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/journal"
 	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/streambinder/spotitube/entity/tag"
 	"github.com/streambinder/spotitube/lyrics"
 	"github.com/streambinder/spotitube/processor"
 	"github.com/streambinder/spotitube/provider"
@@ -29,6 +37,13 @@ import (
 	"github.com/streambinder/spotitube/util/anchor"
 )
 
+const (
+	newReleasesBasename = "releases.json"
+	newReleasesLookback = 30 * 24 * time.Hour // on the very first check
+	lastRunBasename     = "last-run.json"
+	sinceLastRun        = "last-run" // each collection since its own last synchronization
+)
+
 const (
 	routineTypeIndex int = iota
 	routineTypeAuth
@@ -43,8 +58,17 @@ var (
 	routineSemaphores map[int](chan bool)
 	routineQueues     map[int](chan interface{})
 	tui               = anchor.New(anchor.Red)
+	syncPlan          *plan   // only set on dry runs
+	syncReport        *report // only set on actual synchronizations
+	syncAlive         bool    // client and index kept across synchronizations
+	errMatchesFailed  = errors.New("no match could be downloaded")
 )
 
+// watermark tells when the releases of the followed artists were last checked
+type watermark struct {
+	Checked time.Time `json:"checked"`
+}
+
 func init() {
 	cmdRoot.AddCommand(cmdSync())
 }
@@ -55,48 +79,224 @@ func cmdSync() *cobra.Command {
 		Short:        "Synchronize collections",
 		SilenceUsage: true,
 		Args:         cobra.NoArgs,
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
 			var (
-				path             = util.ErrWrap(xdg.UserDirs.Music)(cmd.Flags().GetString("output"))
+				path             = outputPath(cmd, "output")
 				playlistEncoding = util.ErrWrap("m3u")(cmd.Flags().GetString("playlist-encoding"))
 				manual           = util.ErrWrap(false)(cmd.Flags().GetBool("manual"))
 				library          = util.ErrWrap(false)(cmd.Flags().GetBool("library"))
+				savedAlbums      = util.ErrWrap(false)(cmd.Flags().GetBool("saved-albums"))
+				newReleases      = util.ErrWrap(false)(cmd.Flags().GetBool("new-releases"))
 				playlists        = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist"))
 				playlistsTracks  = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist-tracks"))
+				archives         = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("archive"))
+				archiveRetention = util.ErrWrap(0)(cmd.Flags().GetInt("archive-retention"))
 				albums           = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("album"))
+				artists          = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("artist"))
+				artistInclude    = util.ErrWrap(spotify.AlbumTypes)(cmd.Flags().GetStringSlice("artist-include"))
+				artistExclude    = util.ErrWrap([]string{})(cmd.Flags().GetStringSlice("artist-exclude"))
 				tracks           = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("track"))
 				fixes            = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("fix"))
+				likeFixes        = util.ErrWrap(false)(cmd.Flags().GetBool("like"))
 				libraryLimit     = util.ErrWrap(0)(cmd.Flags().GetInt("library-limit"))
+				since            = util.ErrWrap("")(cmd.Flags().GetString("since"))
 				lyrics           = util.ErrWrap(false)(cmd.Flags().GetBool("lyrics"))
+				resume           = util.ErrWrap(false)(cmd.Flags().GetBool("resume"))
+				fallbackDepth    = util.ErrWrap(3)(cmd.Flags().GetInt("fallback-depth"))
+				tolerance        = util.ErrWrap(20)(cmd.Flags().GetInt("duration-tolerance"))
+				format           = util.ErrWrap(entity.TrackFormats[0])(cmd.Flags().GetString("format"))
+				pathTemplate     = util.ErrWrap("")(cmd.Flags().GetString("path-template"))
+				prune            = util.ErrWrap(false)(cmd.Flags().GetBool("prune"))
+				pruneArchive     = util.ErrWrap("")(cmd.Flags().GetString("prune-archive"))
+				pruneLimit       = util.ErrWrap(20)(cmd.Flags().GetInt("prune-limit"))
+				pruneDryRun      = util.ErrWrap(false)(cmd.Flags().GetBool("prune-dry-run"))
+				dryRun           = util.ErrWrap(false)(cmd.Flags().GetBool("dry-run"))
+				planFormat       = util.ErrWrap("table")(cmd.Flags().GetString("plan-format"))
+				reportPath       = util.ErrWrap("")(cmd.Flags().GetString("report"))
+				logFormat        = util.ErrWrap(anchor.FormatAuto)(cmd.Flags().GetString("log-format"))
+				app              = util.ErrWrap(false)(cmd.Flags().GetBool("client-credentials"))
+				synced           = make(map[string]bool)
+				started          = time.Now()
 			)
 
+			syncPlan, syncReport = nil, nil
+			if dryRun {
+				if !slices.Contains(planFormats, planFormat) {
+					return errors.New("unsupported plan format: " + planFormat)
+				}
+				if manual {
+					return errors.New("manual mode cannot be dry-run")
+				}
+				syncPlan = newPlan()
+				pruneDryRun = true
+			} else {
+				syncReport = newReport()
+			}
+
+			// neither a report nor a plan written to the
+			// standard output are to be mixed up with logs
+			if reportPath == "-" || dryRun {
+				tui.SetOutput(os.Stderr)
+			} else {
+				tui.SetOutput(nil)
+			}
+			if err := tui.SetFormat(logFormat); err != nil {
+				return err
+			}
+			if !slices.Contains(entity.TrackFormats, format) {
+				return errors.New("unsupported format: " + format)
+			}
+			entity.TrackFormat = format
+			artistTypes := []string{}
+			for _, albumType := range append(artistInclude, artistExclude...) {
+				if !slices.Contains(spotify.AlbumTypes, albumType) {
+					return errors.New("unsupported album type: " + albumType)
+				}
+			}
+			for _, albumType := range artistInclude {
+				if !slices.Contains(artistExclude, albumType) {
+					artistTypes = append(artistTypes, albumType)
+				}
+			}
+			if err := entity.SetPathTemplate(pathTemplate); err != nil {
+				return err
+			}
+			if archiveRetention < 0 {
+				return errors.New("archive retention cannot be negative")
+			}
+			// tracks added earlier (or further down the library)
+			// are not fetched, yet they belong to the collections
+			if prune && (len(since) > 0 || libraryLimit > 0) {
+				return errors.New("incremental synchronization cannot be pruned")
+			}
+			collectionsSince, err := syncSince(since, started)
+			if err != nil {
+				return err
+			}
+
 			for index, path := range fixes {
 				if absPath, err := filepath.Abs(path); err == nil {
 					fixes[index] = absPath
 				}
 			}
+			if len(pruneArchive) > 0 {
+				pruneArchive = util.ErrWrap(pruneArchive)(filepath.Abs(pruneArchive))
+			}
+			if len(reportPath) > 0 && reportPath != "-" {
+				reportPath = util.ErrWrap(reportPath)(filepath.Abs(reportPath))
+			}
 
 			if err := os.Chdir(path); err != nil {
 				return err
 			}
 
-			if err := nursery.RunConcurrently(
-				routineIndex(path),
-				routineAuth,
-				routineFetch(library, playlists, playlistsTracks, albums, tracks, fixes, libraryLimit),
+			// whichever way the synchronization ends, it gets reported
+			defer func() {
+				err = syncReport.finish(err)
+				if len(reportPath) > 0 {
+					if reportErr := syncReport.write(reportPath); reportErr != nil && err == nil {
+						err = reportErr
+					}
+				}
+			}()
+
+			if resume {
+				if err := journalData.Load(); err != nil {
+					return err
+				}
+			} else if !dryRun {
+				if err := journalData.Clear(); err != nil {
+					return err
+				}
+			}
+
+			// on interruption, routines stop handling tracks and drain their
+			// queues, so that whatever got installed still gets mixed
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				// a second interruption terminates straight away
+				<-ctx.Done()
+				stop()
+			}()
+
+			if err := nursery.RunConcurrentlyWithContext(ctx,
+				routineIndex(path, app),
+				routineAuth(app),
+				routineFetch(library, savedAlbums, newReleases, likeFixes, playlists, playlistsTracks, archives, albums, artists, artistTypes, tracks, fixes, libraryLimit, archiveRetention, collectionsSince, synced),
 				routineDecide(manual, path),
-				routineCollect(lyrics),
+				routineCollect(lyrics, fallbackDepth, processor.Verifier{Tolerance: tolerance}),
 				routineProcess,
 				routineInstall,
 				routineMix(playlistEncoding),
 			); err != nil {
+				// whatever got installed so far is on disk
+				// and has to be remembered anyway
+				if !dryRun {
+					util.ErrSuppress(indexData.Persist(util.ProfileFile(index.Basename)))
+				}
+				if ctx.Err() != nil {
+					return errors.New("synchronization interrupted")
+				}
+				return err
+			}
+
+			// only a complete synchronization tells
+			// which tracks do not belong to it anymore
+			if prune && ctx.Err() == nil {
+				if err := syncPrune(synced, pruneArchive, pruneLimit, pruneDryRun); err != nil {
+					util.ErrSuppress(indexData.Persist(util.ProfileFile(index.Basename)))
+					return err
+				}
+			}
+
+			// nothing has been touched, neither the index
+			// nor the journal are worth being persisted
+			if dryRun {
+				if ctx.Err() != nil {
+					return errors.New("synchronization interrupted")
+				}
+				return syncPlan.print(cmd.OutOrStdout(), planFormat)
+			}
+
+			if err := indexData.Persist(util.ProfileFile(index.Basename)); err != nil {
+				return err
+			}
+
+			// journal is kept for the synchronization to be resumed
+			if ctx.Err() != nil {
+				return errors.New("synchronization interrupted")
+			}
+
+			// nothing left to be resumed
+			if err := journalData.Clear(); err != nil {
+				return err
+			}
+
+			// releases coming out meanwhile get found on the next check
+			if newReleases {
+				if err := newReleasesPersist(started); err != nil {
+					return err
+				}
+			}
+
+			// tracks added meanwhile get found by the next synchronization since the last run,
+			// unless only the latest ones of the library have been gone through
+			collections := []string{}
+			if library && libraryLimit == 0 {
+				collections = append(collections, daemonLibrary)
+			}
+			for _, id := range slices.Concat(playlists, playlistsTracks, archives) {
+				collections = append(collections, "playlist:"+id)
+			}
+			if err := lastRunPersist(collections, started); err != nil {
 				return err
 			}
 
 			tui.Printf("synchronization complete")
 			return nil
 		},
-		PreRun: func(cmd *cobra.Command, _ []string) {
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			routineSemaphores = map[int](chan bool){
 				routineTypeIndex:   make(chan bool, 1),
 				routineTypeAuth:    make(chan bool, 1),
@@ -111,74 +311,84 @@ func cmdSync() *cobra.Command {
 			}
 
 			var (
+				savedAlbums     = util.ErrWrap(false)(cmd.Flags().GetBool("saved-albums"))
+				newReleases     = util.ErrWrap(false)(cmd.Flags().GetBool("new-releases"))
 				playlists       = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist"))
 				playlistsTracks = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("playlist-tracks"))
+				archives        = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("archive"))
 				albums          = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("album"))
+				artists         = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("artist"))
 				tracks          = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("track"))
 				fixes           = util.ErrWrap([]string{})(cmd.Flags().GetStringArray("fix"))
 			)
-			if len(playlists)+len(playlistsTracks)+len(albums)+len(tracks)+len(fixes) == 0 {
+			if !savedAlbums && !newReleases &&
+				len(playlists)+len(playlistsTracks)+len(archives)+len(albums)+len(artists)+len(tracks)+len(fixes) == 0 {
 				cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
 					if f.Name == "library" {
 						util.ErrSuppress(f.Value.Set("true"))
 					}
 				})
 			}
+
+			// fail straight away rather than halfway through the synchronization
+			if !util.ErrWrap(false)(cmd.Flags().GetBool("client-credentials")) {
+				return nil
+			}
+			for _, flag := range []string{"library", "saved-albums", "new-releases"} {
+				if util.ErrWrap(false)(cmd.Flags().GetBool(flag)) {
+					return fmt.Errorf("%s cannot be synchronized: %w", flag, spotify.ErrUserRequired)
+				}
+			}
+			if util.ErrWrap(false)(cmd.Flags().GetBool("like")) {
+				return fmt.Errorf("fixed tracks cannot be liked: %w", spotify.ErrUserRequired)
+			}
+			return nil
 		},
 	}
 	cmd.Flags().StringP("output", "o", xdg.UserDirs.Music, "Output synchronization path")
 	cmd.Flags().String("playlist-encoding", "m3u", "Playlist output files encoding")
 	cmd.Flags().BoolP("manual", "m", false, "Enable manual mode (prompts for user-issued URL to use for download)")
 	cmd.Flags().BoolP("library", "l", false, "Synchronize library (auto-enabled if no collection is supplied)")
+	cmd.Flags().Bool("saved-albums", false, "Synchronize albums saved into the library, each one whole")
+	cmd.Flags().Bool("new-releases", false, "Synchronize followed artists' albums and singles released since the last check")
 	cmd.Flags().StringArrayP("playlist", "p", []string{}, "Synchronize playlist")
 	cmd.Flags().StringArray("playlist-tracks", []string{}, "Synchronize playlist tracks without playlist file")
+	cmd.Flags().StringArray("archive", []string{}, "Synchronize rotating playlist, also archiving a weekly snapshot of it")
+	cmd.Flags().Int("archive-retention", 0, "Number of weeks archived playlist snapshots are kept for (unlimited if 0)")
 	cmd.Flags().StringArrayP("album", "a", []string{}, "Synchronize album")
+	cmd.Flags().StringArray("artist", []string{}, "Synchronize artist discography (by ID, URL or name)")
+	cmd.Flags().StringSlice("artist-include", spotify.AlbumTypes, "Types of artist releases to synchronize ("+strings.Join(spotify.AlbumTypes, ", ")+")")
+	cmd.Flags().StringSlice("artist-exclude", []string{}, "Types of artist releases not to synchronize")
 	cmd.Flags().StringArrayP("track", "t", []string{}, "Synchronize track")
 	cmd.Flags().StringArrayP("fix", "f", []string{}, "Fix local track")
+	cmd.Flags().Bool("like", false, "Save fixed tracks into the library, i.e. among the liked songs")
 	cmd.Flags().Int("library-limit", 0, "Number of tracks to fetch from library (unlimited if 0)")
+	cmd.Flags().String("since", "", "Only synchronize library and playlist tracks added since a date (e.g. 2006-01-02), a duration ago (e.g. 36h or 7d) or \""+sinceLastRun+"\", i.e. each collection's last complete synchronization")
 	cmd.Flags().BoolP("lyrics", "y", false, "Fetch lyrics from genius")
+	cmd.Flags().Bool("resume", false, "Resume interrupted synchronization")
+	cmd.Flags().Int("fallback-depth", 3, "Number of matches to try downloading before giving up on a track")
+	cmd.Flags().Int("duration-tolerance", 20, "Seconds a downloaded track may last more or less than expected")
+	cmd.Flags().String("format", entity.TrackFormats[0], "Output tracks format ("+strings.Join(entity.TrackFormats, ", ")+")")
+	cmd.Flags().Bool("prune", false, "Remove local tracks not belonging to any of the synchronized collections")
+	cmd.Flags().String("prune-archive", "", "Move pruned tracks into this path instead of removing them")
+	cmd.Flags().Int("prune-limit", 20, "Maximum number of tracks to prune at once (unlimited if 0)")
+	cmd.Flags().Bool("prune-dry-run", false, "Only show which tracks would be pruned")
+	cmd.Flags().BoolP("dry-run", "n", false, "Only show what the synchronization would do, with no changes applied (implies --prune-dry-run)")
+	cmd.Flags().String("report", "", "Write a JSON report of the synchronization to this path (\"-\" for standard output)")
+	cmd.Flags().String("log-format", anchor.FormatAuto, "Output format ("+strings.Join(anchor.Formats, ", ")+")")
+	cmd.Flags().String("plan-format", "table", "Dry-run plan output format ("+strings.Join(planFormats, ", ")+")")
+	cmd.Flags().Bool("client-credentials", false, "Authenticate as the application, with no user login (public playlists, albums and tracks only)")
+	cmd.Flags().String("path-template", "", "Template of tracks path, relative to output path and with no extension (e.g. \"{{index .Artists 0}}/{{.Year}} - {{.Album}}/{{printf \"%02d\" .Number}} {{.Title}}\")")
 	return cmd
 }
 
 // fuzzyFindTracksForSpotify attempts to match local files without Spotify IDs to Spotify tracks
-func fuzzyFindTracksForSpotify(localPath string, tracks []*entity.Track) error {
+func fuzzyFindTracksForSpotify(ctx context.Context, paths []string, tracks []*entity.Track) error {
 	// Map to store paths of files without Spotify IDs
 	filesWithoutIDs := map[string]bool{}
-
-	// First pass - collect all MP3 files without Spotify IDs
-	err := filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		// Only process MP3 files
-		if !strings.HasSuffix(strings.ToLower(path), entity.TrackFormat) {
-			return nil
-		}
-
-		// Check if file has Spotify ID
-		tag, err := id3.Open(path, id3v2.Options{Parse: true})
-		if err != nil {
-			return nil // Skip files we can't open
-		}
-		defer tag.Close()
-
-		// If no Spotify ID, add to our map
-		if len(tag.SpotifyID()) == 0 {
-			filesWithoutIDs[path] = true
-			tui.Printf("Found file without Spotify ID: %s", filepath.Base(path))
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return err
+	for _, path := range paths {
+		filesWithoutIDs[path] = true
+		tui.Printf("Found file without Spotify ID: %s", filepath.Base(path))
 	}
 
 	// No files without IDs found
@@ -295,7 +505,7 @@ func fuzzyFindTracksForSpotify(localPath string, tracks []*entity.Track) error {
 
 				if strings.ToLower(confirmation) == "y" {
 					// Update the ID3 tags
-					tag, err := id3.Open(filePath, id3v2.Options{Parse: true})
+					tag, err := tag.Open(filePath, true)
 					if err != nil {
 						tui.Printf("Failed to open file for tagging: %s", err)
 						continue
@@ -304,13 +514,15 @@ func fuzzyFindTracksForSpotify(localPath string, tracks []*entity.Track) error {
 					tag.SetSpotifyID(track.ID)
 					tag.SetTitle(track.Title)
 					tag.SetArtist(track.Artists[0])
+					tag.SetArtists(track.Artists)
 					tag.SetAlbum(track.Album)
 					tag.SetArtworkURL(track.Artwork.URL)
 					tag.SetDuration(strconv.Itoa(track.Duration))
 					tag.SetTrackNumber(strconv.Itoa(track.Number))
+					tag.SetDiscNumber(strconv.Itoa(track.Disc))
 					tag.SetYear(strconv.Itoa(track.Year))
 
-					if err := tag.Save(); err != nil {
+					if err := tag.Save(ctx); err != nil {
 						tui.Printf("Failed to save tags: %s", err)
 						tag.Close()
 						continue
@@ -335,12 +547,22 @@ func fuzzyFindTracksForSpotify(localPath string, tracks []*entity.Track) error {
 
 // indexer scans a possible local music library
 // to be considered as already synchronized
-func routineIndex(path string) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
+func routineIndex(path string, app bool) func(context.Context, chan error) {
+	return func(ctx context.Context, ch chan error) {
 		// remember to signal fetcher
 		defer close(routineSemaphores[routineTypeIndex])
 
+		// daemons and servers build the index again on every synchronization,
+		// as files may have been moved or deleted meanwhile: those already
+		// known are not parsed again unless changed, nor matched if untagged
+		rebuild := syncAlive && indexData.Built()
+
 		tui.Lot("index").Printf("scanning")
+		if !rebuild {
+			if err := indexData.Load(util.ProfileFile(index.Basename)); err != nil {
+				tui.Printf("index cache unreadable, rebuilding: %s", err)
+			}
+		}
 		if err := indexData.Build(path); err != nil {
 			tui.Printf("indexing failed: %s", err)
 			routineSemaphores[routineTypeIndex] <- false
@@ -351,6 +573,13 @@ func routineIndex(path string) func(context.Context, chan error) {
 
 		// Before we signal that indexing is complete, check if we should
 		// try to match local files without Spotify IDs to Spotify tracks
+		untagged := indexData.Untagged()
+		if len(untagged) == 0 || syncPlan != nil || app || rebuild {
+			tui.Lot("index").Close(strconv.Itoa(indexData.Size()) + " tracks")
+			routineSemaphores[routineTypeIndex] <- true
+			return
+		}
+
 		spotifyClient, err := spotify.Authenticate(spotify.BrowserProcessor)
 		if err != nil {
 			tui.Printf("authentication for fuzzy matching failed: %s", err)
@@ -365,7 +594,7 @@ func routineIndex(path string) func(context.Context, chan error) {
 
 		// Fetch tracks from the user's Spotify library
 		go func() {
-			if err := spotifyClient.Library(100, tracksChan); err != nil {
+			if err := spotifyClient.Library(100, time.Time{}, tracksChan); err != nil {
 				tui.Printf("Error fetching library: %s", err)
 				close(tracksChan)
 				return
@@ -381,7 +610,7 @@ func routineIndex(path string) func(context.Context, chan error) {
 		// If we got tracks, try to match them against local files without Spotify IDs
 		if len(trackList) > 0 {
 			tui.Lot("index").Printf("attempting to match files with Spotify tracks")
-			if err := fuzzyFindTracksForSpotify(path, trackList); err != nil {
+			if err := fuzzyFindTracksForSpotify(ctx, untagged, trackList); err != nil {
 				tui.Printf("Error during fuzzy matching: %s", err)
 			}
 		}
@@ -393,29 +622,55 @@ func routineIndex(path string) func(context.Context, chan error) {
 	}
 }
 
-func routineAuth(_ context.Context, ch chan error) {
-	// remember to close auth semaphore
-	defer close(routineSemaphores[routineTypeAuth])
+func routineAuth(app bool) func(context.Context, chan error) {
+	return func(_ context.Context, ch chan error) {
+		// remember to close auth semaphore
+		defer close(routineSemaphores[routineTypeAuth])
 
-	tui.Lot("auth").Printf("authenticating")
-	var err error
-	spotifyClient, err = spotify.Authenticate(spotify.BrowserProcessor)
-	if err != nil {
-		tui.Printf("authentication failed: %s", err)
-		routineSemaphores[routineTypeAuth] <- false
-		ch <- err
-		return
+		if syncAlive && spotifyClient != nil {
+			tui.Lot("auth").Close()
+			routineSemaphores[routineTypeAuth] <- true
+			return
+		}
+
+		tui.Lot("auth").Printf("authenticating")
+		var err error
+		spotifyClient, err = syncAuthenticate(app)
+		if err != nil {
+			tui.Printf("authentication failed: %s", err)
+			routineSemaphores[routineTypeAuth] <- false
+			ch <- err
+			return
+		}
+		tui.Lot("auth").Close()
+
+		// once authenticated, signal fetcher
+		routineSemaphores[routineTypeAuth] <- true
+	}
+}
+
+// syncAuthenticate authenticates either as the user or,
+// using client credentials, as the application itself
+func syncAuthenticate(app bool) (*spotify.Client, error) {
+	if app {
+		return spotify.AuthenticateApp()
 	}
-	tui.Lot("auth").Close()
+	return spotify.Authenticate(spotify.BrowserProcessor)
+}
 
-	// once authenticated, signal fetcher
-	routineSemaphores[routineTypeAuth] <- true
+// sessionClient returns the client of the session a daemon or a server keeps
+// alive, if any, so that no other flow is opened: it authenticates otherwise
+func sessionClient() (*spotify.Client, error) {
+	if syncAlive && spotifyClient != nil {
+		return spotifyClient, nil
+	}
+	return spotify.Authenticate(spotify.BrowserProcessor)
 }
 
 // fetcher pulls data from the upstream
 // provider, i.e. Spotify
-func routineFetch(library bool, playlists, playlistsTracks, albums, tracks, fixes []string, libraryLimit int) func(ctx context.Context, ch chan error) {
-	return func(_ context.Context, ch chan error) {
+func routineFetch(library, savedAlbums, newReleases, likeFixes bool, playlists, playlistsTracks, archives, albums, artists, artistTypes, tracks, fixes []string, libraryLimit, archiveRetention int, since func(collection string) time.Time, synced map[string]bool) func(ctx context.Context, ch chan error) {
+	return func(ctx context.Context, ch chan error) {
 		// remember to stop passing data to decider and mixer
 		defer close(routineQueues[routineTypeDecide])
 		defer close(routineQueues[routineTypeMix])
@@ -427,18 +682,37 @@ func routineFetch(library bool, playlists, playlistsTracks, albums, tracks, fixe
 			return
 		}
 
-		fetched := make(chan interface{}, 10000)
+		var (
+			fetched = make(chan interface{}, 10000)
+			waiter  sync.WaitGroup
+		)
+		// synced tracks are all known once fetch is over
+		defer waiter.Wait()
 		defer close(fetched)
+		waiter.Add(1)
 		go func() {
-			counter := 0
+			defer waiter.Done()
+			var (
+				counter    = 0
+				collection string
+			)
 			for event := range fetched {
+				// each collection is announced before its tracks
+				if name, ok := event.(string); ok {
+					collection = name
+					continue
+				}
 				counter++
 				track := event.(*entity.Track)
+				synced[track.ID] = true
+				syncReport.fetch(track, collection)
 				tui.Lot("fetch").Printf("%s by %s", track.Title, track.Artists[0])
 			}
 			tui.Lot("fetch").Close(fmt.Sprintf("%d tracks", counter))
 		}()
 
+		routineFetchJournal(fetched)
+
 		fixesTracks, fixesErr := routineFetchFixesIDs(fixes)
 		if fixesErr != nil {
 			ch <- fixesErr
@@ -446,21 +720,80 @@ func routineFetch(library bool, playlists, playlistsTracks, albums, tracks, fixe
 		}
 		tracks = append(tracks, fixesTracks...)
 
-		if err := routineFetchLibrary(library, libraryLimit, fetched); err != nil {
-			ch <- err
-			return
+		// fixed tracks get known by library synchronizations too
+		if likeFixes && len(fixesTracks) > 0 && syncPlan == nil {
+			tui.Lot("fetch").Printf("liking %d fixed tracks", len(fixesTracks))
+			if err := spotifyClient.Like(fixesTracks...); err != nil {
+				ch <- err
+				return
+			}
 		}
-		if err := routineFetchAlbums(albums, fetched); err != nil {
-			ch <- err
-			return
+
+		for _, fetch := range []func() error{
+			func() error { return routineFetchLibrary(library, libraryLimit, since(daemonLibrary), fetched) },
+			func() error { return routineFetchSavedAlbums(savedAlbums, fetched) },
+			func() error { return routineFetchNewReleases(newReleases, fetched) },
+			func() error { return routineFetchAlbums(albums, fetched) },
+			func() error { return routineFetchArtists(artists, artistTypes, fetched) },
+			func() error { return routineFetchTracks(tracks, fetched) },
+			func() error { return routineFetchPlaylists(append(playlists, playlistsTracks...), since, fetched) },
+			func() error { return routineFetchArchives(archives, archiveRetention, since, fetched) },
+		} {
+			// stop fetching further collections once interrupted
+			if ctx.Err() != nil {
+				return
+			}
+			if err := fetch(); err != nil {
+				ch <- err
+				return
+			}
 		}
-		if err := routineFetchTracks(tracks, fetched); err != nil {
-			ch <- err
-			return
+	}
+}
+
+// journaled tracks are put back into the stage they reached
+// during the interrupted synchronization they belong to: as
+// they are fetched, they do not get pruned either
+func routineFetchJournal(fetched chan interface{}) {
+	entries := journalData.Entries()
+	if len(entries) > 0 {
+		fetched <- "journal"
+	}
+	for _, entry := range entries {
+		var (
+			track = entry.Track
+			stage = entry.Stage
+		)
+		fetched <- track
+
+		// processing needs the blob and the artwork,
+		// collect them again if gone missing meanwhile
+		if stage == journal.Collected || stage == journal.Processed {
+			if _, err := os.Stat(track.Path().Download()); err != nil {
+				stage = journal.Decided
+			}
 		}
-		if err := routineFetchPlaylists(append(playlists, playlistsTracks...), fetched); err != nil {
-			ch <- err
-			return
+		if stage == journal.Collected {
+			if artwork, err := os.ReadFile(track.Path().Artwork()); err == nil || len(track.Artwork.URL) == 0 {
+				track.Artwork.Data = artwork
+			} else {
+				stage = journal.Decided
+			}
+		}
+
+		switch stage {
+		case journal.Decided:
+			indexData.Set(track, index.Online)
+			routineQueues[routineTypeCollect] <- track
+		case journal.Collected:
+			indexData.Set(track, index.Online)
+			routineQueues[routineTypeProcess] <- track
+		case journal.Processed:
+			indexData.Set(track, index.Online)
+			routineQueues[routineTypeInstall] <- track
+		case journal.Installed:
+			indexData.Set(track, index.Installed)
+			syncReport.set(track, reportInstalled)
 		}
 	}
 }
@@ -469,7 +802,7 @@ func routineFetchFixesIDs(fixes []string) ([]string, error) {
 	var localTracks []string
 	for _, path := range fixes {
 		tui.Lot("fetch").Printf("track %s", path)
-		tag, err := id3.Open(path, id3v2.Options{Parse: true})
+		tag, err := tag.Open(path, true)
 		if err != nil {
 			return nil, err
 		}
@@ -488,18 +821,167 @@ func routineFetchFixesIDs(fixes []string) ([]string, error) {
 	return localTracks, nil
 }
 
-func routineFetchLibrary(library bool, libraryLimit int, fetched chan interface{}) error {
+func routineFetchLibrary(library bool, libraryLimit int, since time.Time, fetched chan interface{}) error {
 	if !library {
 		return nil
 	}
 
-	tui.Lot("fetch").Printf("library")
-	return spotifyClient.Library(libraryLimit, routineQueues[routineTypeDecide], fetched)
+	if since.IsZero() {
+		tui.Lot("fetch").Printf("library")
+	} else {
+		tui.Lot("fetch").Printf("library since %s", since.Format(time.DateTime))
+	}
+	fetched <- "library"
+	return spotifyClient.Library(libraryLimit, since, routineQueues[routineTypeDecide], fetched)
+}
+
+func routineFetchSavedAlbums(savedAlbums bool, fetched chan interface{}) error {
+	if !savedAlbums {
+		return nil
+	}
+
+	tui.Lot("fetch").Printf("saved albums")
+	fetched <- "saved-albums"
+	return util.ErrOnly(spotifyClient.SavedAlbums(routineQueues[routineTypeDecide], fetched))
+}
+
+func routineFetchNewReleases(newReleases bool, fetched chan interface{}) error {
+	if !newReleases {
+		return nil
+	}
+
+	since, err := newReleasesWatermark()
+	if err != nil {
+		return err
+	}
+
+	tui.Lot("fetch").Printf("releases since %s", since.Format(time.DateOnly))
+	fetched <- "new-releases"
+	return util.ErrOnly(spotifyClient.NewReleases(since, routineQueues[routineTypeDecide], fetched))
+}
+
+// newReleasesWatermark returns when the releases of the followed artists
+// were last checked: the first time, the latest month gets looked into
+func newReleasesWatermark() (time.Time, error) {
+	data, err := os.ReadFile(util.ProfileFile(newReleasesBasename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Now().Add(-newReleasesLookback), nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	var watermark watermark
+	if err := json.Unmarshal(data, &watermark); err != nil {
+		return time.Time{}, err
+	}
+	return watermark.Checked, nil
+}
+
+// newReleasesPersist takes note of the releases of
+// the followed artists being checked at the given time
+func newReleasesPersist(checked time.Time) error {
+	data, err := json.Marshal(watermark{checked})
+	if err != nil {
+		return err
+	}
+
+	path := util.ProfileFile(newReleasesBasename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// syncSince returns, by collection, the time from which on the tracks added to it
+// get synchronized, as the given value tells: either a date, a duration back from
+// the given time or the last complete synchronization of the collection
+func syncSince(value string, now time.Time) (func(collection string) time.Time, error) {
+	switch value {
+	case "":
+		return func(string) time.Time { return time.Time{} }, nil
+	case sinceLastRun:
+		lastRun, err := lastRunLoad()
+		if err != nil {
+			return nil, err
+		}
+		return func(collection string) time.Time { return lastRun[collection] }, nil
+	}
+
+	since, err := sinceParse(value, now)
+	if err != nil {
+		return nil, err
+	}
+	return func(string) time.Time { return since }, nil
+}
+
+// sinceParse returns the time the given date,
+// or duration back from the given time, stands for
+func sinceParse(value string, now time.Time) (time.Time, error) {
+	// durations in days are not known to time
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if days, err := strconv.Atoi(days); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.DateOnly, time.DateTime, time.RFC3339} {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("unsupported since: " + value)
+}
+
+// lastRunLoad returns when each collection got
+// last synchronized completely, by collection
+func lastRunLoad() (map[string]time.Time, error) {
+	lastRun := make(map[string]time.Time)
+	data, err := os.ReadFile(util.ProfileFile(lastRunBasename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return lastRun, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &lastRun); err != nil {
+		return nil, err
+	}
+	return lastRun, nil
+}
+
+// lastRunPersist takes note of the given collections
+// being synchronized completely at the given time
+func lastRunPersist(collections []string, synchronized time.Time) error {
+	if len(collections) == 0 {
+		return nil
+	}
+
+	lastRun, err := lastRunLoad()
+	if err != nil {
+		return err
+	}
+	for _, collection := range collections {
+		lastRun[collection] = synchronized
+	}
+
+	data, err := json.Marshal(lastRun)
+	if err != nil {
+		return err
+	}
+
+	path := util.ProfileFile(lastRunBasename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
 }
 
 func routineFetchAlbums(albums []string, fetched chan interface{}) error {
 	for _, id := range albums {
 		tui.Lot("fetch").Printf("album %s", id)
+		fetched <- "album:" + id
 		if _, err := spotifyClient.Album(id, routineQueues[routineTypeDecide], fetched); err != nil {
 			return err
 		}
@@ -507,9 +989,21 @@ func routineFetchAlbums(albums []string, fetched chan interface{}) error {
 	return nil
 }
 
+func routineFetchArtists(artists, types []string, fetched chan interface{}) error {
+	for _, id := range artists {
+		tui.Lot("fetch").Printf("artist %s", id)
+		fetched <- "artist:" + id
+		if _, err := spotifyClient.Artist(id, types, routineQueues[routineTypeDecide], fetched); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func routineFetchTracks(tracks []string, fetched chan interface{}) error {
 	for _, id := range tracks {
 		tui.Lot("fetch").Printf("track %s", id)
+		fetched <- "track:" + id
 		if _, err := spotifyClient.Track(id, routineQueues[routineTypeDecide], fetched); err != nil {
 			return err
 		}
@@ -517,10 +1011,11 @@ func routineFetchTracks(tracks []string, fetched chan interface{}) error {
 	return nil
 }
 
-func routineFetchPlaylists(playlists []string, fetched chan interface{}) error {
+func routineFetchPlaylists(playlists []string, since func(collection string) time.Time, fetched chan interface{}) error {
 	for index, id := range playlists {
 		tui.Lot("fetch").Printf("playlist %s", id)
-		playlist, err := spotifyClient.Playlist(id, routineQueues[routineTypeDecide], fetched)
+		fetched <- "playlist:" + id
+		playlist, err := routineFetchPlaylist(id, since("playlist:"+id), fetched)
 		if err != nil {
 			return err
 		}
@@ -531,34 +1026,210 @@ func routineFetchPlaylists(playlists []string, fetched chan interface{}) error {
 	return nil
 }
 
+// routineFetchArchives fetches the given rotating playlists, each one mixed
+// both as it is and as the snapshot of the current week, and removes those
+// snapshots of theirs which fell out of the retention period, if any
+func routineFetchArchives(archives []string, retention int, since func(collection string) time.Time, fetched chan interface{}) error {
+	now := time.Now()
+	for _, id := range archives {
+		tui.Lot("fetch").Printf("playlist %s", id)
+		fetched <- "playlist:" + id
+		playlist, err := routineFetchPlaylist(id, since("playlist:"+id), fetched)
+		if err != nil {
+			return err
+		}
+		routineQueues[routineTypeMix] <- playlist
+		routineQueues[routineTypeMix] <- playlist.Archive(now)
+
+		// dry runs leave snapshots untouched
+		expired, err := playlist.Expired(".", now, retention)
+		if err != nil || syncPlan != nil {
+			return err
+		}
+		for _, path := range expired {
+			tui.Printf("archive %s expired", path)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// routineFetchPlaylist fetches the given playlist, unless its snapshot did not
+// change since the last synchronization and those of its tracks which are
+// indexed are still on disk: in that case, its cached tracks are used instead.
+// Either way, only the tracks added at the given time or later get synchronized
+func routineFetchPlaylist(id string, since time.Time, fetched chan interface{}) (*playlist.Playlist, error) {
+	cachePath := util.ProfileFile(filepath.Join(playlist.CacheDirname, url.PathEscape(id)+".json"))
+	if cached, err := playlist.Load(cachePath); err != nil {
+		tui.Printf("playlist %s cache unreadable, fetching: %s", id, err)
+	} else if cached != nil && len(cached.SnapshotID) > 0 {
+		snapshot, err := spotifyClient.PlaylistSnapshot(id)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot == cached.SnapshotID && routineFetchPlaylistOnDisk(cached) {
+			tui.Lot("fetch").Printf("playlist %s unchanged", id)
+			for _, track := range cached.Tracks {
+				if track.AddedAt.Before(since) {
+					continue
+				}
+				routineQueues[routineTypeDecide] <- track
+				fetched <- track
+			}
+			return cached, nil
+		}
+	}
+
+	fresh, err := spotifyClient.Playlist(id, since, routineQueues[routineTypeDecide], fetched)
+	if err != nil {
+		return nil, err
+	}
+	// dry runs leave no trace behind
+	if syncPlan == nil && len(fresh.SnapshotID) > 0 {
+		util.ErrSuppress(fresh.Persist(cachePath))
+	}
+	return fresh, nil
+}
+
+// routineFetchPlaylistOnDisk tells whether every track of the given
+// playlist the index knows the file of is still there
+func routineFetchPlaylistOnDisk(playlist *playlist.Playlist) bool {
+	for _, track := range playlist.Tracks {
+		if entry, ok := indexData.Entry(track); ok && len(entry.Path) > 0 {
+			if _, err := os.Stat(entry.Path); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// syncPrune removes (or moves into the archive path, if any) every track file
+// on disk whose Spotify ID has not been fetched during the synchronization:
+// all of them get listed first and nothing is touched if they are too many
+func syncPrune(synced map[string]bool, archive string, limit int, dryRun bool) error {
+	archived, err := syncArchived()
+	if err != nil {
+		return err
+	}
+
+	var (
+		stale = make(map[string]string) // relative to the output (working) directory
+		paths []string
+		wd    = util.ErrWrap("")(os.Getwd())
+	)
+	for _, entry := range indexData.Tagged() {
+		if !synced[entry.ID] && !archived[entry.Path] {
+			path := util.ErrWrap(entry.Path)(filepath.Rel(wd, entry.Path))
+			stale[path] = entry.Path
+			paths = append(paths, path)
+			syncPlan.addPrune(path)
+			tui.Printf("prune %s", path)
+		}
+	}
+
+	if dryRun || len(paths) == 0 {
+		return nil
+	}
+	if limit > 0 && len(paths) > limit {
+		return fmt.Errorf("refusing to prune %d tracks, more than %d", len(paths), limit)
+	}
+
+	// a track which cannot be pruned does not stop the others
+	var errs []error
+	for _, path := range paths {
+		if len(archive) > 0 {
+			if err := util.FileMoveOrCopy(path, filepath.Join(archive, path), true); err != nil {
+				tui.AnchorPrintf("failed to prune %s: %s", path, err)
+				errs = append(errs, err)
+				continue
+			}
+		} else if err := os.Remove(path); err != nil {
+			tui.AnchorPrintf("failed to prune %s: %s", path, err)
+			errs = append(errs, err)
+			continue
+		}
+		indexData.Remove(stale[path])
+		reorganizePrune(filepath.Dir(path))
+	}
+	tui.Printf("%d tracks pruned", len(paths)-len(errs))
+	if len(errs) > 0 {
+		return fmt.Errorf("%d tracks could not be pruned: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// syncArchived returns the absolute paths of the tracks the archived snapshots
+// of rotating playlists point to: although those dropped off the playlists,
+// they are kept for as long as any of their snapshots is
+func syncArchived() (map[string]bool, error) {
+	snapshots, err := playlist.Archives(".")
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		entries, err := playlist.Entries(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			archived[util.ErrWrap(entry)(filepath.Abs(entry))] = true
+		}
+	}
+	return archived, nil
+}
+
 // decider finds the right asset to retrieve
 // for a given track
 func routineDecide(manualMode bool, outputDir string) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
+	return func(ctx context.Context, ch chan error) {
 		// remember to stop passing data to the collector
 		// the retriever, the composer and the painter
 		defer close(routineQueues[routineTypeCollect])
 
 		for event := range routineQueues[routineTypeDecide] {
-			track := event.(*entity.Track)
+			// once interrupted, only drain the queue
+			if ctx.Err() != nil {
+				continue
+			}
+
+			var (
+				track = event.(*entity.Track)
+				start = time.Now()
+			)
 
 			// First check if we already have this track by Spotify ID
 			if _, err := os.Stat(track.Path().Final()); err == nil {
-				tag, err := id3.Open(track.Path().Final(), id3v2.Options{Parse: true})
+				tag, err := tag.Open(track.Path().Final(), true)
 				if err == nil {
 					defer tag.Close()
 					if existingID := tag.SpotifyID(); existingID == track.ID {
 						tui.Printf("track %s already exists with matching ID: %s", track.Path().Final(), existingID)
 						indexData.Set(track, index.Installed)
+						syncPlan.add(planInstalled, track)
+						syncReport.set(track, reportSkipped)
 						continue
 					} else if existingID != track.ID {
 						tui.Printf("track %s has a different ID (%s) that the one in the playlist (%s). Updating ID...", track.Path().Final(), existingID, track.ID)
+						if syncPlan != nil {
+							syncPlan.add(planRetag, track)
+							continue
+						}
 						tag.SetSpotifyID(track.ID)
-						if err := tag.Save(); err != nil {
+						if err := tag.Save(ctx); err != nil {
 							tui.AnchorPrintf("failed to update tags: %s", err)
+							syncReport.set(track, reportFailed, err)
 							tag.Close()
 							continue
 						}
+						// the file is now indexed under the new ID,
+						// which pruning must not take for a stale one
+						indexData.Set(track, index.Installed)
+						syncReport.set(track, reportSkipped)
 						continue
 					}
 				}
@@ -571,9 +1242,12 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 				indexData.Set(track, index.Online)
 			} else if status == index.Online {
 				tui.Printf("skip %s by %s", track.Title, track.Artists[0])
+				syncPlan.add(planDuplicate, track)
 				continue
 			} else if status == index.Offline {
 				tui.Printf("missing %s by %s", track.Title, track.Artists[0])
+				syncPlan.add(planIndexed, track)
+				syncReport.set(track, reportMissing)
 				continue
 			}
 
@@ -627,7 +1301,7 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 					// Display numbered list of options
 					tui.Printf("Found %d potential matches:", len(matches))
 					for i, match := range matches {
-						tui.Printf("%d. %s", i+1, filepath.Base(match))
+						tui.Printf("%d. %s", i+1, util.ErrWrap(match)(filepath.Rel(outputDir, match)))
 					}
 
 					// Let user select a file (with 1 as default)
@@ -657,13 +1331,15 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 
 					selectedFile := matches[selectionNum-1]
 
-					// Update the file: rename it to match the expected format
-					expectedPath := filepath.Join(filepath.Dir(selectedFile), track.Path().Final())
+					// Update the file: move it to where the track would be
+					// installed, i.e. relative to the output (working) directory
+					expectedPath := track.Path().Final()
 
-					tui.Printf("Renaming file from: %s to: %s", filepath.Base(selectedFile), filepath.Base(expectedPath))
+					tui.Printf("Renaming file from: %s to: %s",
+						util.ErrWrap(selectedFile)(filepath.Rel(outputDir, selectedFile)), expectedPath)
 
 					// Check and update tags first
-					tag, err := id3.Open(selectedFile, id3v2.Options{Parse: true})
+					tag, err := tag.Open(selectedFile, true)
 					if err != nil {
 						tui.AnchorPrintf("failed to open selected file: %s", err)
 						continue
@@ -673,13 +1349,15 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 					tag.SetSpotifyID(track.ID)
 					tag.SetTitle(track.Title)
 					tag.SetArtist(track.Artists[0])
+					tag.SetArtists(track.Artists)
 					tag.SetAlbum(track.Album)
 					tag.SetArtworkURL(track.Artwork.URL)
 					tag.SetDuration(strconv.Itoa(track.Duration))
 					tag.SetTrackNumber(strconv.Itoa(track.Number))
+					tag.SetDiscNumber(strconv.Itoa(track.Disc))
 					tag.SetYear(strconv.Itoa(track.Year))
 
-					if err := tag.Save(); err != nil {
+					if err := tag.Save(ctx); err != nil {
 						tui.AnchorPrintf("failed to update tags: %s", err)
 						tag.Close()
 						continue
@@ -695,6 +1373,7 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 
 					// Update index
 					indexData.Set(track, index.Installed)
+					syncReport.set(track, reportInstalled)
 					tui.Printf("File successfully renamed and tagged")
 					continue
 
@@ -705,8 +1384,9 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 
 			} else {
 				tui.Lot("decide").Printf("%s by %s", track.Title, track.Artists[0])
-				matches, err := provider.Search(track)
+				matches, err := provider.Search(ctx, track)
 				tui.Lot("decide").Wipe()
+				syncReport.elapsed(track, "decide", start)
 				if err != nil {
 					ch <- err
 					return
@@ -714,9 +1394,21 @@ func routineDecide(manualMode bool, outputDir string) func(context.Context, chan
 
 				if len(matches) == 0 {
 					tui.AnchorPrintf("%s by %s (id: %s) not found", track.Title, track.Artists[0], track.ID)
+					syncPlan.add(planNotFound, track)
+					syncReport.set(track, reportNotFound)
 					continue
 				}
 				track.UpstreamURL = matches[0].URL
+				track.Matches = matches
+			}
+			// on dry runs, tracks go no further
+			if syncPlan != nil {
+				syncPlan.add(planDownload, track)
+				continue
+			}
+			if err := journalData.Set(track, journal.Decided); err != nil {
+				ch <- err
+				return
 			}
 			routineQueues[routineTypeCollect] <- track
 		}
@@ -788,7 +1480,7 @@ func fuzzySearchLocalFiles(dir string, track *entity.Track) ([]string, error) {
 	// Walk through the directory
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		// Skip directories and non-music files
-		if err != nil || info.IsDir() || !strings.HasSuffix(strings.ToLower(path), entity.TrackFormat) {
+		if err != nil || info.IsDir() || !entity.IsTrackFile(path) {
 			return nil
 		}
 
@@ -872,31 +1564,47 @@ func fuzzySearchLocalFiles(dir string, track *entity.Track) ([]string, error) {
 // collector fetches all the needed assets
 // for a blob to be processed (basically
 // a wrapper around: retriever, composer and painter)
-func routineCollect(lyrics bool) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
+func routineCollect(lyrics bool, fallbackDepth int, verifier processor.Verifier) func(context.Context, chan error) {
+	return func(ctx context.Context, ch chan error) {
 		// remember to stop passing data to installer
 		defer close(routineQueues[routineTypeProcess])
 
 		for event := range routineQueues[routineTypeCollect] {
+			// once interrupted, only drain the queue
+			if ctx.Err() != nil {
+				continue
+			}
+
 			track := event.(*entity.Track)
+			// on dry runs, only journaled tracks get here
+			if syncPlan != nil {
+				syncPlan.add(planResume, track)
+				continue
+			}
+			start := time.Now()
+			jobs := []nursery.ConcurrentJob{
+				routineCollectAsset(track, fallbackDepth, verifier),
+				routineCollectArtwork(track),
+			}
 			if lyrics {
-				if err := nursery.RunConcurrently(
-					routineCollectAsset(track),
-					routineCollectLyrics(track),
-					routineCollectArtwork(track),
-				); err != nil {
-					tui.Printf("failure in routineCollect")
-					ch <- err
-					return
-				}
-			} else {
-				if err := nursery.RunConcurrently(
-					routineCollectAsset(track),
-					routineCollectArtwork(track),
-				); err != nil {
-					ch <- err
-					return
-				}
+				jobs = append(jobs, routineCollectLyrics(track))
+			}
+			err := nursery.RunConcurrentlyWithContext(ctx, jobs...)
+			syncReport.elapsed(track, "collect", start)
+			if errors.Is(err, errMatchesFailed) {
+				// a track which cannot be downloaded
+				// is no reason to stop the others
+				tui.AnchorPrintf("%s by %s (id: %s) failed: %s", track.Title, track.Artists[0], track.ID, err)
+				syncReport.set(track, reportFailed, err)
+				continue
+			} else if err != nil {
+				syncReport.set(track, reportFailed, err)
+				ch <- err
+				return
+			}
+			if err := journalData.Set(track, journal.Collected); err != nil {
+				ch <- err
+				return
 			}
 			routineQueues[routineTypeProcess] <- track
 		}
@@ -907,26 +1615,50 @@ func routineCollect(lyrics bool) func(context.Context, chan error) {
 }
 
 // retriever pulls a track blob corresponding
-// to the (meta)data fetched from upstream
-func routineCollectAsset(track *entity.Track) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
-		tui.Lot("download").Print(track.UpstreamURL)
-		if err := downloader.Download(track.UpstreamURL, track.Path().Download(), nil); err != nil {
-			tui.AnchorPrintf("download failure: %s", err)
-			ch <- err
-			return
+// to the (meta)data fetched from upstream,
+// falling back to the next match if either
+// its download or its verification fails
+func routineCollectAsset(track *entity.Track, fallbackDepth int, verifier processor.Verifier) func(context.Context, chan error) {
+	return func(ctx context.Context, ch chan error) {
+		matches := track.Matches
+		if len(matches) == 0 {
+			matches = []*entity.Match{{URL: track.UpstreamURL}}
+		}
+
+		var err error
+		for depth, match := range matches {
+			if depth > 0 && depth >= fallbackDepth {
+				break
+			}
+
+			track.UpstreamURL = match.URL
+			tui.Lot("download").Print(track.UpstreamURL)
+			if err = downloader.Download(ctx, track.UpstreamURL, track.Path().Download(), nil); err == nil {
+				if err = verifier.Do(ctx, track); err == nil {
+					tui.Printf("asset for %s by %s: %s", track.Title, track.Artists[0], track.UpstreamURL)
+					tui.Lot("download").Wipe()
+					return
+				}
+				// a blob not matching the track is of no use
+				util.ErrSuppress(os.Remove(track.Path().Download()))
+			}
+			if ctx.Err() != nil {
+				ch <- err
+				return
+			}
+			tui.AnchorPrintf("download failure for %s by %s: %s", track.Title, track.Artists[0], err)
 		}
-		tui.Printf("asset for %s by %s: %s", track.Title, track.Artists[0], track.UpstreamURL)
 		tui.Lot("download").Wipe()
+		ch <- fmt.Errorf("%w: %s", errMatchesFailed, err)
 	}
 }
 
 // composer pulls lyrics to be inserted
 // in the fetched blob
 func routineCollectLyrics(track *entity.Track) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
+	return func(ctx context.Context, ch chan error) {
 		tui.Lot("compose").Printf("%s by %s", track.Title, track.Artists[0])
-		lyrics, err := lyrics.Search(track)
+		lyrics, err := lyrics.Search(ctx, track)
 		if err != nil {
 			tui.AnchorPrintf("compose failure: %s", err)
 			ch <- err
@@ -941,12 +1673,12 @@ func routineCollectLyrics(track *entity.Track) func(context.Context, chan error)
 // painter pulls image blobs to be inserted
 // as artworks in the fetched blob
 func routineCollectArtwork(track *entity.Track) func(context.Context, chan error) {
-	return func(_ context.Context, ch chan error) {
+	return func(ctx context.Context, ch chan error) {
 		artwork := make(chan []byte, 1)
 		defer close(artwork)
 
 		tui.Lot("paint").Printf("%s by %s", track.Title, track.Artists[0])
-		if err := downloader.Download(track.Artwork.URL, track.Path().Artwork(), processor.Artwork{}, artwork); err != nil {
+		if err := downloader.Download(ctx, track.Artwork.URL, track.Path().Artwork(), processor.Artwork{}, artwork); err != nil {
 			tui.AnchorPrintf("compose failure: %s", err)
 			ch <- err
 			return
@@ -961,19 +1693,36 @@ func routineCollectArtwork(track *entity.Track) func(context.Context, chan error
 // postprocessor applies some further enhancements
 // e.g. combining the downloaded artwork/lyrics
 // into the blob
-func routineProcess(_ context.Context, ch chan error) {
+func routineProcess(ctx context.Context, ch chan error) {
 	// remember to stop passing data to installer
 	defer close(routineQueues[routineTypeInstall])
 
 	for event := range routineQueues[routineTypeProcess] {
+		// once interrupted, only drain the queue
+		if ctx.Err() != nil {
+			continue
+		}
+
 		track := event.(*entity.Track)
+		if syncPlan != nil {
+			syncPlan.add(planResume, track)
+			continue
+		}
 		tui.Lot("process").Printf("%s by %s", track.Title, track.Artists[0])
-		if err := processor.Do(track); err != nil {
+		start := time.Now()
+		err := processor.Do(ctx, track)
+		syncReport.elapsed(track, "process", start)
+		if err != nil {
 			tui.AnchorPrintf("processing failed for %s by %s: %s", track.Title, track.Artists[0], err)
+			syncReport.set(track, reportFailed, err)
 			ch <- err
 			return
 		}
 		tui.Lot("process").Wipe()
+		if err := journalData.Set(track, journal.Processed); err != nil {
+			ch <- err
+			return
+		}
 		routineQueues[routineTypeInstall] <- track
 	}
 	tui.Lot("process").Close()
@@ -989,14 +1738,27 @@ func routineInstall(_ context.Context, ch chan error) {
 			track     = event.(*entity.Track)
 			status, _ = indexData.Get(track)
 		)
+		if syncPlan != nil {
+			syncPlan.add(planResume, track)
+			continue
+		}
 		tui.Lot("install").Printf("%s by %s ", track.Title, track.Artists[0])
-		if err := util.FileMoveOrCopy(track.Path().Download(), track.Path().Final(), status == index.Flush); err != nil {
+		start := time.Now()
+		err := util.FileMoveOrCopy(track.Path().Download(), track.Path().Final(), status == index.Flush)
+		syncReport.elapsed(track, "install", start)
+		if err != nil {
 			tui.AnchorPrintf("installation failed for %s by %s: %s", track.Title, track.Artists[0], err)
+			syncReport.set(track, reportFailed, err)
 			ch <- err
 			return
 		}
 		tui.Lot("install").Wipe()
 		indexData.Set(track, index.Installed)
+		syncReport.set(track, reportInstalled)
+		if err := journalData.Set(track, journal.Installed); err != nil {
+			ch <- err
+			return
+		}
 	}
 	tui.Lot("install").Close(strconv.Itoa(indexData.Size(index.Installed)) + " tracks")
 }
@@ -1019,10 +1781,13 @@ func routineMix(encoding string) func(context.Context, chan error) {
 				return
 			}
 
+			entries := 0
 			for _, track := range playlist.Tracks {
-				if trackStatus, ok := indexData.Get(track); !ok || (trackStatus != index.Installed && trackStatus != index.Offline) {
+				if trackStatus, ok := indexData.Get(track); !syncPlan.installed(track) &&
+					(!ok || (trackStatus != index.Installed && trackStatus != index.Offline)) {
 					continue
 				}
+				entries++
 
 				if err := encoder.Add(track); err != nil {
 					tui.AnchorPrintf("adding track to %s failed: %s", playlist.Name, err)
@@ -1032,11 +1797,17 @@ func routineMix(encoding string) func(context.Context, chan error) {
 				}
 			}
 
+			// on dry runs, playlists are not written
+			if syncPlan != nil {
+				syncPlan.addPlaylist(playlist.Name, encoder.Target(), entries)
+				continue
+			}
 			if err := encoder.Close(); err != nil {
 				tui.AnchorPrintf("closing playlist %s failed: %s", playlist.Name, err)
 				ch <- err
 				return
 			}
+			syncReport.mix(playlist, encoder.Target())
 		}
 		tui.Lot("mix").Close(fmt.Sprintf("%d playlists", counter))
 	}
@@ -1,25 +1,37 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/bogem/id3v2/v2"
+	"github.com/spf13/cobra"
 	"github.com/streambinder/spotitube/downloader"
 	"github.com/streambinder/spotitube/entity"
 	"github.com/streambinder/spotitube/entity/id3"
 	"github.com/streambinder/spotitube/entity/index"
+	"github.com/streambinder/spotitube/entity/journal"
 	"github.com/streambinder/spotitube/entity/playlist"
 	"github.com/streambinder/spotitube/lyrics"
 	"github.com/streambinder/spotitube/processor"
 	"github.com/streambinder/spotitube/provider"
 	"github.com/streambinder/spotitube/spotify"
 	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/anchor"
 	"github.com/streambinder/spotitube/util/cmd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkSync(b *testing.B) {
@@ -30,6 +42,7 @@ func BenchmarkSync(b *testing.B) {
 
 func cleanup() {
 	indexData = index.New()
+	syncPlan = nil
 }
 
 func TestCmdSync(t *testing.T) {
@@ -52,14 +65,14 @@ func TestCmdSync(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			for _, c := range ch {
 				c <- _track
 				c <- _track // to trigger duplicate check
 			}
 			return nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, ch ...chan interface{}) (*playlist.Playlist, error) {
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
 			ch[0] <- _track
 			ch[0] <- _trackNotFound // to skip inclusion in playlist
 			return _playlist, nil
@@ -81,13 +94,16 @@ func TestCmdSync(t *testing.T) {
 		ApplyMethod(&id3.Tag{}, "Close", func() error {
 			return nil
 		}).
-		ApplyFunc(provider.Search, func(track *entity.Track) ([]*provider.Match, error) {
+		ApplyFunc(provider.Search, func(_ context.Context, track *entity.Track) ([]*provider.Match, error) {
 			if track.ID == _trackNotFound.ID {
 				return []*provider.Match{}, nil
 			}
 			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			for _, c := range ch {
 				c <- []byte{}
 			}
@@ -113,7 +129,15 @@ func TestCmdSync(t *testing.T) {
 	library, err := cmd.Flags().GetBool("library")
 	assert.Nil(t, err)
 	assert.True(t, library)
-	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "-l", "-p", "123", "-a", "123", "-t", "123", "-f", "path")))
+	assert.EqualError(t, testExecute(cmdSync(), "-l", "-p", "123", "-a", "123", "-t", "123", "-f", "path"), "synchronization partially complete: 1 tracks not synchronized")
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123", "--format", "opus")))
+	assert.Equal(t, "opus", entity.TrackFormat)
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123")))
+	assert.Equal(t, "mp3", entity.TrackFormat)
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123", "--path-template", "{{.Album}}/{{.Title}}")))
+	assert.NotNil(t, entity.PathTemplate)
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123")))
+	assert.Nil(t, entity.PathTemplate)
 }
 
 func TestCmdSyncOfflineIndex(t *testing.T) {
@@ -132,14 +156,17 @@ func TestCmdSyncOfflineIndex(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
 			ch[0] <- _track
 			return nil
 		}).
 		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
 			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			for _, c := range ch {
 				c <- []byte{}
 			}
@@ -164,112 +191,291 @@ func TestCmdSyncOfflineIndex(t *testing.T) {
 	assert.Nil(t, util.ErrOnly(testExecute(cmd)))
 }
 
-func TestCmdSyncPathFailure(t *testing.T) {
+// testSyncPrune synchronizes the library, made of a single track, against
+// an output path holding both the very same track and another, stale one
+func testSyncPrune(t *testing.T, args ...string) (string, error) {
 	t.Cleanup(cleanup)
 
-	// monkey patching
-	defer gomonkey.ApplyFunc(os.Chdir, func() error {
-		return errors.New("ko")
-	}).Reset()
-
-	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
-}
-
-func TestCmdSyncIndexFailure(t *testing.T) {
-	t.Cleanup(cleanup)
+	var (
+		_track = &entity.Track{ID: "TestCmdSyncPrune", Title: "Title", Artists: []string{"Artist"}}
+		output = t.TempDir()
+		wd     = util.ErrWrap("")(os.Getwd())
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Title.opus"), []byte(_track.ID), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Stale.opus"), []byte("stale"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Untagged.opus"), []byte{}, 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Archived.opus"), []byte("archived"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "playlist-2026-w01.m3u"), []byte("Artist - Archived.opus\n"), 0o644))
 
 	// monkey patching
 	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: map[string]string{
+				"spotify_id": string(util.ErrWrap([]byte{})(os.ReadFile(path))),
+			}}, nil
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
-			return errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			for _, c := range ch {
+				c <- _track
+			}
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
+			return nil, nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	return output, testExecute(cmdSync(), append([]string{"-o", output, "--format", "opus", "--prune"}, args...)...)
 }
 
-func TestCmdSyncAuthFailure(t *testing.T) {
+func TestCmdSyncPrune(t *testing.T) {
+	output, err := testSyncPrune(t)
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(output, "Artist - Title.opus"))
+	assert.FileExists(t, filepath.Join(output, "Artist - Untagged.opus"))
+	assert.FileExists(t, filepath.Join(output, "Artist - Archived.opus"))
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneArchive(t *testing.T) {
+	archive := t.TempDir()
+	output, err := testSyncPrune(t, "--prune-archive", archive)
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(output, "Artist - Title.opus"))
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+	assert.FileExists(t, filepath.Join(archive, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneDryRun(t *testing.T) {
+	output, err := testSyncPrune(t, "--prune-dry-run")
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneLimit(t *testing.T) {
+	output, err := testSyncPrune(t, "--prune-limit", "0")
+	assert.Nil(t, err)
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneResume(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_track = &entity.Track{ID: "TestCmdSyncPruneResume", Title: "Resumed", Artists: []string{"Artist"}}
+		output = t.TempDir()
+		wd     = util.ErrWrap("")(os.Getwd())
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Resumed.opus"), []byte(_track.ID), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Stale.opus"), []byte("stale"), 0o644))
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Load", func() error {
 			return nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return nil
+		}).
+		ApplyMethod(&journal.Journal{}, "Load", func() error {
 			return nil
 		}).
+		ApplyMethod(&journal.Journal{}, "Entries", func() []*journal.Entry {
+			return []*journal.Entry{{Track: _track, Stage: journal.Installed}}
+		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: map[string]string{
+				"spotify_id": string(util.ErrWrap([]byte{})(os.ReadFile(path))),
+			}}, nil
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
-			return &spotify.Client{}, errors.New("ko")
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func() error {
+			return nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "--format", "opus", "--resume", "--prune"))
+	assert.FileExists(t, filepath.Join(output, "Artist - Resumed.opus"))
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Stale.opus"))
 }
 
-func TestCmdSyncLibraryFailure(t *testing.T) {
+func TestCmdSyncPruneLimitExceeded(t *testing.T) {
+	// nothing gets fetched, hence every tagged track is stale
+	output, err := testSyncPrune(t, "--prune-limit", "1", "-t", "123")
+	assert.EqualError(t, err, "refusing to prune 2 tracks, more than 1")
+	assert.FileExists(t, filepath.Join(output, "Artist - Title.opus"))
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.Remove, func(path string) error {
+		if path == "Artist - Stale.opus" {
+			return errors.New("ko")
+		}
+		return syscall.Unlink(path)
+	}).Reset()
+
+	// testing: nothing gets fetched, hence every tagged track is stale
+	output, err := testSyncPrune(t, "-t", "123")
+	assert.EqualError(t, err, "1 tracks could not be pruned: ko")
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Title.opus"))
+}
+
+func TestCmdSyncPruneRetag(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_track = &entity.Track{ID: "TestCmdSyncPruneRetag", Title: "Retagged", Artists: []string{"Artist"}}
+		output = t.TempDir()
+		wd     = util.ErrWrap("")(os.Getwd())
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Retagged.opus"), []byte("TestCmdSyncPruneOld"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Stale.opus"), []byte("stale"), 0o644))
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Load", func() error {
 			return nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
+		ApplyMethod(&index.Index{}, "Persist", func() error {
 			return nil
 		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: map[string]string{
+				"spotify_id": string(util.ErrWrap([]byte{})(os.ReadFile(path))),
+			}}, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, path string, fields map[string]string, _ string) error {
+			return os.WriteFile(path, []byte(fields["spotify_id"]), 0o644)
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func() error {
-			return errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			for _, c := range ch {
+				c <- _track
+			}
+			return nil
 		}).
 		Reset()
 
-	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	// testing: the file is retagged, hence not stale anymore
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "--format", "opus", "--prune"))
+	assert.Equal(t, _track.ID, string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(output, "Artist - Retagged.opus")))))
+	assert.NoFileExists(t, filepath.Join(output, "Artist - Stale.opus"))
 }
 
-func TestCmdSyncPlaylistFailure(t *testing.T) {
+func TestCmdSyncRetagFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_track = &entity.Track{ID: "TestCmdSyncRetagFailure", Title: "Retagged", Artists: []string{"Artist"}}
+		output = t.TempDir()
+		wd     = util.ErrWrap("")(os.Getwd())
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Retagged.opus"), []byte("TestCmdSyncRetagOld"), 0o644))
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Load", func() error {
 			return nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
+		ApplyMethod(&index.Index{}, "Persist", func() error {
 			return nil
 		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: map[string]string{
+				"spotify_id": string(util.ErrWrap([]byte{})(os.ReadFile(path))),
+			}}, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func() error {
+			return errors.New("ko")
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
-			return nil, errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			for _, c := range ch {
+				c <- _track
+			}
+			return nil
 		}).
 		Reset()
 
+	// testing: dry runs only plan retagging, failures leave the file as it is
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "--format", "opus", "--dry-run"))
+	require.Len(t, syncPlan.Tracks, 1)
+	assert.Equal(t, planRetag, syncPlan.Tracks[0].Action)
+	cleanup()
+	assert.EqualError(t, testExecute(cmdSync(), "-o", output, "--format", "opus"), "synchronization partially complete: 1 tracks not synchronized")
+	assert.Equal(t, "TestCmdSyncRetagOld", string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(output, "Artist - Retagged.opus")))))
+}
+
+func TestCmdSyncPruneArchivedFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(playlist.Entries, func() ([]string, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+	output, err := testSyncPrune(t)
+	assert.EqualError(t, err, "ko")
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
 }
 
-func TestCmdSyncAlbumFailure(t *testing.T) {
+func TestCmdSyncArchive(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		output  = t.TempDir()
+		cache   = t.TempDir()
+		wd      = util.ErrWrap("")(os.Getwd())
+		expired = fmt.Sprintf("playlist-%d-w01.m3u", time.Now().Year()-1)
+		other   = fmt.Sprintf("other-%d-w01.m3u", time.Now().Year()-1)
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	for _, name := range []string{expired, other} {
+		assert.Nil(t, os.WriteFile(filepath.Join(output, name), []byte{}, 0o644))
+	}
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
 		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
@@ -277,23 +483,49 @@ func TestCmdSyncAlbumFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Album", func() (*entity.Album, error) {
-			return nil, errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{Name: "Playlist", Tracks: []*entity.Track{}}, nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-a", "123")), "ko")
+	year, week := time.Now().ISOWeek()
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "--archive", "123", "--archive-retention", "1", "--dry-run"))
+	assert.NoFileExists(t, filepath.Join(output, fmt.Sprintf("playlist-%d-w%02d.m3u", year, week)))
+	assert.FileExists(t, filepath.Join(output, expired))
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "--archive", "123", "--archive-retention", "1"))
+	assert.FileExists(t, filepath.Join(output, "playlist.m3u"))
+	assert.FileExists(t, filepath.Join(output, fmt.Sprintf("playlist-%d-w%02d.m3u", year, week)))
+	assert.NoFileExists(t, filepath.Join(output, expired))
+	assert.FileExists(t, filepath.Join(output, other))
 }
 
-func TestCmdSyncTrackFailure(t *testing.T) {
+func TestCmdSyncArchiveFailure(t *testing.T) {
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--archive", "123", "--archive-retention", "-1"), "archive retention cannot be negative")
+}
+
+func TestCmdSyncPlaylistCached(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_track    = &entity.Track{ID: "TestCmdSyncPlaylistCached", Title: "Title", Artists: []string{"Artist"}}
+		_playlist = &playlist.Playlist{ID: "123", Name: "Playlist", SnapshotID: "snapshot", Tracks: []*entity.Track{_track}}
+		output    = t.TempDir()
+		cache     = t.TempDir()
+		cachePath = filepath.Join(cache, playlist.CacheDirname, "123.json")
+		wd        = util.ErrWrap("")(os.Getwd())
+		fetches   int
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, _playlist.Persist(cachePath))
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyFunc(util.CacheFile, func(filename string) string {
+			return filepath.Join(cache, filename)
 		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
@@ -301,23 +533,53 @@ func TestCmdSyncTrackFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
-			return nil, errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			return "snapshot", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			fetches++
+			for _, c := range ch {
+				c <- _track
+			}
+			return &playlist.Playlist{ID: "123", Name: "Playlist", SnapshotID: "fetched", Tracks: []*entity.Track{_track}}, nil
 		}).
 		Reset()
 
-	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123")), "ko")
+	// testing: the track is indexed but not on disk the second time
+	indexData.Set(_track, index.Offline)
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "-p", "123"))
+	assert.Equal(t, 0, fetches)
+	indexData.Set(_track, index.Installed)
+	indexData.Set(_track, index.Offline)
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "-p", "123"))
+	assert.Equal(t, 1, fetches)
+	cached, err := playlist.Load(cachePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "fetched", cached.SnapshotID)
 }
 
-func TestCmdSyncFixOpenFailure(t *testing.T) {
+func TestCmdSyncPlaylistCachedProfile(t *testing.T) {
 	t.Cleanup(cleanup)
+	t.Cleanup(func() { util.ErrSuppress(util.SetProfile("")) })
+
+	var (
+		_track    = &entity.Track{ID: "TestCmdSyncPlaylistCachedProfile", Title: "Title", Artists: []string{"Artist"}}
+		_playlist = &playlist.Playlist{ID: "123", Name: "Playlist", SnapshotID: "snapshot", Tracks: []*entity.Track{_track}}
+		output    = t.TempDir()
+		cache     = t.TempDir()
+		wd        = util.ErrWrap("")(os.Getwd())
+		fetches   int
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, _playlist.Persist(filepath.Join(cache, playlist.CacheDirname, "123.json")))
+	assert.Nil(t, util.SetProfile("alice"))
 
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
 		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
@@ -325,23 +587,45 @@ func TestCmdSyncFixOpenFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
-			return nil, errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			return "snapshot", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			fetches++
+			for _, c := range ch {
+				c <- _track
+			}
+			return &playlist.Playlist{ID: "123", Name: "Playlist", SnapshotID: "fetched", Tracks: []*entity.Track{_track}}, nil
 		}).
 		Reset()
 
-	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "ko")
+	// testing: the cache of the default profile is not the one of the profile in use
+	indexData.Set(_track, index.Offline)
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "-p", "123"))
+	assert.Equal(t, 1, fetches)
+	cached, err := playlist.Load(filepath.Join(cache, util.ProfilesDirname, "alice", playlist.CacheDirname, "123.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, "fetched", cached.SnapshotID)
+	cached, err = playlist.Load(filepath.Join(cache, playlist.CacheDirname, "123.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot", cached.SnapshotID)
 }
 
-func TestCmdSyncFixSpotifyIDFailure(t *testing.T) {
+func TestCmdSyncPlaylistSnapshotFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_playlist = &playlist.Playlist{ID: "123", SnapshotID: "snapshot"}
+		cache     = t.TempDir()
+	)
+	assert.Nil(t, _playlist.Persist(filepath.Join(cache, playlist.CacheDirname, "123.json")))
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyFunc(util.CacheFile, func(filename string) string {
+			return filepath.Join(cache, filename)
 		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
@@ -349,225 +633,401 @@ func TestCmdSyncFixSpotifyIDFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
-			return &id3.Tag{}, nil
-		}).
-		ApplyPrivateMethod(&id3.Tag{}, "userDefinedText", func() string {
-			return ""
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			return "", errors.New("ko")
 		}).
 		Reset()
 
 	// testing
-	assert.ErrorContains(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "does not have spotify ID metadata set")
+	assert.EqualError(t, testExecute(cmdSync(), "-p", "123"), "ko")
 }
 
-func TestCmdSyncFixCloseFailure(t *testing.T) {
+func TestCmdSyncResume(t *testing.T) {
 	t.Cleanup(cleanup)
 
+	var (
+		_track          = &entity.Track{ID: "TestCmdSyncResume", Title: "Title", Artists: []string{"Artist"}}
+		_trackInstalled = &entity.Track{ID: "TestCmdSyncResumeInstalled", Title: "Title", Artists: []string{"Artist"}}
+	)
+
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
+		ApplyMethod(&journal.Journal{}, "Load", func() error {
 			return nil
 		}).
+		ApplyMethod(&journal.Journal{}, "Entries", func() []*journal.Entry {
+			return []*journal.Entry{
+				{Track: _track, Stage: journal.Processed},
+				{Track: _trackInstalled, Stage: journal.Installed},
+			}
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
-			return &id3.Tag{}, nil
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track // to trigger duplicate check
+			return nil
 		}).
-		ApplyPrivateMethod(&id3.Tag{}, "userDefinedText", func() string {
-			return "123"
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return nil, errors.New("ko")
 		}).
-		ApplyMethod(&id3v2.Tag{}, "Close", func() error {
-			return errors.New("ko")
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "ko")
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "--resume")))
+	status, ok := indexData.Get(_trackInstalled)
+	assert.True(t, ok)
+	assert.Equal(t, index.Installed, status)
 }
 
-func TestCmdSyncDecideManual(t *testing.T) {
+func TestCmdSyncDryRun(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncDecideManual", Title: "Title", Artists: []string{"Artist"}}
+	var (
+		_track         = &entity.Track{ID: "TestCmdSyncDryRun", Title: "Title", Artists: []string{"Artist"}}
+		_trackNotFound = &entity.Track{ID: "TestCmdSyncDryRunNotFound", Title: "Title Not Found", Artists: []string{"Artist"}}
+		_trackJournal  = &entity.Track{ID: "TestCmdSyncDryRunJournal", Title: "Title Journal", Artists: []string{"Artist"}}
+		_playlist      = &playlist.Playlist{Name: "Playlist", Tracks: []*entity.Track{_track, _trackNotFound}}
+		errSideEffect  = errors.New("side effect")
+	)
 
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
+		ApplyMethod(&index.Index{}, "Persist", func() error {
+			return errSideEffect
+		}).
+		ApplyMethod(&journal.Journal{}, "Load", func() error {
 			return nil
 		}).
+		ApplyMethod(&journal.Journal{}, "Entries", func() []*journal.Entry {
+			return []*journal.Entry{{Track: _trackJournal, Stage: journal.Processed}}
+		}).
+		ApplyMethod(&journal.Journal{}, "Set", func() error {
+			return errSideEffect
+		}).
+		ApplyMethod(&journal.Journal{}, "Clear", func() error {
+			return errSideEffect
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library",
-			func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-				ch[0] <- _track
-				return nil
-			}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			ch[0] <- _track
+			ch[0] <- _trackNotFound
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func(_ context.Context, track *entity.Track) ([]*provider.Match, error) {
+			if track.ID == _trackNotFound.ID {
+				return []*provider.Match{}, nil
+			}
+			return []*provider.Match{{URL: "http://localhost/", Score: 42}}, nil
+		}).
+		ApplyFunc(downloader.Download, func() error {
+			return errSideEffect
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return errSideEffect
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return errSideEffect
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return errSideEffect
+		}).
 		Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "--manual")))
+	assert.Nil(t, testExecute(cmdSync(), "--dry-run", "--resume", "--prune", "-p", "123"))
+	assert.ElementsMatch(t, []planTrack{
+		{planResume, _trackJournal.ID, "Title Journal", "Artist", "Artist - Title Journal.mp3", "", 0},
+		{planDownload, _track.ID, "Title", "Artist", "Artist - Title.mp3", "http://localhost/", 42},
+		{planNotFound, _trackNotFound.ID, "Title Not Found", "Artist", "Artist - Title Not Found.mp3", "", 0},
+	}, syncPlan.Tracks)
+	assert.Equal(t, []planPlaylist{{"Playlist", "playlist.m3u", 1}}, syncPlan.Playlists)
 }
 
-func TestCmdSyncDecideFailure(t *testing.T) {
+func TestCmdSyncReport(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncDecideFailure", Title: "Title", Artists: []string{"Artist"}}
+	var (
+		_track         = &entity.Track{ID: "TestCmdSyncReport", Title: "Title", Artists: []string{"Artist"}}
+		_trackNotFound = &entity.Track{ID: "TestCmdSyncReportNotFound", Title: "Title Not Found", Artists: []string{"Artist"}}
+		_playlist      = &playlist.Playlist{ID: "123", Name: "Playlist", Tracks: []*entity.Track{_track, _trackNotFound}}
+		path           = filepath.Join(t.TempDir(), "report.json")
+	)
 
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
-		}).
+		ApplyFunc(cmd.Open, func() error { return nil }).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library",
-			func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-				ch[0] <- _track
-				return nil
-			}).
-		ApplyFunc(provider.Search, func(*entity.Track) ([]*provider.Match, error) {
-			return nil, errors.New("ko")
-		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			for _, c := range ch {
+				c <- _track
+				c <- _trackNotFound
+			}
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func(_ context.Context, track *entity.Track) ([]*provider.Match, error) {
+			if track.ID == _trackNotFound.ID {
+				return []*provider.Match{}, nil
+			}
+			return []*provider.Match{{URL: "http://localhost/", Score: 42}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	err := testExecute(cmdSync(), "-p", "123", "--report", path)
+	assert.EqualError(t, err, "synchronization partially complete: 1 tracks not synchronized")
+	var exitErr *exitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitPartial, exitErr.code)
+
+	var report report
+	assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(os.ReadFile(path)), &report))
+	assert.Equal(t, reportPartial, report.Outcome)
+	assert.Equal(t, 2, len(report.Tracks))
+	for _, track := range report.Tracks {
+		assert.Equal(t, []string{"playlist:123"}, track.Collections)
+		switch track.ID {
+		case _track.ID:
+			assert.Equal(t, reportInstalled, track.Outcome)
+			assert.Equal(t, "http://localhost/", track.URL)
+			assert.Equal(t, 42, track.Score)
+			assert.Contains(t, track.Timings, "install")
+		case _trackNotFound.ID:
+			assert.Equal(t, reportNotFound, track.Outcome)
+		}
+	}
+	assert.Equal(t, 1, len(report.Playlists))
+	assert.Equal(t, map[string]int{reportInstalled: 1, reportNotFound: 1}, report.Playlists[0].Totals)
 }
 
-func TestCmdSyncDecideNotFound(t *testing.T) {
+func TestCmdSyncReportFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncDecideNotFound", Title: "Title", Artists: []string{"Artist"}}
-
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
-		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
-			return nil
+			return errors.New("ko")
 		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library",
-			func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-				ch[0] <- _track
-				return nil
-			}).
-		ApplyFunc(provider.Search, func(*entity.Track) ([]*provider.Match, error) {
-			return []*provider.Match{}, nil
-		}).
 		Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(testExecute(cmdSync())))
+	path := filepath.Join(t.TempDir(), "report.json")
+	assert.EqualError(t, testExecute(cmdSync(), "--report", path), "ko")
+	var report report
+	assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(os.ReadFile(path)), &report))
+	assert.Equal(t, reportFailed, report.Outcome)
+	assert.Equal(t, "ko", report.Error)
+	assert.EqualError(t, testExecute(cmdSync(), "--report", t.TempDir()), "ko")
 }
 
-func TestCmdSyncCollectFailure(t *testing.T) {
-	t.Cleanup(cleanup)
-
-	_track := &entity.Track{ID: "TestCmdSyncCollectFailure", Title: "Title", Artists: []string{"Artist"}}
+func TestCmdSyncReportStdout(t *testing.T) {
+	var (
+		stdout = os.Stdout
+		stderr = os.Stderr
+	)
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		tui.SetOutput(nil)
+		cleanup()
+	})
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	require.Nil(t, err)
+	stderrReader, stderrWriter, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
 
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			tui.Printf("building index")
+			return errors.New("ko")
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
 		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--report", "-", "--log-format", "text"), "ko")
+	assert.Nil(t, stdoutWriter.Close())
+	assert.Nil(t, stderrWriter.Close())
+	var report report
+	assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(io.ReadAll(stdoutReader)), &report))
+	assert.Equal(t, reportFailed, report.Outcome)
+	assert.Contains(t, string(util.ErrWrap([]byte{})(io.ReadAll(stderrReader))), "building index")
+}
+
+func TestCmdSyncDryRunStdout(t *testing.T) {
+	stderr := os.Stderr
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		tui.SetOutput(nil)
+		cleanup()
+	})
+	stderrReader, stderrWriter, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stderr = stderrWriter
+
+	// monkey patching
+	defer gomonkey.NewPatches().
 		ApplyMethod(&index.Index{}, "Build", func() error {
+			tui.Printf("building index")
 			return nil
 		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library",
-			func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-				ch[0] <- _track
-				return nil
-			}).
-		ApplyFunc(provider.Search, func(*entity.Track) ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(url string, _ string, _ processor.Processor, ch ...chan []byte) error {
-			if url != "http://localhost/" {
-				return errors.New("ko")
-			}
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{Name: "Playlist"}, nil
 		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "", nil
+		Reset()
+
+	// testing
+	var (
+		stdout bytes.Buffer
+		parent = &cobra.Command{}
+	)
+	parent.AddCommand(cmdSync())
+	parent.SetArgs([]string{"sync", "--dry-run", "--plan-format", "json", "--log-format", "text", "-p", "123"})
+	parent.SetOut(&stdout)
+	parent.SetErr(io.Discard)
+	assert.Nil(t, parent.Execute())
+	assert.Nil(t, stderrWriter.Close())
+	var plan plan
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &plan))
+	assert.Equal(t, []planPlaylist{{"Playlist", "playlist.m3u", 0}}, plan.Playlists)
+	assert.Contains(t, string(util.ErrWrap([]byte{})(io.ReadAll(stderrReader))), "building index")
+}
+
+func TestCmdSyncDryRunFailure(t *testing.T) {
+	assert.EqualError(t, testExecute(cmdSync(), "--dry-run", "--plan-format", "xml"), "unsupported plan format: xml")
+	assert.EqualError(t, testExecute(cmdSync(), "--dry-run", "--manual"), "manual mode cannot be dry-run")
+}
+
+func TestCmdSyncResumeFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&journal.Journal{}, "Load", func() error {
+			return errors.New("ko")
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "--resume")), "ko")
 }
 
-func TestCmdSyncDownloadFailure(t *testing.T) {
+func TestCmdSyncFormatFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncDownloadFailure", Title: "Title", Artists: []string{"Artist"}}
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "--format", "wav")), "unsupported format: wav")
+}
+
+func TestCmdSyncLogFormatFailure(t *testing.T) {
+	assert.EqualError(t, testExecute(cmdSync(), "--log-format", "xml"), "unsupported log format: xml")
+}
+
+func TestCmdSyncPathTemplateFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// testing
+	assert.Error(t, util.ErrOnly(testExecute(cmdSync(), "--path-template", "{{.Unknown}}")))
+}
+
+func TestCmdSyncPathFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.Chdir, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+}
+
+func TestCmdSyncIndexFailure(t *testing.T) {
+	t.Cleanup(cleanup)
 
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
-		}).
-		ApplyMethod(&index.Index{}, "Build", func() error {
-			return nil
-		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-			ch[0] <- _track
-			return nil
-		}).
-		ApplyFunc(provider.Search, func(*entity.Track) ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
+		ApplyMethod(&index.Index{}, "Build", func() error {
 			return errors.New("ko")
 		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "", nil
-		}).
 		Reset()
 
 	// testing
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
 }
 
-func TestCmdSyncLyricsFailure(t *testing.T) {
+func TestCmdSyncAuthFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncLyricsFailure", Title: "Title", Artists: []string{"Artist"}}
-
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
@@ -578,23 +1038,7 @@ func TestCmdSyncLyricsFailure(t *testing.T) {
 			return nil
 		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
-			return &spotify.Client{}, nil
-		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-			ch[0] <- _track
-			return nil
-		}).
-		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
-		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "", errors.New("ko")
+			return &spotify.Client{}, errors.New("ko")
 		}).
 		Reset()
 
@@ -602,52 +1046,41 @@ func TestCmdSyncLyricsFailure(t *testing.T) {
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
 }
 
-func TestCmdSyncProcessorFailure(t *testing.T) {
+func TestCmdSyncClientCredentials(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_track := &entity.Track{ID: "TestCmdSyncProcessorFailure", Title: "Title", Artists: []string{"Artist"}}
-
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
-			return nil
-		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
-			return &spotify.Client{}, nil
-		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-			ch[0] <- _track
-			return nil
-		}).
-		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
+			return nil, errors.New("user authentication attempted")
 		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "lyrics", nil
+		ApplyFunc(spotify.AuthenticateApp, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
 		}).
-		ApplyFunc(processor.Do, func() error {
-			return errors.New("ko")
+		ApplyMethod(&spotify.Client{}, "Album", func() (*entity.Album, error) {
+			return nil, errors.New("ko")
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+	assert.EqualError(t, testExecute(cmdSync(), "--client-credentials", "-a", "123"), "ko")
 }
 
-func TestCmdSyncInstallerFailure(t *testing.T) {
-	t.Cleanup(cleanup)
+func TestCmdSyncClientCredentialsLibrary(t *testing.T) {
+	// testing
+	assert.ErrorIs(t, testExecute(cmdSync(), "--client-credentials"), spotify.ErrUserRequired)
+	assert.ErrorIs(t, testExecute(cmdSync(), "--client-credentials", "-l", "-p", "123"), spotify.ErrUserRequired)
+	assert.ErrorIs(t, testExecute(cmdSync(), "--client-credentials", "--saved-albums"), spotify.ErrUserRequired)
+	assert.ErrorIs(t, testExecute(cmdSync(), "--client-credentials", "--new-releases"), spotify.ErrUserRequired)
+	assert.ErrorIs(t, testExecute(cmdSync(), "--client-credentials", "-t", "123", "--like"), spotify.ErrUserRequired)
+}
 
-	_track := &entity.Track{ID: "TestCmdSyncInstallerFailure", Title: "Title", Artists: []string{"Artist"}}
+func TestCmdSyncLibraryFailure(t *testing.T) {
+	t.Cleanup(cleanup)
 
 	// monkey patching
 	defer gomonkey.NewPatches().
@@ -661,26 +1094,7 @@ func TestCmdSyncInstallerFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, ch ...chan interface{}) error {
-			ch[0] <- _track
-			return nil
-		}).
-		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
-		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "lyrics", nil
-		}).
-		ApplyFunc(processor.Do, func() error {
-			return nil
-		}).
-		ApplyFunc(util.FileMoveOrCopy, func() error {
+		ApplyMethod(&spotify.Client{}, "Library", func() error {
 			return errors.New("ko")
 		}).
 		Reset()
@@ -689,13 +1103,9 @@ func TestCmdSyncInstallerFailure(t *testing.T) {
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
 }
 
-func TestCmdSyncPlaylistEncoderFailure(t *testing.T) {
+func TestCmdSyncPlaylistFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
-		{ID: "TestCmdSyncPlaylistEncoderFailure", Title: "Title", Artists: []string{"Artist"}},
-	}}
-
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
@@ -709,27 +1119,6 @@ func TestCmdSyncPlaylistEncoderFailure(t *testing.T) {
 			return &spotify.Client{}, nil
 		}).
 		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
-			return _playlist, nil
-		}).
-		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
-		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
-		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "lyrics", nil
-		}).
-		ApplyFunc(processor.Do, func() error {
-			return nil
-		}).
-		ApplyFunc(util.FileMoveOrCopy, func() error {
-			return nil
-		}).
-		ApplyMethod(playlist.Playlist{}, "Encoder", func() (any, error) {
 			return nil, errors.New("ko")
 		}).
 		Reset()
@@ -738,13 +1127,9 @@ func TestCmdSyncPlaylistEncoderFailure(t *testing.T) {
 	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
 }
 
-func TestCmdSyncPlaylistEncoderAddFailure(t *testing.T) {
+func TestCmdSyncAlbumFailure(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
-		{ID: "TestCmdSyncPlaylistEncoderAddFailure", Title: "Title", Artists: []string{"Artist"}},
-	}}
-
 	// monkey patching
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
@@ -757,72 +1142,1415 @@ func TestCmdSyncPlaylistEncoderAddFailure(t *testing.T) {
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, ch ...chan interface{}) (*playlist.Playlist, error) {
-			ch[0] <- _playlist.Tracks[0]
-			return _playlist, nil
-		}).
-		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
-			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		ApplyMethod(&spotify.Client{}, "Album", func() (*entity.Album, error) {
+			return nil, errors.New("ko")
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
-			for _, c := range ch {
-				c <- []byte{}
-			}
-			return nil
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-a", "123")), "ko")
+}
+
+func TestCmdSyncArtistFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Artist", func(_ *spotify.Client, _ string, types []string, _ ...chan interface{}) ([]*entity.Track, error) {
+			assert.Equal(t, []string{"album", "compilation"}, types)
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "--artist", "123",
+		"--artist-include", "album,single,compilation", "--artist-exclude", "single")), "ko")
+}
+
+func TestCmdSyncArtistTypeFailure(t *testing.T) {
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--artist", "123", "--artist-include", "live"), "unsupported album type: live")
+	assert.EqualError(t, testExecute(cmdSync(), "--artist", "123", "--artist-exclude", "live"), "unsupported album type: live")
+}
+
+func TestCmdSyncNewReleases(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache = t.TempDir()
+		since []time.Time
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "SavedAlbums", func() ([]*entity.Album, error) {
+			return []*entity.Album{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "NewReleases", func(_ *spotify.Client, checked time.Time, _ ...chan interface{}) ([]*entity.Album, error) {
+			since = append(since, checked)
+			return []*entity.Album{}, nil
+		}).
+		Reset()
+
+	// testing
+	cmd := cmdSync()
+	assert.Nil(t, testExecute(cmd, "--saved-albums", "--new-releases"))
+	assert.False(t, util.ErrWrap(true)(cmd.Flags().GetBool("library")))
+	assert.FileExists(t, filepath.Join(cache, newReleasesBasename))
+	assert.Nil(t, testExecute(cmdSync(), "--new-releases", "--dry-run"))
+	assert.Nil(t, testExecute(cmdSync(), "--new-releases"))
+	assert.Equal(t, 3, len(since))
+	assert.WithinDuration(t, time.Now().Add(-newReleasesLookback), since[0], time.Minute)
+	assert.WithinDuration(t, time.Now(), since[1], time.Minute)
+	assert.Equal(t, since[1], since[2])
+}
+
+func TestCmdSyncNewReleasesFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "NewReleases", func() ([]*entity.Album, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--new-releases"), "ko")
+	assert.NoFileExists(t, filepath.Join(cache, newReleasesBasename))
+	assert.Nil(t, os.WriteFile(filepath.Join(cache, newReleasesBasename), []byte("{"), 0o644))
+	assert.EqualError(t, testExecute(cmdSync(), "--new-releases"), "unexpected end of JSON input")
+}
+
+func TestCmdSyncSince(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		cache = t.TempDir()
+		since = make(map[string][]time.Time) // by collection
+		date  = time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, added time.Time, _ ...chan interface{}) error {
+			since["library"] = append(since["library"], added)
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, id string, added time.Time, _ ...chan interface{}) (*playlist.Playlist, error) {
+			since[id] = append(since[id], added)
+			return &playlist.Playlist{Name: "TestCmdSyncSince"}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testExecute(cmdSync(), "-l", "-p", "123", "--since", "2026-01-02"))
+	assert.FileExists(t, filepath.Join(cache, lastRunBasename))
+	assert.Nil(t, testExecute(cmdSync(), "-l", "--since", sinceLastRun))
+	assert.Nil(t, testExecute(cmdSync(), "-l", "-p", "123", "-p", "456", "--since", sinceLastRun))
+	assert.Equal(t, 3, len(since["library"]))
+	assert.True(t, date.Equal(since["library"][0]))
+	assert.WithinDuration(t, time.Now(), since["library"][1], time.Minute)
+	assert.False(t, since["library"][2].Before(since["library"][1]))
+	assert.Equal(t, 2, len(since["123"]))
+	assert.True(t, date.Equal(since["123"][0]))
+	assert.True(t, since["library"][1].Equal(since["123"][1]))
+	assert.True(t, since["456"][0].IsZero())
+}
+
+func TestCmdSyncSinceFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--since", "yesterday"), "unsupported since: yesterday")
+	assert.EqualError(t, testExecute(cmdSync(), "--since", "7d", "--prune"), "incremental synchronization cannot be pruned")
+	assert.EqualError(t, testExecute(cmdSync(), "--library-limit", "10", "--prune"), "incremental synchronization cannot be pruned")
+	assert.Nil(t, os.WriteFile(filepath.Join(cache, lastRunBasename), []byte("{"), 0o644))
+	assert.Error(t, testExecute(cmdSync(), "--since", sinceLastRun))
+}
+
+func TestSinceParse(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// testing
+	for value, expected := range map[string]time.Time{
+		"7d":                   now.AddDate(0, 0, -7),
+		"36h":                  now.Add(-36 * time.Hour),
+		"2026-01-02":           time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local),
+		"2026-01-02 15:04:05":  time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local),
+		"2026-01-02T15:04:05Z": time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+	} {
+		since, err := sinceParse(value, now)
+		assert.Nil(t, err)
+		assert.True(t, expected.Equal(since), value)
+	}
+	for _, value := range []string{"-7d", "-1h", "d", "yesterday"} {
+		assert.EqualError(t, util.ErrOnly(sinceParse(value, now)), "unsupported since: "+value)
+	}
+}
+
+func TestCmdSyncSavedAlbumsFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "SavedAlbums", func() ([]*entity.Album, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "--saved-albums"), "ko")
+}
+
+func TestCmdSyncTrackFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Track", func() (*entity.Track, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-t", "123")), "ko")
+}
+
+func TestCmdSyncFixOpenFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "ko")
+}
+
+func TestCmdSyncFixSpotifyIDFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
+			return &id3.Tag{}, nil
+		}).
+		ApplyPrivateMethod(&id3.Tag{}, "userDefinedText", func() string {
+			return ""
+		}).
+		Reset()
+
+	// testing
+	assert.ErrorContains(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "does not have spotify ID metadata set")
+}
+
+func TestCmdSyncFixCloseFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
+			return &id3.Tag{}, nil
+		}).
+		ApplyPrivateMethod(&id3.Tag{}, "userDefinedText", func() string {
+			return "123"
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Close", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path")), "ko")
+}
+
+func TestCmdSyncFixLikeFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+	var liked []string
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyFunc(id3.Open, func() (*id3.Tag, error) {
+			return &id3.Tag{}, nil
+		}).
+		ApplyPrivateMethod(&id3.Tag{}, "userDefinedText", func() string {
+			return "123"
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Close", func() error {
+			return nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Like", func(_ *spotify.Client, ids ...string) error {
+			liked = append(liked, ids...)
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-f", "path", "--like")), "ko")
+	assert.Equal(t, []string{"123"}, liked)
+}
+
+func TestCmdSyncDecideManual(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncDecideManual", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library",
+			func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+				ch[0] <- _track
+				return nil
+			}).
+		Reset()
+
+	// testing
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync(), "--manual")))
+}
+
+func TestCmdSyncDecideFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncDecideFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library",
+			func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+				ch[0] <- _track
+				return nil
+			}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+}
+
+func TestCmdSyncDecideNotFound(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncDecideNotFound", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library",
+			func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+				ch[0] <- _track
+				return nil
+			}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return []*provider.Match{}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync()), "synchronization partially complete: 1 tracks not synchronized")
+}
+
+func TestCmdSyncCollectFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncCollectFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library",
+			func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+				ch[0] <- _track
+				return nil
+			}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, url string, _ string, _ processor.Processor, ch ...chan []byte) error {
+			if url != "http://localhost/" {
+				return errors.New("ko")
+			}
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "", nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+}
+
+func TestCmdSyncDownloadFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncDownloadFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, url, _ string, _ processor.Processor, ch ...chan []byte) error {
+			if url == "http://localhost/" {
+				return errors.New("ko")
+			}
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "", nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync()), "synchronization partially complete: 1 tracks not synchronized")
+	status, ok := indexData.Get(_track)
+	assert.True(t, ok)
+	assert.Equal(t, index.Online, status)
+}
+
+func TestCmdSyncDownloadFallback(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncDownloadFallback", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 1}, {URL: "http://localhost/fallback", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, url, _ string, _ processor.Processor, ch ...chan []byte) error {
+			if url == "http://localhost/" {
+				return errors.New("ko")
+			}
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync())))
+	status, ok := indexData.Get(_track)
+	assert.True(t, ok)
+	assert.Equal(t, index.Installed, status)
+	assert.Equal(t, "http://localhost/fallback", _track.UpstreamURL)
+}
+
+func TestCmdSyncVerifyFallback(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncVerifyFallback", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func(context.Context, *entity.Track) ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 1}, {URL: "http://localhost/fallback", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func(_ processor.Verifier, _ context.Context, object interface{}) error {
+			if object.(*entity.Track).UpstreamURL == "http://localhost/" {
+				return errors.New("ko")
+			}
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, url, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, util.ErrOnly(testExecute(cmdSync())))
+	status, ok := indexData.Get(_track)
+	assert.True(t, ok)
+	assert.Equal(t, index.Installed, status)
+	assert.Equal(t, "http://localhost/fallback", _track.UpstreamURL)
+}
+
+func TestCmdSyncLyricsFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncLyricsFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "", errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "--lyrics")), "ko")
+}
+
+func TestCmdSyncProcessorFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncProcessorFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+}
+
+func TestCmdSyncInstallerFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_track := &entity.Track{ID: "TestCmdSyncInstallerFailure", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- _track
+			return nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync())), "ko")
+}
+
+func TestCmdSyncPlaylistEncoderFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
+		{ID: "TestCmdSyncPlaylistEncoderFailure", Title: "Title", Artists: []string{"Artist"}},
+	}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(playlist.Playlist{}, "Encoder", func() (any, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+}
+
+func TestCmdSyncPlaylistEncoderAddFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
+		{ID: "TestCmdSyncPlaylistEncoderAddFailure", Title: "Title", Artists: []string{"Artist"}},
+	}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			ch[0] <- _playlist.Tracks[0]
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Add", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+}
+
+func TestCmdSyncPlaylistEncoderCloseFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
+		{ID: "TestCmdSyncPlaylistEncoderCloseFailure", Title: "Title", Artists: []string{"Artist"}},
+	}}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			ch[0] <- _playlist.Tracks[0]
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(lyrics.Search, func() (string, error) {
+			return "lyrics", nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func() error {
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+}
+
+func TestCmdSyncInterrupted(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		_playlist = &playlist.Playlist{Name: "TestCmdSyncInterrupted", Tracks: []*entity.Track{
+			{ID: "TestCmdSyncInterrupted", Title: "Title", Artists: []string{"Artist"}},
+		}}
+		interrupt context.CancelFunc
+		mixed     bool
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error {
+			return nil
+		}).
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			interrupt = cancel
+			return ctx, cancel
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, _ time.Time, ch ...chan interface{}) (*playlist.Playlist, error) {
+			interrupt()
+			ch[0] <- _playlist.Tracks[0]
+			return _playlist, nil
+		}).
+		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
+			return nil, errors.New("ko")
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			mixed = true
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "synchronization interrupted")
+	assert.True(t, mixed)
+}
+
+// testSyncEmpty synchronizes an empty playlist
+// into a fresh output path and cache directory
+func testSyncEmpty(t *testing.T, args ...string) error {
+	t.Cleanup(cleanup)
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{Name: "Playlist", Tracks: []*entity.Track{}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "NewReleases", func() ([]*entity.Album, error) {
+			return []*entity.Album{}, nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	return testExecute(cmdSync(), append([]string{"-o", t.TempDir(), "-p", "123"}, args...)...)
+}
+
+func TestCmdSyncReportWriteFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&index.Index{}, "Load", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing: an unreadable index cache gets rebuilt, an unwritable report fails
+	assert.Nil(t, testSyncEmpty(t))
+	assert.Error(t, testSyncEmpty(t, "--report", t.TempDir()))
+}
+
+func TestCmdSyncJournalClearFailure(t *testing.T) {
+	var clears, failing int
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&journal.Journal{}, "Clear", func() error {
+		if clears++; clears == failing {
+			return errors.New("ko")
+		}
+		return nil
+	}).Reset()
+
+	// testing: either before or after the synchronization
+	for failing = 1; failing <= 2; failing++ {
+		clears = 0
+		assert.EqualError(t, testSyncEmpty(t), "ko")
+	}
+}
+
+func TestCmdSyncIndexPersistFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&index.Index{}, "Persist", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testSyncEmpty(t), "ko")
+}
+
+func TestCmdSyncWatermarkFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(newReleasesPersist, func() error {
+			return errors.New("new releases")
+		}).
+		ApplyFunc(lastRunPersist, func() error {
+			return errors.New("last run")
 		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "lyrics", nil
+		Reset()
+
+	// testing
+	assert.EqualError(t, testSyncEmpty(t, "--new-releases"), "new releases")
+	assert.EqualError(t, testSyncEmpty(t), "last run")
+}
+
+func TestCmdSyncInterruptedFailure(t *testing.T) {
+	var (
+		interrupt context.CancelFunc
+		failure   error
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(parent)
+			interrupt = cancel
+			return ctx, cancel
 		}).
-		ApplyFunc(processor.Do, func() error {
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			interrupt()
+			return "", failure
+		}).
+		ApplyFunc(playlist.Load, func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{SnapshotID: "snapshot"}, nil
+		}).
+		Reset()
+
+	// testing: whether routines fail or not meanwhile
+	failure = errors.New("ko")
+	assert.EqualError(t, testSyncEmpty(t), "synchronization interrupted")
+	failure = nil
+	assert.EqualError(t, testSyncEmpty(t, "--dry-run"), "synchronization interrupted")
+}
+
+func TestNewReleasesFailure(t *testing.T) {
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.Nil(t, os.Mkdir(filepath.Join(cache, newReleasesBasename), 0o755))
+	assert.Error(t, util.ErrOnly(newReleasesWatermark()))
+	assert.Error(t, newReleasesPersist(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)))
+	cache = filepath.Join(cache, newReleasesBasename, "file")
+	assert.Nil(t, os.WriteFile(cache, []byte{}, 0o644))
+	cache = filepath.Join(cache, "cache")
+	assert.Error(t, newReleasesPersist(time.Now()))
+}
+
+func TestLastRunFailure(t *testing.T) {
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.CacheDirectory, func() string {
+		return cache
+	}).Reset()
+
+	// testing
+	assert.Nil(t, lastRunPersist([]string{}, time.Now()))
+	assert.Error(t, lastRunPersist([]string{daemonLibrary}, time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, os.Mkdir(filepath.Join(cache, lastRunBasename), 0o755))
+	assert.Error(t, util.ErrOnly(lastRunLoad()))
+	assert.Error(t, lastRunPersist([]string{daemonLibrary}, time.Now()))
+	assert.Nil(t, os.Remove(filepath.Join(cache, lastRunBasename)))
+	defer gomonkey.ApplyFunc(os.MkdirAll, func() error {
+		return errors.New("ko")
+	}).Reset()
+	assert.EqualError(t, lastRunPersist([]string{daemonLibrary}, time.Now()), "ko")
+}
+
+func TestCmdSyncPlaylistCacheFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(playlist.Load, func() (*playlist.Playlist, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing: the playlist gets fetched
+	assert.Nil(t, testSyncEmpty(t))
+}
+
+func TestCmdSyncPlaylistCachedSince(t *testing.T) {
+	_track := &entity.Track{ID: "TestCmdSyncPlaylistCachedSince", Title: "Title", Artists: []string{"Artist"}, AddedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(playlist.Load, func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{Name: "Playlist", SnapshotID: "snapshot", Tracks: []*entity.Track{_track}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func() (string, error) {
+			return "snapshot", nil
+		}).
+		Reset()
+
+	// testing: tracks added earlier are not synchronized
+	assert.Nil(t, testSyncEmpty(t, "--since", "2020-01-01"))
+	_, ok := indexData.Get(_track)
+	assert.False(t, ok)
+}
+
+func TestCmdSyncArchiveFetchFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(playlist.Load, func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{SnapshotID: "snapshot"}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "PlaylistSnapshot", func(_ *spotify.Client, id string) (string, error) {
+			if id == "456" {
+				return "", errors.New("ko")
+			}
+			return "", nil
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, testSyncEmpty(t, "--archive", "456"), "ko")
+}
+
+func TestCmdSyncArchiveExpireFailure(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		output  = t.TempDir()
+		cache   = t.TempDir()
+		wd      = util.ErrWrap("")(os.Getwd())
+		expired = fmt.Sprintf("playlist-%d-w01.m3u", time.Now().Year()-1)
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	assert.Nil(t, os.WriteFile(filepath.Join(output, expired), []byte{}, 0o644))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
+		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
-		ApplyFunc(util.FileMoveOrCopy, func() error {
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Playlist", func() (*playlist.Playlist, error) {
+			return &playlist.Playlist{Name: "Playlist", Tracks: []*entity.Track{}}, nil
+		}).
+		ApplyFunc(os.Remove, func(path string) error {
+			if filepath.Base(path) == expired {
+				return errors.New("ko")
+			}
 			return nil
 		}).
-		ApplyMethod(&playlist.M3UEncoder{}, "Add", func() error {
-			return errors.New("ko")
+		Reset()
+
+	// testing
+	assert.EqualError(t, testExecute(cmdSync(), "-o", output, "--archive", "123", "--archive-retention", "1"), "ko")
+	assert.FileExists(t, filepath.Join(output, expired))
+}
+
+func TestCmdSyncPruneArchiveFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(util.FileMoveOrCopy, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	output, err := testSyncPrune(t, "--prune-archive", t.TempDir())
+	assert.EqualError(t, err, "1 tracks could not be pruned: ko")
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestCmdSyncPruneArchivesFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(playlist.Archives, func() ([]string, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	output, err := testSyncPrune(t)
+	assert.EqualError(t, err, "ko")
+	assert.FileExists(t, filepath.Join(output, "Artist - Stale.opus"))
+}
+
+func TestFuzzyFindTracksForSpotify(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		dir     = t.TempDir()
+		answers = []string{"y", "n", "y", "y"}
+		saves   = 0
+		tracks  = []*entity.Track{
+			{ID: "exact", Title: "Title", Artists: []string{"Artist"}},
+			{ID: "base", Title: "Song (Live)", Artists: []string{"Band"}},
+			{ID: "variant", Title: "Variant - Remastered", Artists: []string{"Nobody"}},
+			{ID: "words", Title: "Wonderful Morning", Artists: []string{"Someone Else"}},
+			{ID: "title", Title: "Great Lovely Day", Artists: []string{"Xyz"}},
+			{ID: "unsaved", Title: "Tune", Artists: []string{"Singer"}},
+		}
+		paths = []string{
+			filepath.Join(dir, "Artist - Title.mp3"),
+			filepath.Join(dir, "Band - Song.mp3"),
+			filepath.Join(dir, "Someone Wonderful.mp3"),
+			filepath.Join(dir, "Great Lovely.mp3"),
+			filepath.Join(dir, "Singer - Tune.mp3"),
+		}
+	)
+	for _, path := range paths {
+		assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	}
+	// a directory cannot be opened for tagging
+	assert.Nil(t, os.Remove(paths[2]))
+	assert.Nil(t, os.Mkdir(paths[2], 0o755))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&anchor.Window{}, "Reads", func() string {
+			answer := answers[0]
+			answers = answers[1:]
+			return answer
+		}).
+		ApplyMethod(&id3.Tag{}, "Save", func() error {
+			if saves++; saves > 1 {
+				return errors.New("ko")
+			}
+			return nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+	assert.Nil(t, fuzzyFindTracksForSpotify(context.Background(), nil, tracks))
+	assert.Nil(t, fuzzyFindTracksForSpotify(context.Background(), paths, tracks))
+	assert.Empty(t, answers)
+	assert.Equal(t, 2, saves)
+	assert.Equal(t, 1, indexData.Size())
 }
 
-func TestCmdSyncPlaylistEncoderCloseFailure(t *testing.T) {
+func TestRoutineIndexFuzzy(t *testing.T) {
 	t.Cleanup(cleanup)
 
-	_playlist := &playlist.Playlist{Tracks: []*entity.Track{
-		{ID: "TestCmdSyncPlaylistEncoderCloseFailure", Title: "Title", Artists: []string{"Artist"}},
-	}}
+	var (
+		dir     = t.TempDir()
+		answers = 0
+		authErr error
+		library error
+	)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Artist - Title.mp3"), []byte{}, 0o644))
 
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyFunc(time.Sleep, func() {}).
-		ApplyFunc(cmd.Open, func() error {
+		ApplyMethod(&index.Index{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&anchor.Window{}, "Reads", func() string {
+			answers++
+			return "n"
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, authErr
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, channels ...chan interface{}) error {
+			if library != nil {
+				return library
+			}
+			for _, c := range channels {
+				c <- &entity.Track{ID: "TestRoutineIndexFuzzy", Title: "Title", Artists: []string{"Artist"}}
+			}
 			return nil
 		}).
+		Reset()
+
+	// testing: no failure prevents indexing from completing
+	scan := func() {
+		ch := make(chan error, 1)
+		defer close(ch)
+		command := cmdSync()
+		assert.Nil(t, command.PreRunE(command, nil))
+		indexData = index.New()
+		routineIndex(dir, false)(context.Background(), ch)
+		assert.True(t, <-routineSemaphores[routineTypeIndex])
+		assert.Empty(t, ch)
+	}
+	authErr = errors.New("ko")
+	scan()
+	authErr, library = nil, errors.New("ko")
+	scan()
+	library = nil
+	scan()
+	assert.Equal(t, 1, answers)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(fuzzyFindTracksForSpotify, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	scan()
+}
+
+// testSyncResume synchronizes the library, resuming
+// the given journal entries, whose downloads are found
+func testSyncResume(t *testing.T, entries func() []*journal.Entry, args ...string) error {
+	t.Cleanup(cleanup)
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(util.CacheDirectory, func() string {
+			return cache
+		}).
 		ApplyMethod(&index.Index{}, "Build", func() error {
 			return nil
 		}).
+		ApplyMethod(&journal.Journal{}, "Load", func() error {
+			return nil
+		}).
+		ApplyMethod(&journal.Journal{}, "Entries", func() []*journal.Entry {
+			entries := entries()
+			for _, entry := range entries {
+				assert.Nil(t, os.WriteFile(entry.Track.Path().Download(), []byte{}, 0o644))
+			}
+			return entries
+		}).
 		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
 			return &spotify.Client{}, nil
 		}).
-		ApplyMethod(&spotify.Client{}, "Playlist", func(_ *spotify.Client, _ string, ch ...chan interface{}) (*playlist.Playlist, error) {
-			ch[0] <- _playlist.Tracks[0]
-			return _playlist, nil
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			ch[0] <- &entity.Track{ID: "testSyncResume", Title: "Title", Artists: []string{"Artist"}}
+			return nil
 		}).
 		ApplyFunc(provider.Search, func() ([]*provider.Match, error) {
 			return []*provider.Match{{URL: "http://localhost/", Score: 0}}, nil
 		}).
-		ApplyFunc(downloader.Download, func(_, _ string, _ processor.Processor, ch ...chan []byte) error {
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
 			for _, c := range ch {
 				c <- []byte{}
 			}
 			return nil
 		}).
-		ApplyFunc(lyrics.Search, func() (string, error) {
-			return "lyrics", nil
-		}).
 		ApplyFunc(processor.Do, func() error {
 			return nil
 		}).
@@ -830,10 +2558,239 @@ func TestCmdSyncPlaylistEncoderCloseFailure(t *testing.T) {
 			return nil
 		}).
 		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	return testExecute(cmdSync(), append([]string{"-o", t.TempDir(), "-l", "--resume"}, args...)...)
+}
+
+// testSyncResumeEntries returns a journal entry for each stage
+// a track can be resumed from, other than the installation one
+func testSyncResumeEntries() []*journal.Entry {
+	return []*journal.Entry{
+		{Track: &entity.Track{ID: "testSyncResumeDecided", Title: "Decided", Artists: []string{"Artist"}}, Stage: journal.Decided},
+		{Track: &entity.Track{ID: "testSyncResumeCollected", Title: "Collected", Artists: []string{"Artist"}}, Stage: journal.Collected},
+		{Track: &entity.Track{ID: "testSyncResumeArtwork", Title: "Artwork", Artists: []string{"Artist"}, Artwork: entity.Artwork{URL: "http://localhost/artwork.jpg"}}, Stage: journal.Collected},
+		{Track: &entity.Track{ID: "testSyncResumeProcessed", Title: "Processed", Artists: []string{"Artist"}}, Stage: journal.Processed},
+	}
+}
+
+func TestCmdSyncResumeStages(t *testing.T) {
+	var entries []*journal.Entry
+
+	// testing: collected tracks missing their artwork are collected again
+	assert.Nil(t, testSyncResume(t, func() []*journal.Entry {
+		entries = testSyncResumeEntries()
+		return entries
+	}))
+	for _, entry := range entries {
+		status, ok := indexData.Get(entry.Track)
+		assert.True(t, ok)
+		assert.Equal(t, index.Installed, status)
+	}
+
+	// testing: on dry runs, nothing gets resumed
+	assert.Nil(t, testSyncResume(t, func() []*journal.Entry {
+		entries = testSyncResumeEntries()
+		return entries
+	}, "--dry-run"))
+	for _, entry := range entries {
+		status, _ := indexData.Get(entry.Track)
+		assert.NotEqual(t, index.Installed, status)
+	}
+}
+
+func TestCmdSyncResumeJournalFailure(t *testing.T) {
+	var failing int
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&journal.Journal{}, "Set", func(_ *journal.Journal, _ *entity.Track, stage int) error {
+		if stage == failing {
 			return errors.New("ko")
+		}
+		return nil
+	}).Reset()
+
+	// testing
+	for _, failing = range []int{journal.Decided, journal.Collected, journal.Processed, journal.Installed} {
+		assert.Error(t, testSyncResume(t, testSyncResumeEntries))
+	}
+}
+
+func TestCmdSyncResumeManualFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&anchor.Window{}, "Reads", func() string {
+			return "2"
+		}).
+		ApplyFunc(fuzzySearchLocalFiles, func() ([]string, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing: tracks failing to be looked for on disk get skipped
+	assert.Nil(t, testSyncResume(t, func() []*journal.Entry { return nil }, "-m"))
+}
+
+func TestCmdSyncResumeInterrupted(t *testing.T) {
+	var interrupt context.CancelFunc
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(signal.NotifyContext, func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(parent)
+		interrupt = cancel
+		return ctx, cancel
+	}).Reset()
+
+	// testing: queued tracks get drained
+	assert.EqualError(t, testSyncResume(t, func() []*journal.Entry {
+		interrupt()
+		return testSyncResumeEntries()
+	}), "synchronization interrupted")
+}
+
+func TestRoutineCollectAssetFailure(t *testing.T) {
+	var (
+		track       = &entity.Track{ID: "TestRoutineCollectAssetFailure", Title: "Title", Artists: []string{"Artist"}, Matches: []*entity.Match{{URL: "http://localhost/1"}, {URL: "http://localhost/2"}}}
+		ctx, cancel = context.WithCancel(context.Background())
+		ch          = make(chan error, 1)
+	)
+	defer close(ch)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(downloader.Download, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing: matches beyond the fallback depth are not tried
+	routineCollectAsset(track, 1, processor.Verifier{})(ctx, ch)
+	assert.ErrorIs(t, <-ch, errMatchesFailed)
+	assert.Equal(t, "http://localhost/1", track.UpstreamURL)
+
+	// testing: interruptions stop falling back
+	cancel()
+	routineCollectAsset(track, 2, processor.Verifier{})(ctx, ch)
+	assert.EqualError(t, <-ch, "ko")
+	assert.Equal(t, "http://localhost/1", track.UpstreamURL)
+}
+
+func TestRoutineCollectLyrics(t *testing.T) {
+	track := &entity.Track{ID: "TestRoutineCollectLyrics", Title: "Title", Artists: []string{"Artist"}}
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(lyrics.Search, func() (string, error) {
+		return "lyrics", nil
+	}).Reset()
+
+	// testing
+	ch := make(chan error, 1)
+	defer close(ch)
+	routineCollectLyrics(track)(context.Background(), ch)
+	assert.Empty(t, ch)
+	assert.Equal(t, "lyrics", track.Lyrics)
+}
+
+func TestCmdSyncManual(t *testing.T) {
+	t.Cleanup(cleanup)
+
+	var (
+		output  = t.TempDir()
+		wd      = util.ErrWrap("")(os.Getwd())
+		tracks  = []*entity.Track{}
+		answers = []string{
+			"0",     // Skipped
+			"1", "", // Unlinked
+			"1", "http://localhost/", // Linked
+			"", "1", // Title
+			"2", "0", // Cancelled
+			"2", "99", // Invalid
+			"2", "", // Broken
+			"2", "1", // Unsaved
+			"2", "1", // Unmoved
+			"2", // Nothing
+			"9", // Unknown
+		}
+	)
+	t.Cleanup(func() { util.ErrSuppress(os.Chdir(wd)) })
+	for _, title := range []string{"Skipped", "Unlinked", "Linked", "Title", "Cancelled", "Invalid", "Broken", "Unsaved", "Unmoved", "Nothing", "Unknown"} {
+		artist := "Artist"
+		if title == "Nothing" {
+			artist = "Nobody"
+		}
+		tracks = append(tracks, &entity.Track{ID: "TestCmdSyncManual" + title, Title: title, Artists: []string{artist}})
+	}
+	for _, title := range []string{"Title", "Cancelled", "Invalid", "Broken", "Unsaved", "Unmoved", "One", "Two", "Three", "Four", "Five"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - "+title+".opus"), []byte{}, 0o644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(output, "Artist - Cover.jpg"), []byte{}, 0o644))
+	assert.Nil(t, os.Mkdir(filepath.Join(output, "Artist - Folder"), 0o755))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyFunc(cmd.Open, func() error { return nil }).
+		ApplyMethod(&index.Index{}, "Build", func() error {
+			return nil
+		}).
+		ApplyMethod(&anchor.Window{}, "Reads", func() string {
+			answer := answers[0]
+			answers = answers[1:]
+			return answer
+		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+			if filepath.Base(path) == "Artist - Broken.opus" {
+				return nil, errors.New("ko")
+			}
+			return &cmd.Probe{Tags: map[string]string{}}, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, path string) error {
+			if filepath.Base(path) == "Artist - Unsaved.opus" {
+				return errors.New("ko")
+			}
+			return nil
+		}).
+		ApplyFunc(spotify.Authenticate, func() (*spotify.Client, error) {
+			return &spotify.Client{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Library", func(_ *spotify.Client, _ int, _ time.Time, ch ...chan interface{}) error {
+			for _, track := range tracks {
+				ch[0] <- track
+			}
+			return nil
+		}).
+		ApplyMethod(processor.Verifier{}, "Do", func() error {
+			return nil
+		}).
+		ApplyFunc(downloader.Download, func(_ context.Context, _, _ string, _ processor.Processor, ch ...chan []byte) error {
+			for _, c := range ch {
+				c <- []byte{}
+			}
+			return nil
+		}).
+		ApplyFunc(processor.Do, func() error {
+			return nil
+		}).
+		ApplyFunc(util.FileMoveOrCopy, func(source, _ string, _ ...bool) error {
+			if filepath.Base(source) == "Artist - Unmoved.opus" {
+				return errors.New("ko")
+			}
+			return nil
+		}).
+		ApplyMethod(&playlist.M3UEncoder{}, "Close", func() error {
+			return nil
 		}).
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testExecute(cmdSync(), "-p", "123")), "ko")
+	assert.Nil(t, testExecute(cmdSync(), "-o", output, "-l", "-m"))
+	assert.Empty(t, answers)
+	for _, track := range tracks {
+		status, _ := indexData.Get(track)
+		assert.Equal(t, track.Title == "Title" || track.Title == "Linked", status == index.Installed, track.Title)
+	}
 }
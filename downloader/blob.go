@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -37,8 +38,13 @@ func (blob) supports(url string) bool {
 	}
 }
 
-func (blob) download(url, path string, processor processor.Processor, channels ...chan []byte) error {
-	response, err := http.Get(url) // nolint
+func (blob) download(ctx context.Context, url, path string, processor processor.Processor, channels ...chan []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return err
 	}
@@ -48,23 +54,25 @@ func (blob) download(url, path string, processor processor.Processor, channels .
 		return errors.New("cannot get blob: " + response.Status)
 	}
 
-	output, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer output.Close()
-
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		return err
 	}
 
 	if processor != nil && processor.Applies(body) {
-		if err := processor.Do(body); err != nil {
+		if err := processor.Do(ctx, body); err != nil {
 			return err
 		}
 	}
 
+	// create output only once the blob is complete
+	// so that an interruption does not leave it truncated
+	output, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
 	for _, ch := range channels {
 		ch <- body
 	}
@@ -1,10 +1,12 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -23,7 +25,7 @@ func (p mockProcessor) Applies(interface{}) bool {
 	return p.applies
 }
 
-func (p mockProcessor) Do(interface{}) error {
+func (p mockProcessor) Do(context.Context, interface{}) error {
 	return p.err
 }
 
@@ -92,7 +94,7 @@ func TestBlobUnsupported(t *testing.T) {
 func TestBlobDownload(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 			return &http.Response{
 				StatusCode: 200,
 				Body:       io.NopCloser(strings.NewReader("bitch")),
@@ -113,13 +115,13 @@ func TestBlobDownload(t *testing.T) {
 	// testing
 	ch := make(chan []byte, 1)
 	defer close(ch)
-	assert.Nil(t, blob{}.download("http://davidepucci.it", "/dev/null", stubProcessor(true, nil), ch))
+	assert.Nil(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", stubProcessor(true, nil), ch))
 }
 
 func TestBlobDownloadProcessorFailure(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 			return &http.Response{
 				StatusCode: 200,
 				Body:       io.NopCloser(strings.NewReader("bitch")),
@@ -135,22 +137,32 @@ func TestBlobDownloadProcessorFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, blob{}.download("http://davidepucci.it", "/dev/null", stubProcessor(true, errors.New("ko"))), "ko")
+	assert.EqualError(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", stubProcessor(true, errors.New("ko"))), "ko")
+}
+
+func TestBlobDownloadRequestFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(http.NewRequestWithContext, func() (*http.Request, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", nil), "ko")
 }
 
 func TestBlobDownloadFailure(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return nil, errors.New("ko")
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, blob{}.download("http://davidepucci.it", "/dev/null", nil), "ko")
+	assert.EqualError(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", nil), "ko")
 }
 
 func TestBlobDownloadNotFound(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 404,
 			Body:       io.NopCloser(strings.NewReader("")),
@@ -158,13 +170,13 @@ func TestBlobDownloadNotFound(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.NotNil(t, blob{}.download("http://davidepucci.it", "/dev/null", nil))
+	assert.NotNil(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", nil))
 }
 
 func TestBlobDownloadFileCreationFailure(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 			return &http.Response{
 				StatusCode: 200,
 				Body:       io.NopCloser(strings.NewReader("")),
@@ -177,13 +189,13 @@ func TestBlobDownloadFileCreationFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, blob{}.download("http://davidepucci.it", "/dev/null", nil), "ko")
+	assert.EqualError(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", nil), "ko")
 }
 
 func TestBlobDownloadReadFailure(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
-		ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 			return &http.Response{
 				StatusCode: 200,
 				Body:       io.NopCloser(strings.NewReader("")),
@@ -199,5 +211,5 @@ func TestBlobDownloadReadFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, blob{}.download("http://davidepucci.it", "/dev/null", nil), "ko")
+	assert.EqualError(t, blob{}.download(context.Background(), "http://davidepucci.it", "/dev/null", nil), "ko")
 }
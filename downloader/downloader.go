@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -12,10 +13,10 @@ var downloaders = []Downloader{}
 
 type Downloader interface {
 	supports(string) bool
-	download(string, string, processor.Processor, ...chan []byte) error
+	download(context.Context, string, string, processor.Processor, ...chan []byte) error
 }
 
-func Download(url, path string, processor processor.Processor, channels ...chan []byte) error {
+func Download(ctx context.Context, url, path string, processor processor.Processor, channels ...chan []byte) error {
 	if len(url) == 0 {
 		return nil
 	}
@@ -33,7 +34,7 @@ func Download(url, path string, processor processor.Processor, channels ...chan
 				return err
 			}
 
-			return downloader.download(url, path, processor, channels...)
+			return downloader.download(ctx, url, path, processor, channels...)
 		}
 	}
 	return errors.New("unsupported url: " + url)
@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -49,11 +50,24 @@ func TestDownload(t *testing.T) {
 	// testing
 	ch := make(chan []byte, 1)
 	defer close(ch)
-	assert.Nil(t, Download("http://youtu.be", "fname.txt", nil, ch))
+	assert.Nil(t, Download(context.Background(), "http://youtu.be", "fname.txt", nil, ch))
+}
+
+func TestYouTubeDlDownload(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(cmd.YouTubeDl, func() error {
+		return nil
+	}).Reset()
+
+	// testing
+	ch := make(chan []byte, 1)
+	defer close(ch)
+	assert.Nil(t, youTubeDl{}.download(context.Background(), "http://youtu.be", "fname.txt", nil, ch))
+	assert.Nil(t, <-ch)
 }
 
 func TestDownloadEmpty(t *testing.T) {
-	assert.Nil(t, Download("", "fname.txt", nil))
+	assert.Nil(t, Download(context.Background(), "", "fname.txt", nil))
 }
 
 func TestDownloadAlreadyExists(t *testing.T) {
@@ -65,7 +79,7 @@ func TestDownloadAlreadyExists(t *testing.T) {
 	// testing
 	ch := make(chan []byte, 1)
 	defer close(ch)
-	assert.Nil(t, Download("http://youtu.be", "fname.txt", nil, ch))
+	assert.Nil(t, Download(context.Background(), "http://youtu.be", "fname.txt", nil, ch))
 }
 
 func TestDownloadMakeDirFailure(t *testing.T) {
@@ -80,7 +94,7 @@ func TestDownloadMakeDirFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, Download("http://youtu.be", "fname.txt", nil), "ko")
+	assert.EqualError(t, Download(context.Background(), "http://youtu.be", "fname.txt", nil), "ko")
 }
 
 func TestDownloadUnsupported(t *testing.T) {
@@ -102,5 +116,5 @@ func TestDownloadUnsupported(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Error(t, Download("http://davidepucci.it", "fname.txt", nil))
+	assert.Error(t, Download(context.Background(), "http://davidepucci.it", "fname.txt", nil))
 }
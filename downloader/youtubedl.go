@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"strings"
 
 	"github.com/streambinder/spotitube/processor"
@@ -19,12 +20,12 @@ func (youTubeDl) supports(url string) bool {
 	return strings.Contains(url, "://youtu.be") || strings.Contains(url, "://youtube.com")
 }
 
-func (youTubeDl) download(url, path string, _ processor.Processor, channels ...chan []byte) error {
+func (youTubeDl) download(ctx context.Context, url, path string, _ processor.Processor, channels ...chan []byte) error {
 	// in this case, data won't be passed through channels
 	// as too heavy
 	for _, ch := range channels {
 		ch <- nil
 	}
 
-	return cmd.YouTubeDl(url, path)
+	return cmd.YouTubeDl(ctx, url, path)
 }
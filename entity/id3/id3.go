@@ -1,6 +1,7 @@
 package id3
 
 import (
+	"context"
 	"strings"
 
 	"github.com/bogem/id3v2/v2"
@@ -9,11 +10,15 @@ import (
 const (
 	frameAttachedPicture      = "Attached picture"
 	frameTrackNumber          = "Track number/Position in set"
+	frameDiscNumber           = "Part of a set"
 	frameUnsynchronizedLyrics = "Unsynchronised lyrics/text transcription"
 	frameSpotifyID            = "Spotify ID"
 	frameArtworkURL           = "Artwork URL"
 	frameDuration             = "Duration"
 	frameUpstreamURL          = "Upstream URL"
+	frameAddedDate            = "Added Date"
+	frameArtists              = "Artists"
+	artistsSeparator          = "; "
 )
 
 type Tag struct {
@@ -43,6 +48,20 @@ func (tag *Tag) TrackNumber() string {
 	return tag.GetTextFrame(tag.CommonID(frameTrackNumber)).Text
 }
 
+func (tag *Tag) SetDiscNumber(number string) {
+	tag.AddFrame(
+		tag.CommonID(frameDiscNumber),
+		id3v2.TextFrame{
+			Encoding: tag.DefaultEncoding(),
+			Text:     number,
+		},
+	)
+}
+
+func (tag *Tag) DiscNumber() string {
+	return tag.GetTextFrame(tag.CommonID(frameDiscNumber)).Text
+}
+
 func (tag *Tag) setUserDefinedText(key, value string) {
 	tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
 		Encoding:    tag.DefaultEncoding(),
@@ -102,6 +121,27 @@ func (tag *Tag) UpstreamURL() string {
 	return tag.userDefinedText(frameUpstreamURL)
 }
 
+func (tag *Tag) SetAddedDate(date string) {
+	tag.setUserDefinedText(frameAddedDate, date)
+}
+
+func (tag *Tag) AddedDate() string {
+	return tag.userDefinedText(frameAddedDate)
+}
+
+// SetArtists keeps track of every artist, the artist
+// frame only holding the main one, as players expect
+func (tag *Tag) SetArtists(artists []string) {
+	tag.setUserDefinedText(frameArtists, strings.Join(artists, artistsSeparator))
+}
+
+func (tag *Tag) Artists() []string {
+	if artists := tag.userDefinedText(frameArtists); len(artists) > 0 {
+		return strings.Split(artists, artistsSeparator)
+	}
+	return nil
+}
+
 func (tag *Tag) SetAttachedPicture(picture []byte) {
 	tag.AddAttachedPicture(id3v2.PictureFrame{
 		Encoding:    tag.DefaultEncoding(),
@@ -137,6 +177,12 @@ func (tag *Tag) UnsynchronizedLyrics() string {
 	return ""
 }
 
+// Save writes the tag to the file: being done
+// in process, it cannot be interrupted midway
+func (tag *Tag) Save(context.Context) error {
+	return tag.Tag.Save()
+}
+
 func (tag *Tag) Close() error {
 	if err := tag.Tag.Close(); err != id3v2.ErrNoFile && err != nil {
 		return err
@@ -1,6 +1,7 @@
 package id3
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -31,25 +32,32 @@ func TestOpen(t *testing.T) {
 	assert.Empty(t, mimeType)
 	assert.Empty(t, image)
 	assert.Empty(t, tag.UnsynchronizedLyrics())
+	assert.Empty(t, tag.Artists())
 
 	tag.SetAttachedPicture([]byte("picture"))
 	tag.SetUnsynchronizedLyrics("title", "lyrics")
 	tag.SetTrackNumber("1")
+	tag.SetDiscNumber("2")
+	tag.SetArtists([]string{"Artist", "Guest"})
 	tag.SetSpotifyID("Spotify ID")
 	tag.SetArtworkURL("Artwork URL")
 	tag.SetDuration("60")
 	tag.SetUpstreamURL("Upstream URL")
+	tag.SetAddedDate("2026-01-02T15:04:05Z")
 
 	mimeType, image = tag.AttachedPicture()
 	assert.Equal(t, "image/jpeg", mimeType)
 	assert.Equal(t, []byte("picture"), image)
 	assert.Equal(t, "lyrics", tag.UnsynchronizedLyrics())
 	assert.Equal(t, "1", tag.TrackNumber())
+	assert.Equal(t, "2", tag.DiscNumber())
+	assert.Equal(t, []string{"Artist", "Guest"}, tag.Artists())
 	assert.Equal(t, "Spotify ID", tag.SpotifyID())
 	assert.Equal(t, "Artwork URL", tag.ArtworkURL())
 	assert.Equal(t, "60", tag.Duration())
 	assert.Equal(t, "Upstream URL", tag.UpstreamURL())
 	assert.Equal(t, "Upstream URL", tag.UpstreamURL()) // served from cache
+	assert.Equal(t, "2026-01-02T15:04:05Z", tag.AddedDate())
 	assert.Equal(t, "", tag.userDefinedText("not existing"))
 }
 
@@ -63,6 +71,23 @@ func TestOpenFailure(t *testing.T) {
 	assert.EqualError(t, util.ErrOnly(Open("", id3v2.Options{})), "ko")
 }
 
+func TestSave(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(Open, func() (*Tag, error) {
+			return &Tag{}, nil
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Save", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	tag, err := Open("", id3v2.Options{})
+	assert.Nil(t, err)
+	assert.EqualError(t, tag.Save(context.Background()), "ko")
+}
+
 func TestClose(t *testing.T) {
 	// monkey patching
 	defer gomonkey.ApplyFunc(Open, func() (*Tag, error) {
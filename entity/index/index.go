@@ -1,15 +1,20 @@
 package index
 
 import (
+	"encoding/json"
+	"errors"
 	"io/fs"
+	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
-	"github.com/bogem/id3v2/v2"
 	"github.com/gosimple/slug"
 	"github.com/streambinder/spotitube/entity"
-	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/tag"
+	"github.com/streambinder/spotitube/util"
 )
 
 const (
@@ -19,9 +24,29 @@ const (
 	Installed        // synced and successfully installed
 )
 
+const Basename = "index.json"
+
+// Entry describes a track known to the index, either
+// because found on disk or because handled during a sync
+type Entry struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	UpstreamURL string    `json:"upstream_url,omitempty"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	Status      int       `json:"status"`
+	Synced      time.Time `json:"synced,omitempty"`
+	// Track is rebuilt out of the file tags, if it carries a Spotify ID:
+	// files tagged before every artist got written miss the artists
+	Track *entity.Track `json:"track,omitempty"`
+}
+
 type Index struct {
-	data map[string]int
-	lock sync.RWMutex
+	data  map[string]*Entry // tracks by key (Spotify ID, if any)
+	files map[string]*Entry // track files found on disk by path
+	cache map[string]*Entry // track files loaded from a previous run by path
+	built bool
+	lock  sync.RWMutex
 }
 
 func keyFromTrack(track *entity.Track) string {
@@ -34,7 +59,7 @@ func keyFromTrack(track *entity.Track) string {
 
 func keyFromPath(path string) string {
 	// For files on disk, try to get the Spotify ID from the tags first
-	tag, err := id3.Open(path, id3v2.Options{Parse: true})
+	tag, err := tag.Open(path, true)
 	if err == nil {
 		defer tag.Close()
 		if id := tag.SpotifyID(); len(id) > 0 {
@@ -47,74 +72,365 @@ func keyFromPath(path string) string {
 
 func New() *Index {
 	return &Index{
-		make(map[string]int),
+		make(map[string]*Entry),
+		make(map[string]*Entry),
+		make(map[string]*Entry),
+		false,
 		sync.RWMutex{},
 	}
 }
 
-func (index *Index) Build(path string, init ...int) error {
+// Load reads the index persisted by a previous run, which will be used
+// as a cache to avoid parsing again those files which did not change
+func (index *Index) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	for _, entry := range entries {
+		index.cache[entry.Path] = entry
+	}
+	return nil
+}
+
+// Persist writes every track file known to be on disk
+// so that a later run can Load it back: unless built,
+// the index does not know them all and nothing is written
+func (index *Index) Persist(path string) error {
+	index.lock.RLock()
+	if !index.built {
+		index.lock.RUnlock()
+		return nil
+	}
+	entries := make([]*Entry, 0, len(index.files))
+	for _, entry := range index.files {
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(entries)
+	index.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that an interruption
+	// never leaves a truncated index behind
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (index *Index) Build(root string, init ...int) error {
+	return index.build(root, entity.PathTemplate != nil, init...)
+}
+
+// BuildTree is Build descending into every directory, whatever
+// the layout, i.e. whether tracks are laid out in a tree or not
+func (index *Index) BuildTree(root string, init ...int) error {
+	return index.build(root, true, init...)
+}
+
+func (index *Index) build(root string, tree bool, init ...int) error {
 	status := Offline
 	for _, override := range init {
 		status = override
 	}
 
-	return filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
-		// stop on root (or any subsequent inner directory, which is not relevant for us)
-		// directory walk failure
+	files := make(map[string]*Entry)
+	if err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		// stop on directory walk failure
 		if err != nil {
 			return err
 		}
 
-		// skip any inner directory from walk
-		if entry.IsDir() && entry.Name() != path {
-			return fs.SkipDir
+		// skip any inner directory from walk,
+		// unless tracks are laid out in a tree
+		if entry.IsDir() {
+			if path != root && !tree {
+				return fs.SkipDir
+			}
+			return nil
 		}
 
 		// skip any file other than supported tracks
-		if !strings.HasSuffix(filepath.Ext(path), entity.TrackFormat) {
+		if !entity.IsTrackFile(path) {
 			return nil
 		}
 
-		tag, err := id3.Open(path, id3v2.Options{Parse: true})
+		info, err := entry.Info()
 		if err != nil {
 			return err
 		}
 
-		if id := tag.SpotifyID(); len(id) > 0 {
-			index.SetPath(path, status)
+		file, err := index.scan(path, info)
+		if err != nil {
+			return err
 		}
+		files[path] = file
+		return nil
+	}); err != nil {
+		return err
+	}
 
-		return tag.Close()
-	})
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	// files which were not found during the walk have been
+	// either moved or deleted: forget about them
+	for path, file := range index.files {
+		if _, ok := files[path]; !ok && len(file.ID) > 0 && index.data[file.ID] == file {
+			delete(index.data, file.ID)
+		}
+	}
+	index.files = files
+	index.built = true
+	for _, file := range files {
+		if len(file.ID) > 0 {
+			file.Status = status
+			index.data[file.ID] = file
+		}
+	}
+	return nil
+}
+
+// scan returns the entry corresponding to the file at the given path,
+// parsing its tags only if neither known from a previous build nor
+// cached, or changed since
+func (index *Index) scan(path string, info fs.FileInfo) (*Entry, error) {
+	index.lock.RLock()
+	cached, ok := index.files[path]
+	if !ok {
+		cached, ok = index.cache[path]
+	}
+	index.lock.RUnlock()
+	// entries persisted before tracks got indexed too are parsed again
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) &&
+		(len(cached.ID) == 0 || cached.Track != nil) {
+		return cached, nil
+	}
+
+	tag, err := tag.Open(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		ID:          tag.SpotifyID(),
+		Path:        path,
+		UpstreamURL: tag.UpstreamURL(),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+	}
+	if len(entry.ID) > 0 {
+		entry.Track = trackFromTag(tag)
+	}
+	if ok {
+		entry.Synced = cached.Synced
+	}
+	return entry, tag.Close()
+}
+
+// trackFromTag rebuilds the track a file has been synchronized
+// from, out of its tags, as far as its path is concerned
+func trackFromTag(tag tag.Tag) *entity.Track {
+	return &entity.Track{
+		ID:          tag.SpotifyID(),
+		Title:       tag.Title(),
+		Artists:     tag.Artists(),
+		Album:       tag.Album(),
+		Duration:    util.ErrWrap(0)(strconv.Atoi(tag.Duration())),
+		Number:      util.ErrWrap(0)(strconv.Atoi(tag.TrackNumber())),
+		Disc:        util.ErrWrap(0)(strconv.Atoi(tag.DiscNumber())),
+		Year:        util.ErrWrap(0)(strconv.Atoi(tag.Year())),
+		UpstreamURL: tag.UpstreamURL(),
+		AddedAt:     util.ErrWrap(time.Time{})(time.Parse(time.RFC3339, tag.AddedDate())),
+	}
+}
+
+// Move makes the index aware that the track file
+// at the given path has been moved to the given one
+func (index *Index) Move(source, destination string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	for _, entries := range []map[string]*Entry{index.cache, index.files} {
+		if entry, ok := entries[source]; ok {
+			delete(entries, source)
+			entry.Path = destination
+			entries[destination] = entry
+		}
+	}
 }
 
 func (index *Index) Set(track *entity.Track, value int) {
+	key := keyFromTrack(track)
+
 	index.lock.Lock()
 	defer index.lock.Unlock()
-	index.data[keyFromTrack(track)] = value
+	entry, ok := index.data[key]
+	if !ok {
+		entry = &Entry{ID: track.ID}
+		index.data[key] = entry
+	}
+	entry.Status = value
+	if len(track.UpstreamURL) > 0 {
+		entry.UpstreamURL = track.UpstreamURL
+	}
+
+	if value != Installed {
+		return
+	}
+
+	// installed tracks are on disk: keep track of
+	// their file in order to persist it
+	path, err := filepath.Abs(track.Path().Final())
+	if err != nil {
+		return
+	}
+	entry.Path = path
+	entry.Synced = time.Now()
+	if info, err := os.Stat(path); err == nil {
+		entry.Size = info.Size()
+		entry.ModTime = info.ModTime()
+		index.files[path] = entry
+	}
 }
 
 func (index *Index) SetPath(path string, value int) {
+	key := keyFromPath(path)
+
 	index.lock.Lock()
 	defer index.lock.Unlock()
-	index.data[keyFromPath(path)] = value
+	entry, ok := index.data[key]
+	if !ok {
+		entry = &Entry{Path: path}
+		index.data[key] = entry
+	}
+	entry.Status = value
 }
 
 func (index *Index) Get(track *entity.Track) (int, bool) {
+	key := keyFromTrack(track)
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	entry, ok := index.data[key]
+	if !ok {
+		return 0, false
+	}
+	return entry.Status, true
+}
+
+// Entry returns a copy of the data indexed for the given track
+func (index *Index) Entry(track *entity.Track) (Entry, bool) {
+	key := keyFromTrack(track)
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	entry, ok := index.data[key]
+	if !ok {
+		return Entry{}, false
+	}
+	return *entry, true
+}
+
+// Tagged returns a copy of the entries of the track files found
+// on disk which carry a Spotify ID, sorted by path
+func (index *Index) Tagged() (entries []Entry) {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	for _, entry := range index.files {
+		if len(entry.ID) > 0 {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return
+}
+
+// Entries returns a copy of the data indexed for every track, sorted by ID
+func (index *Index) Entries() (entries []Entry) {
 	index.lock.RLock()
 	defer index.lock.RUnlock()
-	value, ok := index.data[keyFromTrack(track)]
-	return value, ok
+	for _, entry := range index.data {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return
+}
+
+// Remove forgets about the track file at the given path
+func (index *Index) Remove(path string) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	entry, ok := index.files[path]
+	if !ok {
+		return
+	}
+	delete(index.files, path)
+	if indexed, ok := index.data[entry.ID]; ok && indexed.Path == path {
+		delete(index.data, entry.ID)
+	}
+}
+
+// Untagged returns the paths of the track files found on disk
+// which do not carry any Spotify ID
+func (index *Index) Untagged() (paths []string) {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	for path, entry := range index.files {
+		if len(entry.ID) == 0 {
+			paths = append(paths, path)
+		}
+	}
+	return
+}
+
+// Built tells whether the index has been built already
+func (index *Index) Built() bool {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	return index.built
+}
+
+// Forget drops the tracks with the given status, so that
+// they are unknown to the index the next time they are looked up
+func (index *Index) Forget(status int) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	for key, entry := range index.data {
+		if entry.Status == status {
+			delete(index.data, key)
+		}
+	}
 }
 
 func (index *Index) Size(statuses ...int) (counter int) {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
 	if len(statuses) == 0 {
 		return len(index.data)
 	}
 
-	for _, value := range index.data {
+	for _, entry := range index.data {
 		for _, status := range statuses {
-			if value == status {
+			if entry.Status == status {
 				counter++
 				break
 			}
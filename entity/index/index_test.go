@@ -1,17 +1,21 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/bogem/id3v2/v2"
 	"github.com/streambinder/spotitube/entity"
 	"github.com/streambinder/spotitube/entity/id3"
 	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,6 +24,7 @@ type DirEntry struct {
 
 	name  string
 	isDir bool
+	err   error
 }
 
 func (e DirEntry) Name() string {
@@ -35,7 +40,28 @@ func (e DirEntry) Type() fs.FileMode {
 }
 
 func (e DirEntry) Info() (fs.FileInfo, error) {
-	return nil, nil
+	if e.err != nil {
+		return nil, e.err
+	}
+	return FileInfo{name: e.name}, nil
+}
+
+type FileInfo struct {
+	fs.FileInfo
+
+	name string
+}
+
+func (i FileInfo) Name() string {
+	return i.name
+}
+
+func (i FileInfo) Size() int64 {
+	return 1
+}
+
+func (i FileInfo) ModTime() time.Time {
+	return time.Unix(0, 0)
 }
 
 func BenchmarkIndex(b *testing.B) {
@@ -89,3 +115,370 @@ func TestBuildOpenFailure(t *testing.T) {
 	// testing
 	assert.EqualError(t, New().Build("path"), "ko")
 }
+
+func TestBuildCached(t *testing.T) {
+	var (
+		index  = New()
+		opened = 0
+		cache  = filepath.Join(t.TempDir(), Basename)
+	)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(filepath.WalkDir, func(_ string, f func(string, fs.DirEntry, error) error) error {
+			util.ErrSuppress(f("Artist - Title.mp3", DirEntry{name: "Artist - Title.mp3", isDir: false}, nil))
+			return f("Artist - Untagged.mp3", DirEntry{name: "Artist - Untagged.mp3", isDir: false}, nil)
+		}).
+		ApplyFunc(id3.Open, func(path string) (*id3.Tag, error) {
+			opened++
+			return &id3.Tag{Cache: map[string]string{}}, nil
+		}).
+		ApplyPrivateMethod(reflect.TypeOf(&id3.Tag{}), "userDefinedText", func(tag *id3.Tag, key string) string {
+			if key == "Spotify ID" && opened == 1 {
+				return "id"
+			}
+			return ""
+		}).
+		ApplyPrivateMethod(reflect.TypeOf(&id3v2.Tag{}), "Close", func() error {
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, index.Build("path"))
+	assert.Equal(t, 2, opened)
+	assert.Equal(t, 1, index.Size())
+	assert.Equal(t, []string{"Artist - Untagged.mp3"}, index.Untagged())
+	assert.Nil(t, index.Persist(cache))
+
+	cachedIndex := New()
+	assert.Nil(t, cachedIndex.Load(cache))
+	assert.Nil(t, cachedIndex.Build("path"))
+	assert.Equal(t, 2, opened)
+	entry, ok := cachedIndex.Entry(&entity.Track{ID: "id"})
+	assert.True(t, ok)
+	assert.Equal(t, "Artist - Title.mp3", entry.Path)
+	assert.Equal(t, Offline, entry.Status)
+}
+
+func TestBuildAgain(t *testing.T) {
+	var probed int
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+		probed++
+		return &cmd.Probe{Tags: map[string]string{"spotify_id": util.FileBaseStem(filepath.Base(path))}}, nil
+	}).Reset()
+
+	// testing
+	var (
+		index = New()
+		root  = t.TempDir()
+		kept  = filepath.Join(root, "kept.opus")
+		gone  = filepath.Join(root, "gone.opus")
+	)
+	assert.Nil(t, os.WriteFile(kept, []byte{}, 0o644))
+	assert.Nil(t, os.WriteFile(gone, []byte{}, 0o644))
+	assert.Nil(t, index.Build(root))
+	assert.Equal(t, 2, probed)
+	assert.Nil(t, os.Remove(gone))
+	assert.Nil(t, index.Build(root))
+	assert.Equal(t, 2, probed) // files known from the previous build are not parsed again
+	_, ok := index.Get(&entity.Track{ID: "kept"})
+	assert.True(t, ok)
+	_, ok = index.Get(&entity.Track{ID: "gone"})
+	assert.False(t, ok)
+}
+
+func TestBuildSkipDir(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func(root string, f func(string, fs.DirEntry, error) error) error {
+		assert.Nil(t, f(root, DirEntry{name: "path", isDir: true}, nil))
+		assert.Equal(t, fs.SkipDir, f(filepath.Join(root, "dir"), DirEntry{name: "dir", isDir: true}, nil))
+		return nil
+	}).Reset()
+
+	// testing
+	assert.Nil(t, New().Build("path"))
+}
+
+func TestBuildFormats(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+		return &cmd.Probe{Tags: map[string]string{
+			"spotify_id":  filepath.Ext(path),                  // Vorbis comments
+			"description": "spotify_id: " + filepath.Ext(path), // MP4 atoms
+		}}, nil
+	}).Reset()
+
+	// testing
+	root := t.TempDir()
+	for _, basename := range []string{"Artist - Title.opus", "Artist - Title.m4a", "Artist - Title.wav"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(root, basename), []byte{}, 0o644))
+	}
+	index := New()
+	assert.Nil(t, index.Build(root))
+	assert.Equal(t, 2, index.Size())
+	_, ok := index.Get(&entity.Track{ID: ".opus"})
+	assert.True(t, ok)
+	_, ok = index.Get(&entity.Track{ID: ".wav"})
+	assert.False(t, ok)
+}
+
+func TestBuildNested(t *testing.T) {
+	t.Cleanup(func() { util.ErrSuppress(entity.SetPathTemplate("")) })
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func(_ cmd.FFprobeCmd, _ context.Context, path string) (*cmd.Probe, error) {
+		return &cmd.Probe{Tags: map[string]string{"spotify_id": filepath.Base(path)}}, nil
+	}).Reset()
+
+	// testing
+	root := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "Artist", "Album"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Artist", "Album", "Title.opus"), []byte{}, 0o644))
+	flat := New()
+	assert.Nil(t, flat.Build(root))
+	assert.Equal(t, 0, flat.Size())
+	assert.Nil(t, entity.SetPathTemplate("{{index .Artists 0}}/{{.Album}}/{{.Title}}"))
+	index := New()
+	assert.Nil(t, index.Build(root))
+	entry, ok := index.Entry(&entity.Track{ID: "Title.opus"})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "Artist", "Album", "Title.opus"), entry.Path)
+	assert.Nil(t, entity.SetPathTemplate(""))
+	tree := New()
+	assert.Nil(t, tree.BuildTree(root))
+	assert.Equal(t, 1, tree.Size())
+}
+
+func TestBuildTrack(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+		return &cmd.Probe{Tags: map[string]string{
+			"spotify_id": "id",
+			"title":      "Title",
+			"artist":     "Artist",
+			"artists":    "Artist; Guest",
+			"track":      "1",
+			"disc":       "2",
+			"added_date": "2026-01-02T15:04:05Z",
+		}}, nil
+	}).Reset()
+
+	// testing
+	var (
+		root = t.TempDir()
+		path = filepath.Join(root, "Artist - Title (ft Guest).opus")
+	)
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	index := New()
+	// entries persisted before tracks got indexed are parsed again
+	index.cache[path] = &Entry{ID: "id", Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	assert.Nil(t, index.Build(root))
+	entries := index.Tagged()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, &entity.Track{
+		ID:      "id",
+		Title:   "Title",
+		Artists: []string{"Artist", "Guest"},
+		Number:  1,
+		Disc:    2,
+		AddedAt: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+	}, entries[0].Track)
+	assert.Equal(t, "Artist - Title (ft Guest)", util.FileBaseStem(filepath.Base(entries[0].Track.Path().Final())))
+}
+
+func TestMove(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func(_ string, f func(string, fs.DirEntry, error) error) error {
+		return f("Artist/Title.mp3", DirEntry{name: "Title.mp3", isDir: false}, nil)
+	}).Reset()
+
+	// testing
+	index := New()
+	index.cache["Artist - Title.mp3"] = &Entry{ID: "id", Path: "Artist - Title.mp3", Size: 1, ModTime: time.Unix(0, 0), Track: &entity.Track{ID: "id"}}
+	index.Move("Artist - Title.mp3", "Artist/Title.mp3")
+	index.Move("Artist - Other.mp3", "Artist/Other.mp3")
+	assert.Nil(t, index.Build("path"))
+	entry, ok := index.Entry(&entity.Track{ID: "id"})
+	assert.True(t, ok)
+	assert.Equal(t, "Artist/Title.mp3", entry.Path)
+	index.Move("Artist/Title.mp3", "Title.mp3")
+	entry, _ = index.Entry(&entity.Track{ID: "id"})
+	assert.Equal(t, "Title.mp3", entry.Path)
+}
+
+func TestTagged(t *testing.T) {
+	index := New()
+	index.files["b.mp3"] = &Entry{ID: "b", Path: "b.mp3"}
+	index.files["a.mp3"] = &Entry{ID: "a", Path: "a.mp3"}
+	index.files["c.mp3"] = &Entry{Path: "c.mp3"}
+	entries := index.Tagged()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "a.mp3", entries[0].Path)
+	assert.Equal(t, "b.mp3", entries[1].Path)
+}
+
+func TestEntries(t *testing.T) {
+	index := New()
+	index.data["b"] = &Entry{ID: "b", Status: Online}
+	index.data["a"] = &Entry{ID: "a", Path: "a.mp3", Status: Installed}
+	entries := index.Entries()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "a.mp3", entries[0].Path)
+	assert.Equal(t, Online, entries[1].Status)
+}
+
+func TestRemove(t *testing.T) {
+	index := New()
+	index.files["a.mp3"] = &Entry{ID: "a", Path: "a.mp3"}
+	index.files["b.mp3"] = &Entry{ID: "a", Path: "b.mp3"}
+	index.data["a"] = &Entry{ID: "a", Path: "a.mp3"}
+	index.Remove("b.mp3")
+	index.Remove("c.mp3")
+	_, ok := index.Entry(&entity.Track{ID: "a"})
+	assert.True(t, ok)
+	index.Remove("a.mp3")
+	_, ok = index.Entry(&entity.Track{ID: "a"})
+	assert.False(t, ok)
+	assert.Empty(t, index.Tagged())
+}
+
+func TestForget(t *testing.T) {
+	var (
+		index   = New()
+		online  = &entity.Track{ID: "online"}
+		offline = &entity.Track{ID: "offline"}
+	)
+	index.Set(online, Online)
+	index.Set(offline, Offline)
+	index.Forget(Online)
+	_, ok := index.Get(online)
+	assert.False(t, ok)
+	_, ok = index.Get(offline)
+	assert.True(t, ok)
+}
+
+func TestBuilt(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func() error {
+		return nil
+	}).Reset()
+
+	// testing
+	index := New()
+	assert.False(t, index.Built())
+	assert.Nil(t, index.Build("path"))
+	assert.True(t, index.Built())
+}
+
+func TestLoadNotExists(t *testing.T) {
+	assert.Nil(t, New().Load(filepath.Join(t.TempDir(), Basename)))
+}
+
+func TestLoadFailure(t *testing.T) {
+	assert.Error(t, New().Load(t.TempDir()))
+}
+
+func TestLoadUnmarshalFailure(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), Basename)
+	assert.Nil(t, os.WriteFile(cache, []byte("{"), 0o600))
+	assert.Error(t, New().Load(cache))
+}
+
+func TestPersistNotBuilt(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), Basename)
+	assert.Nil(t, New().Persist(cache))
+	assert.NoFileExists(t, cache)
+}
+
+func TestPersistFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func() error {
+		return nil
+	}).Reset()
+
+	// testing
+	var (
+		index = New()
+		root  = t.TempDir()
+		file  = filepath.Join(root, "file")
+	)
+	assert.Nil(t, index.Build("path"))
+	assert.Nil(t, os.WriteFile(file, []byte{}, 0o600))
+	assert.Error(t, index.Persist(filepath.Join(file, Basename)))
+	assert.Nil(t, os.Mkdir(filepath.Join(root, Basename+".tmp"), 0o755))
+	assert.Error(t, index.Persist(filepath.Join(root, Basename)))
+	// dates past year 9999 cannot be marshaled
+	index.files["path"] = &Entry{Synced: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	assert.Error(t, index.Persist(filepath.Join(root, Basename)))
+}
+
+func TestBuildInfoFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.WalkDir, func(root string, f func(string, fs.DirEntry, error) error) error {
+		return f(filepath.Join(root, "track.mp3"), DirEntry{name: "track.mp3", err: errors.New("ko")}, nil)
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, New().Build("path"), "ko")
+}
+
+func TestSetInstalled(t *testing.T) {
+	var (
+		index = New()
+		track = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}, UpstreamURL: "http://localhost/"}
+	)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.Stat, func() (fs.FileInfo, error) {
+		return FileInfo{name: "Artist - Title.mp3"}, nil
+	}).Reset()
+
+	// testing
+	index.Set(track, Installed)
+	entry, ok := index.Entry(track)
+	assert.True(t, ok)
+	assert.Equal(t, Installed, entry.Status)
+	assert.Equal(t, "http://localhost/", entry.UpstreamURL)
+	assert.Equal(t, int64(1), entry.Size)
+	assert.False(t, entry.Synced.IsZero())
+	assert.True(t, filepath.IsAbs(entry.Path))
+}
+
+func TestSetInstalledAbsFailure(t *testing.T) {
+	var (
+		index = New()
+		track = &entity.Track{ID: "id", Title: "Title", Artists: []string{"Artist"}}
+	)
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(filepath.Abs, func() (string, error) {
+		return "", errors.New("ko")
+	}).Reset()
+
+	// testing
+	index.Set(track, Installed)
+	entry, ok := index.Entry(track)
+	assert.True(t, ok)
+	assert.Empty(t, entry.Path)
+}
+
+func TestSetPath(t *testing.T) {
+	var (
+		index = New()
+		track = &entity.Track{Title: "Title", Artists: []string{"Artist"}}
+	)
+
+	// testing
+	index.SetPath(track.Path().Final(), Installed)
+	status, ok := index.Get(track)
+	assert.True(t, ok)
+	assert.Equal(t, Installed, status)
+	index.SetPath(track.Path().Final(), Flush)
+	status, ok = index.Get(track)
+	assert.True(t, ok)
+	assert.Equal(t, Flush, status)
+}
@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/streambinder/spotitube/entity"
+)
+
+const (
+	Decided   = iota // upstream URL has been chosen
+	Collected        // assets have been downloaded
+	Processed        // blob has been processed
+	Installed        // blob has been moved to its final destination
+)
+
+const Basename = "journal.jsonl"
+
+// Entry is a single line of the journal,
+// marking the stage reached by a track
+type Entry struct {
+	Track *entity.Track `json:"track"`
+	Stage int           `json:"stage"`
+}
+
+// Journal keeps track of the pipeline stage reached by each track,
+// appending every transition to a file so that an interrupted
+// synchronization can be resumed later on
+type Journal struct {
+	path string
+	data map[string]*Entry
+	lock sync.Mutex
+}
+
+func New(path string) *Journal {
+	return &Journal{
+		path,
+		make(map[string]*Entry),
+		sync.Mutex{},
+	}
+}
+
+// Load replays the journal file: for each track,
+// only the latest stage it reached is retained
+func (journal *Journal) Load() error {
+	file, err := os.Open(journal.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1<<20)
+	for scanner.Scan() {
+		var entry Entry
+		// an interruption may leave a truncated
+		// last line behind: skip it
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Track == nil {
+			continue
+		}
+		journal.data[entry.Track.ID] = &entry
+	}
+	return scanner.Err()
+}
+
+// Set records the stage reached by the given track
+func (journal *Journal) Set(track *entity.Track, stage int) error {
+	entry := &Entry{track, stage}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	journal.data[track.ID] = entry
+
+	if err := os.MkdirAll(filepath.Dir(journal.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Entries returns the latest entry for each journaled track
+func (journal *Journal) Entries() (entries []*Entry) {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	for _, entry := range journal.data {
+		entries = append(entries, entry)
+	}
+	return
+}
+
+// Clear forgets about every journaled track
+func (journal *Journal) Clear() error {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	journal.data = make(map[string]*Entry)
+	if err := os.Remove(journal.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var track = &entity.Track{ID: "123", Title: "Title", Artists: []string{"Artist"}}
+
+func BenchmarkJournal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestJournal(&testing.T{})
+	}
+}
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Basename)
+	journal := New(path)
+	assert.Nil(t, journal.Set(track, Decided))
+	assert.Nil(t, journal.Set(track, Collected))
+
+	// testing
+	replay := New(path)
+	assert.Nil(t, replay.Load())
+	entries := replay.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, track.ID, entries[0].Track.ID)
+	assert.Equal(t, Collected, entries[0].Stage)
+	assert.Nil(t, replay.Clear())
+	assert.Empty(t, replay.Entries())
+	assert.Nil(t, replay.Clear())
+	assert.NoFileExists(t, path)
+}
+
+func TestLoadNotExists(t *testing.T) {
+	journal := New(filepath.Join(t.TempDir(), Basename))
+	assert.Nil(t, journal.Load())
+	assert.Empty(t, journal.Entries())
+}
+
+func TestLoadTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Basename)
+	assert.Nil(t, New(path).Set(track, Processed))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	assert.Nil(t, err)
+	_, err = file.WriteString(`{"track":{"ID":"12`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	// testing
+	journal := New(path)
+	assert.Nil(t, journal.Load())
+	entries := journal.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, Processed, entries[0].Stage)
+}
+
+func TestLoadFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(os.OpenFile, func() (*os.File, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, New(Basename).Load(), "ko")
+}
+
+func TestSetMkdirFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(os.MkdirAll, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, New(Basename).Set(track, Decided), "ko")
+}
+
+func TestSetOpenFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(os.OpenFile, func() (*os.File, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, New(filepath.Join(t.TempDir(), Basename)).Set(track, Decided), "ko")
+}
+
+func TestClearFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(os.Remove, func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, New(Basename).Clear(), "ko")
+}
+
+func TestSetMarshalFailure(t *testing.T) {
+	// dates past year 9999 cannot be marshaled
+	track := &entity.Track{ID: "123", AddedAt: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	assert.Error(t, New(filepath.Join(t.TempDir(), Basename)).Set(track, Decided))
+}
+
+func TestSetWriteFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&os.File{}, "Write", func() (int, error) {
+			return 0, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, New(filepath.Join(t.TempDir(), Basename)).Set(track, Decided), "ko")
+}
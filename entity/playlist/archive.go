@@ -0,0 +1,79 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+// archivePattern matches the files of the archived snapshots of playlists,
+// whose name ends with the ISO week they have been taken in (e.g. 2026-w42)
+var archivePattern = regexp.MustCompile(`^(.+)-(\d{4})-w(\d{2})\.(m3u|pls)$`)
+
+// Archive returns the snapshot of the playlist as of the given time,
+// named after the ISO week it falls in (e.g. "Discover Weekly 2026-W42")
+func (entity Playlist) Archive(at time.Time) *Playlist {
+	year, week := at.ISOWeek()
+	entity.Name = fmt.Sprintf("%s %d-W%02d", entity.Name, year, week)
+	return &entity
+}
+
+// Archives returns the paths of the archived snapshots of any playlist
+// within the given directory
+func Archives(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && archivePattern.MatchString(entry.Name()) {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// Expired returns the paths of the archived snapshots of the playlist, within
+// the given directory, taken more than the given number of weeks before the
+// given time, the week of which counts as the first one
+func (entity Playlist) Expired(dir string, at time.Time, weeks int) ([]string, error) {
+	paths, err := Archives(dir)
+	if err != nil || weeks <= 0 {
+		return nil, err
+	}
+
+	var (
+		name    = slug.Make(entity.Name)
+		limit   = isoWeekStart(at.ISOWeek()).AddDate(0, 0, -7*weeks)
+		expired = []string{}
+	)
+	for _, path := range paths {
+		match := archivePattern.FindStringSubmatch(filepath.Base(path))
+		if match[1] != name {
+			continue
+		}
+
+		var (
+			snapshotYear, _ = strconv.Atoi(match[2])
+			snapshotWeek, _ = strconv.Atoi(match[3])
+		)
+		if !isoWeekStart(snapshotYear, snapshotWeek).After(limit) {
+			expired = append(expired, path)
+		}
+	}
+	return expired, nil
+}
+
+// isoWeekStart returns the monday of the given ISO week
+func isoWeekStart(year, week int) time.Time {
+	// the fourth of january always belongs to the first week
+	january := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+	return january.AddDate(0, 0, -(int(january.Weekday())+6)%7+7*(week-1))
+}
@@ -0,0 +1,92 @@
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkArchive(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestArchive(&testing.T{})
+	}
+}
+
+func TestArchive(t *testing.T) {
+	// testing
+	archive := testPlaylist.Archive(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "Playlist 2026-W42", archive.Name)
+	assert.Equal(t, testPlaylist.Tracks, archive.Tracks)
+	assert.Equal(t, "Playlist", testPlaylist.Name)
+	encoder, err := archive.Encoder("m3u")
+	assert.Nil(t, err)
+	assert.Equal(t, "playlist-2026-w42.m3u", encoder.Target())
+	assert.Equal(t, "Playlist 2020-W53", testPlaylist.Archive(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)).Name)
+}
+
+func TestArchives(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"playlist.m3u",
+		"playlist-2026-w40.m3u",
+		"other-playlist-2026-w42.pls",
+		"playlist-2026-w42.txt",
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	}
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "folder-2026-w42.m3u"), 0o755))
+
+	// testing
+	paths, err := Archives(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "other-playlist-2026-w42.pls"),
+		filepath.Join(dir, "playlist-2026-w40.m3u"),
+	}, paths)
+}
+
+func TestArchivesFailure(t *testing.T) {
+	// testing
+	assert.Error(t, util.ErrOnly(Archives(filepath.Join(t.TempDir(), "missing"))))
+}
+
+func TestExpired(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		now = time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC) // 2026-W02
+	)
+	for _, name := range []string{
+		"playlist.m3u",
+		"playlist-2026-w02.m3u",
+		"playlist-2026-w01.m3u",
+		"playlist-2025-w52.pls",
+		"playlist-2025-w51.m3u",
+		"other-playlist-2025-w01.m3u",
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	}
+
+	// testing
+	expired, err := testPlaylist.Expired(dir, now, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "playlist-2025-w51.m3u")}, expired)
+	expired, err = testPlaylist.Expired(dir, now, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "playlist-2025-w51.m3u"),
+		filepath.Join(dir, "playlist-2025-w52.pls"),
+		filepath.Join(dir, "playlist-2026-w01.m3u"),
+	}, expired)
+	expired, err = testPlaylist.Expired(dir, now, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, expired)
+}
+
+func TestExpiredFailure(t *testing.T) {
+	// testing
+	assert.Error(t, util.ErrOnly(testPlaylist.Expired(filepath.Join(t.TempDir(), "missing"), time.Now(), 1)))
+}
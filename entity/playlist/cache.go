@@ -0,0 +1,49 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CacheDirname is the folder, within the cache one, playlists
+// get persisted to, along with their tracks, as of their snapshot
+const CacheDirname = "playlists"
+
+// Load reads the playlist persisted to the given path, if any
+func Load(path string) (*Playlist, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var playlist Playlist
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// Persist writes the playlist, along with its tracks,
+// to the given path, so that a later run can Load it back
+func (playlist *Playlist) Persist(path string) error {
+	data, err := json.Marshal(playlist)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that an interruption
+	// never leaves a truncated playlist behind
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
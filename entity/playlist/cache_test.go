@@ -0,0 +1,70 @@
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkCache(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestPersist(&testing.T{})
+	}
+}
+
+func TestPersist(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), CacheDirname, "123.json")
+		playlist = &Playlist{ID: "123", Name: "Playlist", SnapshotID: "snapshot", Tracks: []*entity.Track{
+			{ID: "id", Title: "Title", Artists: []string{"Artist"}, Artwork: entity.Artwork{URL: "http://localhost/", Data: []byte{1}}},
+		}}
+	)
+
+	// testing
+	assert.Nil(t, playlist.Persist(path))
+	cached, err := Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot", cached.SnapshotID)
+	assert.Equal(t, "Title", cached.Tracks[0].Title)
+	assert.Equal(t, "http://localhost/", cached.Tracks[0].Artwork.URL)
+	assert.Nil(t, cached.Tracks[0].Artwork.Data)
+}
+
+func TestPersistFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o600))
+	assert.Error(t, (&Playlist{}).Persist(filepath.Join(path, "123.json")))
+}
+
+func TestPersistMarshalFailure(t *testing.T) {
+	// dates past year 9999 cannot be marshaled
+	playlist := &Playlist{Tracks: []*entity.Track{{AddedAt: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}}}
+	assert.Error(t, playlist.Persist(filepath.Join(t.TempDir(), "123.json")))
+}
+
+func TestPersistWriteFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "123.json")
+	assert.Nil(t, os.Mkdir(path+".tmp", 0o755))
+	assert.Error(t, (&Playlist{}).Persist(path))
+}
+
+func TestLoadNotExists(t *testing.T) {
+	playlist, err := Load(filepath.Join(t.TempDir(), "123.json"))
+	assert.Nil(t, err)
+	assert.Nil(t, playlist)
+}
+
+func TestLoadFailure(t *testing.T) {
+	assert.Error(t, util.ErrOnly(Load(t.TempDir())))
+}
+
+func TestLoadUnmarshalFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "123.json")
+	assert.Nil(t, os.WriteFile(path, []byte("{"), 0o600))
+	assert.Error(t, util.ErrOnly(Load(path)))
+}
@@ -8,4 +8,5 @@ type playlistEncoder interface {
 	init(string) error
 	Add(*entity.Track) error
 	Close() error
+	Target() string
 }
@@ -0,0 +1,49 @@
+package playlist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entries returns the paths of the tracks the M3U or PLS playlist file at the
+// given path points to: entries relative to the playlist file itself get
+// joined to its directory, just like Relocate expects them
+func Entries(path string) ([]string, error) {
+	var isEntry func(string) (string, bool)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u":
+		isEntry = func(line string) (string, bool) {
+			return line, len(line) > 0 && !strings.HasPrefix(line, "#")
+		}
+	case ".pls":
+		isEntry = func(line string) (string, bool) {
+			key, value, ok := strings.Cut(line, "=")
+			return value, ok && strings.HasPrefix(key, "File")
+		}
+	default:
+		return nil, errors.New("unsupported encoding")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dir     = filepath.Dir(path)
+		entries = []string{}
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		entry, ok := isEntry(strings.TrimSpace(line))
+		if !ok {
+			continue
+		}
+		if entry = filepath.FromSlash(entry); !filepath.IsAbs(entry) {
+			entry = filepath.Join(dir, entry)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
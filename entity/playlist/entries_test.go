@@ -0,0 +1,54 @@
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkEntries(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestEntriesM3U(&testing.T{})
+		TestEntriesPLS(&testing.T{})
+	}
+}
+
+func TestEntriesM3U(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.m3u")
+	assert.Nil(t, os.WriteFile(path, []byte("#EXTM3U\r\n#PLAYLIST:Playlist\r\n"+
+		"#EXTINF:60,Artist - Title\r\nArtist/Title.mp3\r\n\r\n/music/Untitled.opus\r\n"), 0o600))
+
+	// testing
+	entries, err := Entries(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(filepath.Dir(path), "Artist", "Title.mp3"),
+		filepath.FromSlash("/music/Untitled.opus"),
+	}, entries)
+}
+
+func TestEntriesPLS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.pls")
+	assert.Nil(t, os.WriteFile(path, []byte(`[Playlist]
+
+File1=Artist - Title.mp3
+Title1=Artist - Title
+Length1=60
+
+NumberOfEntries=1
+`), 0o600))
+
+	// testing
+	entries, err := Entries(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "Artist - Title.mp3")}, entries)
+}
+
+func TestEntriesFailure(t *testing.T) {
+	// testing
+	assert.EqualError(t, util.ErrOnly(Entries("playlist.txt")), "unsupported encoding")
+	assert.Error(t, util.ErrOnly(Entries(filepath.Join(t.TempDir(), "playlist.m3u"))))
+}
@@ -31,7 +31,7 @@ func (encoder *M3UEncoder) Add(track *entity.Track) error {
 			"#EXTINF:%s,%s\n%s\n",
 			strconv.Itoa(track.Duration),
 			util.FileBaseStem(filepath.Base(track.Path().Final())),
-			filepath.Base(track.Path().Final()),
+			filepath.ToSlash(track.Path().Final()), // relative to the playlist
 		),
 	)...)
 	return nil
@@ -40,3 +40,8 @@ func (encoder *M3UEncoder) Add(track *entity.Track) error {
 func (encoder *M3UEncoder) Close() error {
 	return os.WriteFile(encoder.target, encoder.data, 0o600)
 }
+
+// Target returns the path the playlist file gets written to
+func (encoder *M3UEncoder) Target() string {
+	return encoder.target
+}
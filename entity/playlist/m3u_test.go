@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +37,26 @@ func TestM3U(t *testing.T) {
 Artist - Title.mp3
 `, string(output))
 }
+
+func TestM3UNested(t *testing.T) {
+	var output []byte
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.WriteFile, func(_ string, data []byte, _ fs.FileMode) error {
+		output = data
+		return nil
+	}).Reset()
+	assert.Nil(t, entity.SetPathTemplate("{{index .Artists 0}}/{{.Title}}"))
+	t.Cleanup(func() { util.ErrSuppress(entity.SetPathTemplate("")) })
+
+	// testing
+	encoder := &M3UEncoder{}
+	assert.Nil(t, encoder.init(testPlaylist.Name))
+	assert.Nil(t, encoder.Add(testTrack))
+	assert.Nil(t, encoder.Close())
+	assert.Equal(t, `#EXTM3U
+#PLAYLIST:Playlist
+#EXTINF:0,Title
+Artist/Title.mp3
+`, string(output))
+}
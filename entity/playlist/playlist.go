@@ -12,6 +12,7 @@ type Playlist struct {
 	Name          string
 	Owner         string
 	Collaborative bool
+	SnapshotID    string // version identifier, changing whenever the playlist does
 	Tracks        []*entity.Track
 }
 
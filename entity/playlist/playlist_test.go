@@ -29,11 +29,15 @@ func BenchmarkPlaylist(b *testing.B) {
 }
 
 func TestEncoderM3U(t *testing.T) {
-	assert.Nil(t, util.ErrOnly(testPlaylist.Encoder("m3u")))
+	encoder, err := testPlaylist.Encoder("m3u")
+	assert.Nil(t, err)
+	assert.Equal(t, "playlist.m3u", encoder.Target())
 }
 
 func TestEncoderPLS(t *testing.T) {
-	assert.Nil(t, util.ErrOnly(testPlaylist.Encoder("pls")))
+	encoder, err := testPlaylist.Encoder("pls")
+	assert.Nil(t, err)
+	assert.Equal(t, "playlist.pls", encoder.Target())
 }
 
 func TestEncoderInitFailure(t *testing.T) {
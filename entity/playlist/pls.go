@@ -28,7 +28,7 @@ func (encoder *PLSEncoder) Add(track *entity.Track) error {
 	encoder.data = append(encoder.data, []byte(
 		fmt.Sprintf("File%d=%s\nTitle%d=%s\nLength%d=%d\n\n",
 			encoder.entries,
-			filepath.Base(track.Path().Final()),
+			filepath.ToSlash(track.Path().Final()), // relative to the playlist
 			encoder.entries,
 			util.FileBaseStem(filepath.Base(track.Path().Final())),
 			encoder.entries,
@@ -44,3 +44,8 @@ func (encoder *PLSEncoder) Close() error {
 	)...)
 	return os.WriteFile(encoder.target, encoder.data, 0o600)
 }
+
+// Target returns the path the playlist file gets written to
+func (encoder *PLSEncoder) Target() string {
+	return encoder.target
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +40,29 @@ Length1=0
 NumberOfEntries=1
 `, string(output))
 }
+
+func TestPLSNested(t *testing.T) {
+	var output []byte
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.WriteFile, func(_ string, data []byte, _ fs.FileMode) error {
+		output = data
+		return nil
+	}).Reset()
+	assert.Nil(t, entity.SetPathTemplate("{{index .Artists 0}}/{{.Title}}"))
+	t.Cleanup(func() { util.ErrSuppress(entity.SetPathTemplate("")) })
+
+	// testing
+	encoder := &PLSEncoder{}
+	assert.Nil(t, encoder.init(testPlaylist.Name))
+	assert.Nil(t, encoder.Add(testTrack))
+	assert.Nil(t, encoder.Close())
+	assert.Equal(t, `[Playlist]
+
+File1=Artist/Title.mp3
+Title1=Title
+Length1=0
+
+NumberOfEntries=1
+`, string(output))
+}
@@ -0,0 +1,114 @@
+package playlist
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/streambinder/spotitube/util"
+)
+
+// Relocate rewrites the entries of the M3U or PLS playlist file at the given path
+// which point to any of the moved tracks, given as a map from the old path of each
+// track to the new one: paths are expected to be either relative to the working
+// directory or absolute, just like the playlist one, and the number of entries
+// which have been rewritten is returned
+func Relocate(path string, moves map[string]string) (int, error) {
+	var relocate func([]string, map[string]string) ([]string, int)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u":
+		relocate = relocateM3U
+	case ".pls":
+		relocate = relocatePLS
+	default:
+		return 0, errors.New("unsupported encoding")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// entries are relative to the playlist file itself
+	var (
+		dir           = filepath.Dir(path)
+		relativeMoves = make(map[string]string)
+	)
+	for source, destination := range moves {
+		relativeMoves[filepath.ToSlash(util.ErrWrap(source)(filepath.Rel(dir, source)))] =
+			filepath.ToSlash(util.ErrWrap(destination)(filepath.Rel(dir, destination)))
+	}
+
+	lines, counter := relocate(strings.Split(string(bytes.TrimSuffix(data, []byte("\n"))), "\n"), relativeMoves)
+	if counter == 0 {
+		return 0, nil
+	}
+	return counter, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
+
+// relocatedTitle returns the title an entry would have
+// been given if the track was already at the new path
+func relocatedTitle(title, source, destination string) string {
+	if title == util.FileBaseStem(filepath.Base(source)) {
+		return util.FileBaseStem(filepath.Base(destination))
+	}
+	return title
+}
+
+func relocateM3U(lines []string, moves map[string]string) ([]string, int) {
+	counter := 0
+	for i, line := range lines {
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		destination, ok := moves[filepath.ToSlash(filepath.Clean(line))]
+		if !ok {
+			continue
+		}
+		lines[i] = destination
+		counter++
+
+		// #EXTINF:duration,title
+		if i > 0 && strings.HasPrefix(lines[i-1], "#EXTINF:") {
+			if info, title, ok := strings.Cut(lines[i-1], ","); ok {
+				lines[i-1] = info + "," + relocatedTitle(title, line, destination)
+			}
+		}
+	}
+	return lines, counter
+}
+
+func relocatePLS(lines []string, moves map[string]string) ([]string, int) {
+	var (
+		counter = 0
+		titles  = make(map[string][2]string) // entry number to source and destination
+	)
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(key, "File") {
+			continue
+		}
+
+		destination, ok := moves[filepath.ToSlash(filepath.Clean(value))]
+		if !ok {
+			continue
+		}
+		lines[i] = key + "=" + destination
+		titles[strings.TrimPrefix(key, "File")] = [2]string{value, destination}
+		counter++
+	}
+
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(key, "Title") {
+			continue
+		}
+		if paths, ok := titles[strings.TrimPrefix(key, "Title")]; ok {
+			lines[i] = key + "=" + relocatedTitle(value, paths[0], paths[1])
+		}
+	}
+	return lines, counter
+}
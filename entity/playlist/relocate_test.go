@@ -0,0 +1,122 @@
+package playlist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMoves(root string) map[string]string {
+	return map[string]string{
+		filepath.Join(root, "Artist - Title.mp3"):     filepath.Join(root, "Artist", "Album", "01 Title.mp3"),
+		filepath.Join(root, "Artist - Untitled.opus"): filepath.Join(root, "Artist", "Untitled.opus"),
+	}
+}
+
+func BenchmarkRelocate(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestRelocateM3U(&testing.T{})
+		TestRelocatePLS(&testing.T{})
+	}
+}
+
+func TestRelocateM3U(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.m3u")
+	assert.Nil(t, os.WriteFile(path, []byte(`#EXTM3U
+#PLAYLIST:Playlist
+#EXTINF:60,Artist - Title
+Artist - Title.mp3
+#EXTINF:60,Custom title
+Artist - Untitled.opus
+Artist - Other.mp3
+`), 0o600))
+
+	// testing
+	counter, err := Relocate(path, testMoves(filepath.Dir(path)))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, counter)
+	assert.Equal(t, `#EXTM3U
+#PLAYLIST:Playlist
+#EXTINF:60,01 Title
+Artist/Album/01 Title.mp3
+#EXTINF:60,Custom title
+Artist/Untitled.opus
+Artist - Other.mp3
+`, string(util.ErrWrap([]byte{})(os.ReadFile(path))))
+}
+
+func TestRelocatePLS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.pls")
+	assert.Nil(t, os.WriteFile(path, []byte(`[Playlist]
+
+File1=Artist - Title.mp3
+Title1=Artist - Title
+Length1=60
+
+File2=Artist - Other.mp3
+Title2=Artist - Other
+Length2=60
+
+NumberOfEntries=2
+`), 0o600))
+
+	// testing
+	counter, err := Relocate(path, testMoves(filepath.Dir(path)))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, counter)
+	assert.Equal(t, `[Playlist]
+
+File1=Artist/Album/01 Title.mp3
+Title1=01 Title
+Length1=60
+
+File2=Artist - Other.mp3
+Title2=Artist - Other
+Length2=60
+
+NumberOfEntries=2
+`, string(util.ErrWrap([]byte{})(os.ReadFile(path))))
+}
+
+func TestRelocateNested(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "playlists"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "playlists", "playlist.m3u"), []byte("../Artist - Title.mp3\n"), 0o600))
+
+	// testing
+	assert.Equal(t, 1, util.ErrWrap(0)(Relocate(filepath.Join(root, "playlists", "playlist.m3u"), testMoves(root))))
+	assert.Equal(t, "../Artist/Album/01 Title.mp3\n",
+		string(util.ErrWrap([]byte{})(os.ReadFile(filepath.Join(root, "playlists", "playlist.m3u")))))
+}
+
+func TestRelocateNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.m3u")
+	assert.Nil(t, os.WriteFile(path, []byte("Artist - Other.mp3\n"), 0o600))
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.WriteFile, func() error {
+		panic("nothing to be written")
+	}).Reset()
+
+	// testing
+	assert.Equal(t, 0, util.ErrWrap(-1)(Relocate(path, testMoves(filepath.Dir(path)))))
+}
+
+func TestRelocateUnsupported(t *testing.T) {
+	assert.EqualError(t, util.ErrOnly(Relocate("playlist.txt", testMoves(""))), "unsupported encoding")
+}
+
+func TestRelocateReadFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.ReadFile, func() ([]byte, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(Relocate("playlist.pls", testMoves(""))), "ko")
+}
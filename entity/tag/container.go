@@ -0,0 +1,165 @@
+package tag
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
+)
+
+const (
+	fieldTitle       = "title"
+	fieldArtist      = "artist"
+	fieldAlbum       = "album"
+	fieldYear        = "date"
+	fieldTrackNumber = "track"
+	fieldDiscNumber  = "disc"
+	fieldLyrics      = "lyrics"
+)
+
+// container holds the metadata of those formats which ffmpeg
+// can read and write as plain key-value pairs: the picture
+// is only extracted from the file when needed
+type container struct {
+	path    string
+	fields  map[string]string
+	picture []byte
+	loaded  bool // whether picture has been either extracted or set
+}
+
+func openContainer(path string, parse bool) (*container, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	if !parse {
+		return &container{path, make(map[string]string), nil, true}, nil
+	}
+
+	probe, err := cmd.FFprobe().Probe(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+	return &container{path, probe.Tags, nil, false}, nil
+}
+
+func (tag *container) get(key string) string {
+	return tag.fields[strings.ToLower(key)]
+}
+
+func (tag *container) set(key, value string) {
+	tag.fields[strings.ToLower(key)] = value
+}
+
+func (tag *container) SetTitle(title string) {
+	tag.set(fieldTitle, title)
+}
+
+func (tag *container) Title() string {
+	return tag.get(fieldTitle)
+}
+
+func (tag *container) SetArtist(artist string) {
+	tag.set(fieldArtist, artist)
+}
+
+func (tag *container) Artist() string {
+	return tag.get(fieldArtist)
+}
+
+func (tag *container) SetAlbum(album string) {
+	tag.set(fieldAlbum, album)
+}
+
+func (tag *container) Album() string {
+	return tag.get(fieldAlbum)
+}
+
+func (tag *container) SetYear(year string) {
+	tag.set(fieldYear, year)
+}
+
+func (tag *container) Year() string {
+	return tag.get(fieldYear)
+}
+
+func (tag *container) SetTrackNumber(number string) {
+	tag.set(fieldTrackNumber, number)
+}
+
+func (tag *container) TrackNumber() string {
+	return tag.get(fieldTrackNumber)
+}
+
+func (tag *container) SetDiscNumber(number string) {
+	tag.set(fieldDiscNumber, number)
+}
+
+func (tag *container) DiscNumber() string {
+	return tag.get(fieldDiscNumber)
+}
+
+func (tag *container) SetUnsynchronizedLyrics(_, lyrics string) {
+	tag.set(fieldLyrics, lyrics)
+}
+
+func (tag *container) UnsynchronizedLyrics() string {
+	return tag.get(fieldLyrics)
+}
+
+func (tag *container) SetAttachedPicture(picture []byte) {
+	tag.picture = picture
+	tag.loaded = true
+}
+
+func (tag *container) AttachedPicture() (string, []byte) {
+	return tag.attachedPicture(context.Background())
+}
+
+// attachedPicture extracts the picture out of the
+// file, unless already done, as long as ctx is not done
+func (tag *container) attachedPicture(ctx context.Context) (string, []byte) {
+	if !tag.loaded {
+		tag.picture = util.ErrWrap([]byte{})(cmd.FFmpeg().Picture(ctx, tag.path))
+		tag.loaded = true
+	}
+
+	if len(tag.picture) == 0 {
+		return "", []byte{}
+	}
+	return "image/jpeg", tag.picture
+}
+
+// save rewrites the file metadata, attaching
+// the picture as a stream if a cover is given
+func (tag *container) save(ctx context.Context, fields map[string]string, cover bool) error {
+	if !cover {
+		return cmd.FFmpeg().Metadata(ctx, tag.path, fields, "")
+	}
+
+	_, picture := tag.attachedPicture(ctx)
+	if len(picture) == 0 {
+		return cmd.FFmpeg().Metadata(ctx, tag.path, fields, "")
+	}
+
+	file, err := os.CreateTemp("", "cover*.jpg")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(picture); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return cmd.FFmpeg().Metadata(ctx, tag.path, fields, file.Name())
+}
+
+func (tag *container) Close() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package tag
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const fieldDescription = "description"
+
+var mp4CustomFields = []string{
+	fieldSpotifyID,
+	fieldArtworkURL,
+	fieldDuration,
+	fieldUpstreamURL,
+	fieldAddedDate,
+	fieldArtists,
+}
+
+// MP4 is the tag of M4A files, made of iTunes atoms: as ffmpeg
+// cannot write freeform atoms, custom fields are kept as "key: value"
+// lines appended to the description atom, whose other lines are kept
+type MP4 struct {
+	*container
+	custom      map[string]string
+	description []string // description lines other than custom fields
+}
+
+func openMP4(path string, parse bool) (*MP4, error) {
+	container, err := openContainer(path, parse)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &MP4{container, make(map[string]string), nil}
+	// freeform atoms (----:com.apple.iTunes:<name>) written by
+	// other tools are read by name, the description lines prevail
+	for _, key := range mp4CustomFields {
+		if value := container.get(key); len(value) > 0 {
+			tag.custom[key] = value
+		}
+	}
+	if description := container.get(fieldDescription); len(description) > 0 {
+		for _, line := range strings.Split(description, "\n") {
+			if key, value, ok := strings.Cut(line, ": "); ok && slices.Contains(mp4CustomFields, key) {
+				tag.custom[key] = value
+			} else {
+				tag.description = append(tag.description, line)
+			}
+		}
+	}
+	return tag, nil
+}
+
+func (tag *MP4) SetSpotifyID(id string) {
+	tag.custom[fieldSpotifyID] = id
+}
+
+func (tag *MP4) SpotifyID() string {
+	return tag.custom[fieldSpotifyID]
+}
+
+func (tag *MP4) SetArtworkURL(url string) {
+	tag.custom[fieldArtworkURL] = url
+}
+
+func (tag *MP4) ArtworkURL() string {
+	return tag.custom[fieldArtworkURL]
+}
+
+func (tag *MP4) SetDuration(duration string) {
+	tag.custom[fieldDuration] = duration
+}
+
+func (tag *MP4) Duration() string {
+	return tag.custom[fieldDuration]
+}
+
+func (tag *MP4) SetUpstreamURL(url string) {
+	tag.custom[fieldUpstreamURL] = url
+}
+
+func (tag *MP4) UpstreamURL() string {
+	return tag.custom[fieldUpstreamURL]
+}
+
+func (tag *MP4) SetAddedDate(date string) {
+	tag.custom[fieldAddedDate] = date
+}
+
+func (tag *MP4) AddedDate() string {
+	return tag.custom[fieldAddedDate]
+}
+
+func (tag *MP4) SetArtists(artists []string) {
+	tag.custom[fieldArtists] = strings.Join(artists, artistsSeparator)
+}
+
+func (tag *MP4) Artists() []string {
+	return splitArtists(tag.custom[fieldArtists])
+}
+
+func (tag *MP4) Save(ctx context.Context) error {
+	var custom []string
+	for key, value := range tag.custom {
+		custom = append(custom, key+": "+value)
+	}
+	sort.Strings(custom)
+	tag.set(fieldDescription, strings.Join(append(slices.Clone(tag.description), custom...), "\n"))
+	return tag.save(ctx, tag.fields, true)
+}
@@ -0,0 +1,116 @@
+package tag
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkMP4(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestMP4(&testing.T{})
+	}
+}
+
+func TestMP4(t *testing.T) {
+	var (
+		metadata map[string]string
+		cover    string
+	)
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", stubProbe(map[string]string{
+			"title":       "Title",
+			"description": "spotify_id: Spotify ID\nduration: 60\nnot a field",
+		})).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, _ string, fields map[string]string, coverPath string) error {
+			// fields may live on the caller stack
+			metadata, cover = maps.Clone(fields), coverPath
+			return nil
+		}).
+		Reset()
+
+	// testing
+	tag, err := openMP4(touch(t, "track.m4a"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Title", tag.Title())
+	assert.Equal(t, "Spotify ID", tag.SpotifyID())
+	assert.Equal(t, "60", tag.Duration())
+	assert.Empty(t, tag.ArtworkURL())
+	assert.Empty(t, tag.UpstreamURL())
+	assert.Empty(t, tag.AddedDate())
+	assert.Empty(t, tag.Artists())
+
+	tag.SetArtworkURL("Artwork URL")
+	tag.SetUpstreamURL("Upstream URL")
+	tag.SetAddedDate("2026-01-02T15:04:05Z")
+	tag.SetArtists([]string{"Artist", "Guest"})
+	tag.SetDuration("61")
+	tag.SetSpotifyID("Spotify ID")
+	tag.SetAttachedPicture([]byte("picture"))
+	assert.Equal(t, "Artwork URL", tag.ArtworkURL())
+	assert.Equal(t, "Upstream URL", tag.UpstreamURL())
+	assert.Equal(t, "2026-01-02T15:04:05Z", tag.AddedDate())
+	assert.Equal(t, []string{"Artist", "Guest"}, tag.Artists())
+	assert.Nil(t, tag.Save(context.Background()))
+	assert.NotEmpty(t, cover)
+	assert.Equal(t, "Title", metadata["title"])
+	assert.Equal(t, "not a field\nadded_date: 2026-01-02T15:04:05Z\nartists: Artist; Guest\nartwork_url: Artwork URL\nduration: 61\nspotify_id: Spotify ID\nupstream_url: Upstream URL", metadata["description"])
+}
+
+func TestMP4Description(t *testing.T) {
+	var (
+		description = "Recorded live\n\nat: the venue"
+		probed      = map[string]string{"description": description + "\nspotify_id: Old ID"}
+		metadata    map[string]string
+	)
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+			return &cmd.Probe{Tags: probed}, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, _ string, fields map[string]string, _ string) error {
+			metadata = maps.Clone(fields)
+			return nil
+		}).
+		Reset()
+
+	// testing
+	path := touch(t, "track.m4a")
+	tag, err := openMP4(path, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Old ID", tag.SpotifyID())
+	tag.SetSpotifyID("Spotify ID")
+	assert.Nil(t, tag.Save(context.Background()))
+	assert.Equal(t, description+"\nspotify_id: Spotify ID", metadata["description"])
+
+	// what gets written is read back the same way
+	probed = metadata
+	tag, err = openMP4(path, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Spotify ID", tag.SpotifyID())
+	assert.Nil(t, tag.Save(context.Background()))
+	assert.Equal(t, description+"\nspotify_id: Spotify ID", metadata["description"])
+}
+
+func TestMP4Freeform(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", stubProbe(map[string]string{
+		"spotify_id":  "Freeform ID",
+		"duration":    "60",
+		"description": "duration: 61",
+	})).Reset()
+
+	// testing
+	tag, err := openMP4(touch(t, "track.m4a"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Freeform ID", tag.SpotifyID())
+	assert.Equal(t, "61", tag.Duration())
+}
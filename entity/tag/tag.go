@@ -0,0 +1,63 @@
+package tag
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/streambinder/spotitube/entity/id3"
+)
+
+// Tag is the set of metadata carried by a track
+// file, regardless of the format it is encoded to
+type Tag interface {
+	SetTitle(string)
+	Title() string
+	SetArtist(string)
+	Artist() string
+	SetArtists([]string)
+	Artists() []string
+	SetAlbum(string)
+	Album() string
+	SetYear(string)
+	Year() string
+	SetTrackNumber(string)
+	TrackNumber() string
+	SetDiscNumber(string)
+	DiscNumber() string
+	SetSpotifyID(string)
+	SpotifyID() string
+	SetArtworkURL(string)
+	ArtworkURL() string
+	SetDuration(string)
+	Duration() string
+	SetUpstreamURL(string)
+	UpstreamURL() string
+	SetAddedDate(string)
+	AddedDate() string
+	SetAttachedPicture([]byte)
+	AttachedPicture() (string, []byte)
+	SetUnsynchronizedLyrics(string, string)
+	UnsynchronizedLyrics() string
+	Save(context.Context) error
+	Close() error
+}
+
+// Open returns the tag of the track file at the given path according
+// to the format its extension tells: unless parsed, it starts off empty
+func Open(path string, parse bool) (Tag, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".opus", ".ogg", ".flac":
+		return openVorbis(path, parse)
+	case ".m4a":
+		return openMP4(path, parse)
+	}
+
+	// anything else is handled as MP3
+	tag, err := id3.Open(path, id3v2.Options{Parse: parse})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
@@ -0,0 +1,104 @@
+package tag
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/bogem/id3v2/v2"
+	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/util"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func touch(t *testing.T, basename string) string {
+	path := filepath.Join(t.TempDir(), basename)
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	return path
+}
+
+func stubProbe(tags map[string]string) func() (*cmd.Probe, error) {
+	return func() (*cmd.Probe, error) {
+		return &cmd.Probe{Tags: tags}, nil
+	}
+}
+
+func BenchmarkTag(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestOpen(&testing.T{})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+			return id3v2.NewEmptyTag(), nil
+		}).
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", stubProbe(map[string]string{"title": "Title"})).
+		Reset()
+
+	// testing
+	for basename, expected := range map[string]interface{}{
+		"track.mp3":  &id3.Tag{},
+		"track.opus": &Vorbis{},
+		"track.ogg":  &Vorbis{},
+		"track.FLAC": &Vorbis{},
+		"track.m4a":  &MP4{},
+		"track":      &id3.Tag{},
+	} {
+		tag, err := Open(touch(t, basename), true)
+		assert.Nil(t, err)
+		assert.IsType(t, expected, tag)
+		if _, ok := expected.(*id3.Tag); !ok {
+			assert.Equal(t, "Title", tag.Title())
+		}
+	}
+}
+
+func TestOpenEmpty(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+		panic("unparsed tags must not be probed")
+	}).Reset()
+
+	// testing
+	tag, err := Open(touch(t, "track.opus"), false)
+	assert.Nil(t, err)
+	assert.Empty(t, tag.Title())
+	mimeType, picture := tag.AttachedPicture()
+	assert.Empty(t, mimeType)
+	assert.Empty(t, picture)
+	assert.Nil(t, tag.Close())
+}
+
+func TestOpenFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	tag, err := Open(touch(t, "track.mp3"), true)
+	assert.EqualError(t, err, "ko")
+	assert.Nil(t, tag)
+}
+
+func TestOpenNotFound(t *testing.T) {
+	assert.Error(t, util.ErrOnly(Open(filepath.Join(t.TempDir(), "track.opus"), false)))
+	assert.Error(t, util.ErrOnly(Open(filepath.Join(t.TempDir(), "track.m4a"), false)))
+}
+
+func TestOpenProbeFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(Open(touch(t, "track.opus"), true)), "ko")
+	assert.EqualError(t, util.ErrOnly(Open(touch(t, "track.m4a"), true)), "ko")
+}
@@ -0,0 +1,132 @@
+package tag
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+
+	"github.com/streambinder/spotitube/util"
+)
+
+const (
+	fieldSpotifyID     = "spotify_id"
+	fieldArtworkURL    = "artwork_url"
+	fieldDuration      = "duration"
+	fieldUpstreamURL   = "upstream_url"
+	fieldAddedDate     = "added_date"
+	fieldArtists       = "artists"
+	artistsSeparator   = "; "
+	fieldBlockPicture  = "metadata_block_picture"
+	pictureFrontCover  = 3
+	pictureDescription = "Front cover"
+)
+
+// Vorbis is the tag of Opus, Ogg and FLAC files,
+// made of Vorbis comments
+type Vorbis struct {
+	*container
+}
+
+func openVorbis(path string, parse bool) (*Vorbis, error) {
+	container, err := openContainer(path, parse)
+	if err != nil {
+		return nil, err
+	}
+	return &Vorbis{container}, nil
+}
+
+func (tag *Vorbis) SetSpotifyID(id string) {
+	tag.set(fieldSpotifyID, id)
+}
+
+func (tag *Vorbis) SpotifyID() string {
+	return tag.get(fieldSpotifyID)
+}
+
+func (tag *Vorbis) SetArtworkURL(url string) {
+	tag.set(fieldArtworkURL, url)
+}
+
+func (tag *Vorbis) ArtworkURL() string {
+	return tag.get(fieldArtworkURL)
+}
+
+func (tag *Vorbis) SetDuration(duration string) {
+	tag.set(fieldDuration, duration)
+}
+
+func (tag *Vorbis) Duration() string {
+	return tag.get(fieldDuration)
+}
+
+func (tag *Vorbis) SetUpstreamURL(url string) {
+	tag.set(fieldUpstreamURL, url)
+}
+
+func (tag *Vorbis) UpstreamURL() string {
+	return tag.get(fieldUpstreamURL)
+}
+
+func (tag *Vorbis) SetAddedDate(date string) {
+	tag.set(fieldAddedDate, date)
+}
+
+func (tag *Vorbis) AddedDate() string {
+	return tag.get(fieldAddedDate)
+}
+
+func (tag *Vorbis) SetArtists(artists []string) {
+	tag.set(fieldArtists, strings.Join(artists, artistsSeparator))
+}
+
+func (tag *Vorbis) Artists() []string {
+	return splitArtists(tag.get(fieldArtists))
+}
+
+func (tag *Vorbis) Save(ctx context.Context) error {
+	// FLAC has its own picture block, which ffmpeg
+	// fills in with the cover stream it is given
+	if strings.EqualFold(filepath.Ext(tag.path), ".flac") {
+		return tag.save(ctx, tag.fields, true)
+	}
+
+	// Ogg, instead, only knows about comments:
+	// the picture gets encoded as one of them
+	fields := make(map[string]string, len(tag.fields)+1)
+	for key, value := range tag.fields {
+		fields[key] = value
+	}
+	if mimeType, picture := tag.attachedPicture(ctx); len(picture) > 0 {
+		fields[fieldBlockPicture] = base64.StdEncoding.EncodeToString(pictureBlock(mimeType, picture))
+	}
+	return tag.save(ctx, fields, false)
+}
+
+// splitArtists returns the artists joined into the given
+// field value, none if the field was never written
+func splitArtists(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, artistsSeparator)
+}
+
+// pictureBlock encodes the given picture as a FLAC
+// picture block, i.e. the way Vorbis comments carry it
+func pictureBlock(mimeType string, picture []byte) []byte {
+	var block bytes.Buffer
+	for _, field := range []interface{}{
+		uint32(pictureFrontCover),
+		uint32(len(mimeType)), []byte(mimeType),
+		uint32(len(pictureDescription)), []byte(pictureDescription),
+		uint32(0), uint32(0), uint32(0), uint32(0), // width, height, depth and colors are optional
+		uint32(len(picture)), picture,
+	} {
+		// writing to a buffer never fails
+		util.ErrSuppress(binary.Write(&block, binary.BigEndian, field))
+	}
+	return block.Bytes()
+}
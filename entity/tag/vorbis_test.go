@@ -0,0 +1,183 @@
+package tag
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkVorbis(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestVorbis(&testing.T{})
+	}
+}
+
+func TestVorbis(t *testing.T) {
+	var (
+		metadata map[string]string
+		cover    string
+	)
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, _ string, fields map[string]string, coverPath string) error {
+		// fields may live on the caller stack
+		metadata, cover = maps.Clone(fields), coverPath
+		return nil
+	}).Reset()
+
+	// testing
+	tag, err := openVorbis(touch(t, "track.opus"), false)
+	assert.Nil(t, err)
+	tag.SetTitle("Title")
+	tag.SetArtist("Artist")
+	tag.SetAlbum("Album")
+	tag.SetYear("1970")
+	tag.SetTrackNumber("1")
+	tag.SetDiscNumber("2")
+	tag.SetArtists([]string{"Artist", "Guest"})
+	tag.SetSpotifyID("Spotify ID")
+	tag.SetArtworkURL("Artwork URL")
+	tag.SetDuration("60")
+	tag.SetUpstreamURL("Upstream URL")
+	tag.SetAddedDate("2026-01-02T15:04:05Z")
+	tag.SetUnsynchronizedLyrics("Title", "lyrics")
+	tag.SetAttachedPicture([]byte("picture"))
+	assert.Equal(t, "Title", tag.Title())
+	assert.Equal(t, "Artist", tag.Artist())
+	assert.Equal(t, "Album", tag.Album())
+	assert.Equal(t, "1970", tag.Year())
+	assert.Equal(t, "1", tag.TrackNumber())
+	assert.Equal(t, "2", tag.DiscNumber())
+	assert.Equal(t, []string{"Artist", "Guest"}, tag.Artists())
+	assert.Equal(t, "Spotify ID", tag.SpotifyID())
+	assert.Equal(t, "Artwork URL", tag.ArtworkURL())
+	assert.Equal(t, "60", tag.Duration())
+	assert.Equal(t, "Upstream URL", tag.UpstreamURL())
+	assert.Equal(t, "2026-01-02T15:04:05Z", tag.AddedDate())
+	assert.Equal(t, "lyrics", tag.UnsynchronizedLyrics())
+	mimeType, picture := tag.AttachedPicture()
+	assert.Equal(t, "image/jpeg", mimeType)
+	assert.Equal(t, []byte("picture"), picture)
+
+	assert.Nil(t, tag.Save(context.Background()))
+	assert.Empty(t, cover)
+	assert.Equal(t, "Spotify ID", metadata["spotify_id"])
+	assert.Equal(t, "Upstream URL", metadata["upstream_url"])
+	assert.Equal(t, "2026-01-02T15:04:05Z", metadata["added_date"])
+	assert.Equal(t, "Artist; Guest", metadata["artists"])
+	assert.Equal(t, "2", metadata["disc"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString(pictureBlock("image/jpeg", []byte("picture"))), metadata["metadata_block_picture"])
+	assert.NotContains(t, tag.fields, "metadata_block_picture")
+}
+
+func TestVorbisFLAC(t *testing.T) {
+	var (
+		cover       []byte
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", stubProbe(map[string]string{"spotify_id": "Spotify ID"})).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func(_ cmd.FFmpegCmd, pictureCtx context.Context, _ string) ([]byte, error) {
+			// ffmpeg gets killed along with the save
+			assert.Equal(t, ctx, pictureCtx)
+			return []byte("picture"), nil
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, metadataCtx context.Context, _ string, fields map[string]string, coverPath string) error {
+			assert.Equal(t, ctx, metadataCtx)
+			assert.NotContains(t, fields, "metadata_block_picture")
+			cover, _ = os.ReadFile(coverPath)
+			return nil
+		}).
+		Reset()
+
+	// testing
+	tag, err := openVorbis(touch(t, "track.flac"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Spotify ID", tag.SpotifyID())
+	assert.Nil(t, tag.Save(ctx))
+	assert.Equal(t, []byte("picture"), cover)
+}
+
+func TestVorbisNoPicture(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(cmd.FFprobeCmd{}, "Probe", stubProbe(map[string]string{})).
+		ApplyMethod(cmd.FFmpegCmd{}, "Picture", func() ([]byte, error) {
+			return nil, errors.New("ko")
+		}).
+		ApplyMethod(cmd.FFmpegCmd{}, "Metadata", func(_ cmd.FFmpegCmd, _ context.Context, _ string, fields map[string]string, coverPath string) error {
+			assert.NotContains(t, fields, "metadata_block_picture")
+			assert.Empty(t, coverPath)
+			return nil
+		}).
+		Reset()
+
+	// testing
+	for _, basename := range []string{"track.ogg", "track.flac"} {
+		tag, err := openVorbis(touch(t, basename), true)
+		assert.Nil(t, err)
+		assert.Nil(t, tag.Save(context.Background()))
+	}
+}
+
+func TestVorbisCreateTempFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.CreateTemp, func() (*os.File, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	tag, err := openVorbis(touch(t, "track.flac"), false)
+	assert.Nil(t, err)
+	tag.SetAttachedPicture([]byte("picture"))
+	assert.EqualError(t, tag.Save(context.Background()), "ko")
+}
+
+func TestVorbisWriteFailure(t *testing.T) {
+	path := touch(t, "track.flac")
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&os.File{}, "Write", func() (int, error) {
+		return 0, errors.New("ko")
+	}).Reset()
+
+	// testing
+	tag, err := openVorbis(path, false)
+	assert.Nil(t, err)
+	tag.SetAttachedPicture([]byte("picture"))
+	assert.EqualError(t, tag.Save(context.Background()), "ko")
+}
+
+func TestVorbisCloseFailure(t *testing.T) {
+	path := touch(t, "track.flac")
+
+	// monkey patching
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(os.File{}).Field(0).Type, "close", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	tag, err := openVorbis(path, false)
+	assert.Nil(t, err)
+	tag.SetAttachedPicture([]byte("picture"))
+	assert.EqualError(t, tag.Save(context.Background()), "ko")
+}
+
+func TestPictureBlock(t *testing.T) {
+	assert.Equal(t, append([]byte{
+		0, 0, 0, 3, // front cover
+		0, 0, 0, 10, 'i', 'm', 'a', 'g', 'e', '/', 'j', 'p', 'e', 'g',
+		0, 0, 0, 11, 'F', 'r', 'o', 'n', 't', ' ', 'c', 'o', 'v', 'e', 'r',
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 7,
+	}, []byte("picture")...), pictureBlock("image/jpeg", []byte("picture")))
+}
@@ -1,9 +1,15 @@
 package entity
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"path"
+	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/streambinder/spotitube/util"
@@ -11,7 +17,14 @@ import (
 
 type Artwork struct {
 	URL  string
-	Data []byte
+	Data []byte `json:"-"`
+}
+
+// Match is an upstream blob candidate
+// for a track, scored by a provider
+type Match struct {
+	URL   string
+	Score int
 }
 
 type Track struct {
@@ -23,8 +36,11 @@ type Track struct {
 	Duration    int // in seconds
 	Lyrics      string
 	Number      int // track number within the album
+	Disc        int // disc number within the album
 	Year        int
-	UpstreamURL string // URL to the upstream blob the song's been downloaded from
+	UpstreamURL string    // URL to the upstream blob the song's been downloaded from
+	Matches     []*Match  // upstream blob candidates, ranked by score
+	AddedAt     time.Time // when the track got added to the collection it is fetched from, if known
 }
 
 type TrackPath struct {
@@ -32,11 +48,49 @@ type TrackPath struct {
 }
 
 const (
-	TrackFormat   = "mp3"
 	ArtworkFormat = "jpg"
 	LyricsFormat  = "txt"
 )
 
+var (
+	// TrackFormats lists the audio formats tracks can be encoded to
+	TrackFormats = []string{"mp3", "opus", "m4a", "flac", "ogg"}
+	// TrackFormat is the audio format tracks get encoded to
+	TrackFormat = TrackFormats[0]
+)
+
+// PathTemplate, if set, lays out the path tracks get installed to, relative
+// to the output directory and with no extension: it is executed over the
+// track, whose fields are stripped of any character illegal in filenames
+var PathTemplate *template.Template
+
+// SetPathTemplate parses the given text as PathTemplate,
+// an empty one restoring the default flat layout
+func SetPathTemplate(text string) error {
+	if len(text) == 0 {
+		PathTemplate = nil
+		return nil
+	}
+
+	pathTemplate, err := template.New("path").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+	// catch execution errors upfront (e.g. unknown
+	// fields) rather than on every single track
+	if err := pathTemplate.Execute(io.Discard, &Track{Artists: []string{""}}); err != nil {
+		return err
+	}
+	PathTemplate = pathTemplate
+	return nil
+}
+
+// IsTrackFile tells whether the given path has
+// the extension of any supported track format
+func IsTrackFile(path string) bool {
+	return slices.Contains(TrackFormats, strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")))
+}
+
 // certain track titles include the variant description,
 // this functions aims to strip out that part:
 // > Title: Name - Acoustic
@@ -58,6 +112,12 @@ func (track *Track) Path() TrackPath {
 }
 
 func (trackPath TrackPath) Final() string {
+	if PathTemplate != nil {
+		if path, err := trackPath.template(); err == nil {
+			return path
+		}
+	}
+
 	// Get the primary artist and remove dots
 	primaryArtist := trackPath.track.Artists[0]
 	primaryArtist = strings.ReplaceAll(primaryArtist, ".", "")
@@ -90,6 +150,36 @@ func (trackPath TrackPath) Final() string {
 	return util.LegalizeFilename(fmt.Sprintf("%s - %s.%s", primaryArtist, title, TrackFormat))
 }
 
+// template executes PathTemplate over the track, making sure every
+// directory it lays out is a legal one, inside the output directory
+func (trackPath TrackPath) template() (string, error) {
+	track := *trackPath.track
+	track.Title = util.LegalizeFilename(track.Title)
+	track.Album = util.LegalizeFilename(track.Album)
+	track.Artists = make([]string, len(trackPath.track.Artists))
+	for i, artist := range trackPath.track.Artists {
+		track.Artists[i] = util.LegalizeFilename(artist)
+	}
+
+	var path strings.Builder
+	if err := PathTemplate.Execute(&path, &track); err != nil {
+		return "", err
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(path.String()), "/") {
+		segment = strings.TrimSpace(util.LegalizeFilename(segment))
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "", errors.New("empty path")
+	}
+	return filepath.Join(segments...) + "." + TrackFormat, nil
+}
+
 func (trackPath TrackPath) Download() string {
 	return util.CacheFile(
 		util.LegalizeFilename(fmt.Sprintf("%s.%s", slug.Make(trackPath.track.ID), TrackFormat)),
@@ -3,8 +3,10 @@ package entity
 import (
 	"fmt"
 	"path"
+	"path/filepath"
 	"testing"
 
+	"github.com/streambinder/spotitube/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,6 +14,8 @@ func BenchmarkTrack(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		TestSong(&testing.T{})
 		TestPath(&testing.T{})
+		TestIsTrackFile(&testing.T{})
+		TestPathTemplate(&testing.T{})
 	}
 }
 
@@ -40,4 +44,44 @@ func TestPath(t *testing.T) {
 	assert.Equal(t,
 		fmt.Sprintf("%s.%s", track.Path().track.ID, LyricsFormat),
 		path.Base(track.Path().Lyrics()))
+	assert.Equal(t, "AB - Title (Remix).mp3", path.Base((&Track{Title: "Title - Remix", Artists: []string{"A.B"}}).Path().Final()))
+}
+
+func TestIsTrackFile(t *testing.T) {
+	for _, format := range TrackFormats {
+		assert.True(t, IsTrackFile("Artist - Title."+format))
+	}
+	assert.True(t, IsTrackFile("path/Artist - Title.OPUS"))
+	assert.False(t, IsTrackFile("Artist - Title.jpg"))
+	assert.False(t, IsTrackFile("Artist - Title"))
+}
+
+func TestPathTemplate(t *testing.T) {
+	t.Cleanup(func() { util.ErrSuppress(SetPathTemplate("")) })
+	track := &Track{
+		Title:   "Title: Reprise",
+		Artists: []string{"AC/DC", "Other"},
+		Album:   "Album",
+		Number:  1,
+		Disc:    2,
+		Year:    1970,
+	}
+
+	// testing
+	assert.Nil(t, SetPathTemplate(`{{index .Artists 0}}/{{.Year}} - {{.Album}}/{{.Disc}}-{{printf "%02d" .Number}} {{.Song}}`))
+	assert.Equal(t, filepath.Join("ACDC", "1970 - Album", "2-01 Title Reprise.mp3"), track.Path().Final())
+	assert.Nil(t, SetPathTemplate("/../{{.Album}}//./{{.Title}}"))
+	assert.Equal(t, filepath.Join("Album", "Title Reprise.mp3"), track.Path().Final())
+	assert.Nil(t, SetPathTemplate("{{if false}}{{.Title}}{{end}}"))
+	assert.Equal(t, "ACDC - Title Reprise (ft Other).mp3", track.Path().Final()) // falls back to flat
+	assert.Nil(t, SetPathTemplate("{{if .Title}}{{index .Artists 2}}{{end}}"))
+	assert.Equal(t, "ACDC - Title Reprise (ft Other).mp3", track.Path().Final()) // falls back to flat
+	assert.Nil(t, SetPathTemplate(""))
+	assert.Nil(t, PathTemplate)
+}
+
+func TestPathTemplateFailure(t *testing.T) {
+	assert.Error(t, SetPathTemplate("{{.Title"))
+	assert.Error(t, SetPathTemplate("{{.Unknown}}"))
+	assert.Nil(t, PathTemplate)
 }
@@ -12,12 +12,12 @@ import (
 var composers = []Composer{}
 
 type Composer interface {
-	search(*entity.Track, ...context.Context) ([]byte, error)
-	get(string, ...context.Context) ([]byte, error)
+	search(context.Context, *entity.Track) ([]byte, error)
+	get(context.Context, string) ([]byte, error)
 }
 
 // not found entries return no error
-func Search(track *entity.Track) (string, error) {
+func Search(ctxBackground context.Context, track *entity.Track) (string, error) {
 	if bytes, err := os.ReadFile(track.Path().Lyrics()); err == nil {
 		return string(bytes), nil
 	}
@@ -25,7 +25,6 @@ func Search(track *entity.Track) (string, error) {
 	var (
 		workers        []nursery.ConcurrentJob
 		result         []byte
-		ctxBackground  = context.Background()
 		ctx, ctxCancel = context.WithCancel(ctxBackground)
 	)
 	defer ctxCancel()
@@ -33,7 +32,7 @@ func Search(track *entity.Track) (string, error) {
 	for _, composer := range composers {
 		workers = append(workers, func(c Composer) func(context.Context, chan error) {
 			return func(ctx context.Context, ch chan error) {
-				scopedLyrics, err := c.search(track, ctx)
+				scopedLyrics, err := c.search(ctx, track)
 				if err != nil {
 					ch <- err
 					return
@@ -74,7 +73,7 @@ func Get(url string) (string, error) {
 	for _, composer := range composers {
 		workers = append(workers, func(c Composer) func(context.Context, chan error) {
 			return func(ctx context.Context, ch chan error) {
-				scopedLyrics, err := c.get(url, ctx)
+				scopedLyrics, err := c.get(ctx, url)
 				if err != nil {
 					ch <- err
 					return
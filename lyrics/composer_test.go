@@ -1,6 +1,7 @@
 package lyrics
 
 import (
+	"context"
 	"errors"
 	"os"
 	"reflect"
@@ -39,7 +40,7 @@ func TestSearch(t *testing.T) {
 		Reset()
 
 	// testing
-	lyrics, err := Search(track)
+	lyrics, err := Search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Equal(t, "glyrics", lyrics)
 }
@@ -51,7 +52,7 @@ func TestSearchAlreadyExists(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := Search(track)
+	lyrics, err := Search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Equal(t, "lyrics", lyrics)
 }
@@ -71,7 +72,7 @@ func TestSearchFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(Search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(Search(context.Background(), track)), "ko")
 }
 
 func TestSearchNotFound(t *testing.T) {
@@ -89,7 +90,7 @@ func TestSearchNotFound(t *testing.T) {
 		Reset()
 
 	// testing
-	lyrics, err := Search(track)
+	lyrics, err := Search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Empty(t, lyrics)
 }
@@ -112,7 +113,7 @@ func TestSearchCannotCreateDir(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(Search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(Search(context.Background(), track)), "ko")
 }
 
 func TestGet(t *testing.T) {
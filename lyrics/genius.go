@@ -50,14 +50,8 @@ func init() {
 	composers = append(composers, &genius{})
 }
 
-func (composer genius) search(track *entity.Track, ctxs ...context.Context) ([]byte, error) {
-	var (
-		ctx            = context.Background()
-		mainArtistOnly = false
-	)
-	if len(ctxs) > 0 {
-		ctx = ctxs[0]
-	}
+func (composer genius) search(ctx context.Context, track *entity.Track) ([]byte, error) {
+	mainArtistOnly := false
 	if ctxMainArtistOnly, ok := ctx.Value(contextValueLabel(contextValueLabelMainArtist)).(bool); ok {
 		mainArtistOnly = ctxMainArtistOnly
 	}
@@ -85,20 +79,15 @@ func (composer genius) search(track *entity.Track, ctxs ...context.Context) ([]b
 	defer response.Body.Close()
 	if response.StatusCode == 429 {
 		util.SleepUntilRetry(response.Header)
-		return composer.search(track, ctx)
+		return composer.search(ctx, track)
 	} else if response.StatusCode != 200 {
 		return nil, errors.New("cannot search lyrics on genius: " + response.Status)
 	}
 
-	return composer.parseResult(track, query, mainArtistOnly, response.Body, ctxs...)
+	return composer.parseResult(ctx, track, query, mainArtistOnly, response.Body)
 }
 
-func (composer genius) parseResult(track *entity.Track, query string, mainArtistOnly bool, response io.Reader, ctxs ...context.Context) ([]byte, error) {
-	ctx := context.Background()
-	if len(ctxs) > 0 {
-		ctx = ctxs[0]
-	}
-
+func (composer genius) parseResult(ctx context.Context, track *entity.Track, query string, mainArtistOnly bool, response io.Reader) ([]byte, error) {
 	body, err := io.ReadAll(response)
 	if err != nil {
 		return nil, err
@@ -126,18 +115,13 @@ func (composer genius) parseResult(track *entity.Track, query string, mainArtist
 		return nil, nil
 	} else if url == "" {
 		return composer.search(
-			track, context.WithValue(ctx, contextValueLabel(contextValueLabelMainArtist), true))
+			context.WithValue(ctx, contextValueLabel(contextValueLabelMainArtist), true), track)
 	}
 
-	return composer.get(url, ctx)
+	return composer.get(ctx, url)
 }
 
-func (composer genius) get(url string, ctxs ...context.Context) ([]byte, error) {
-	ctx := context.Background()
-	if len(ctxs) > 0 {
-		ctx = ctxs[0]
-	}
-
+func (composer genius) get(ctx context.Context, url string) ([]byte, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -153,7 +137,7 @@ func (composer genius) get(url string, ctxs ...context.Context) ([]byte, error)
 
 	if response.StatusCode == 429 {
 		util.SleepUntilRetry(response.Header)
-		return composer.get(url, ctx)
+		return composer.get(ctx, url)
 	} else if response.StatusCode != 200 {
 		return nil, errors.New("cannot fetch lyrics on genius: " + response.Status)
 	}
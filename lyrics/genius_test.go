@@ -55,7 +55,7 @@ func TestGeniusSearch(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := genius{}.search(track, context.Background())
+	lyrics, err := genius{}.search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Equal(t, []byte("verse\nlyrics"), lyrics)
 }
@@ -67,7 +67,7 @@ func TestGeniusSearchNewRequestFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.search(context.Background(), track)), "ko")
 }
 
 func TestGeniusSearchNewRequestContextCanceled(t *testing.T) {
@@ -77,7 +77,7 @@ func TestGeniusSearchNewRequestContextCanceled(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := genius{}.search(track)
+	lyrics, err := genius{}.search(context.Background(), track)
 	assert.Nil(t, lyrics)
 	assert.Nil(t, err)
 }
@@ -93,7 +93,7 @@ func TestGeniusSearchMalformedData(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Error(t, util.ErrOnly(genius{}.search(track)))
+	assert.Error(t, util.ErrOnly(genius{}.search(context.Background(), track)))
 }
 
 func TestGeniusSearchFailure(t *testing.T) {
@@ -103,7 +103,7 @@ func TestGeniusSearchFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.search(context.Background(), track)), "ko")
 }
 
 func TestGeniusSearchHttpNotFound(t *testing.T) {
@@ -117,7 +117,7 @@ func TestGeniusSearchHttpNotFound(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.NotNil(t, util.ErrOnly(genius{}.search(track)))
+	assert.NotNil(t, util.ErrOnly(genius{}.search(context.Background(), track)))
 }
 
 func TestGeniusSearchTooManyRequests(t *testing.T) {
@@ -158,7 +158,7 @@ func TestGeniusSearchTooManyRequests(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(genius{}.search(track)))
+	assert.Nil(t, util.ErrOnly(genius{}.search(context.Background(), track)))
 }
 
 func TestGeniusSearchReadFailure(t *testing.T) {
@@ -177,7 +177,7 @@ func TestGeniusSearchReadFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.search(context.Background(), track)), "ko")
 }
 
 func TestGeniusSearchNotFound(t *testing.T) {
@@ -191,7 +191,7 @@ func TestGeniusSearchNotFound(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := genius{}.search(track)
+	lyrics, err := genius{}.search(context.Background(), track)
 	assert.Nil(t, lyrics)
 	assert.Nil(t, err)
 }
@@ -210,7 +210,7 @@ func TestGeniusLyricsGetFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.search(context.Background(), track)), "ko")
 }
 
 func TestGeniusLyricsNewRequestFailure(t *testing.T) {
@@ -220,7 +220,7 @@ func TestGeniusLyricsNewRequestFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.get("http://genius.com/test", context.Background())), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.get(context.Background(), "http://genius.com/test")), "ko")
 }
 
 func TestGeniusLyricsNewRequestContextCanceled(t *testing.T) {
@@ -237,7 +237,7 @@ func TestGeniusLyricsNewRequestContextCanceled(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := genius{}.search(track)
+	lyrics, err := genius{}.search(context.Background(), track)
 	assert.Nil(t, lyrics)
 	assert.Nil(t, err)
 }
@@ -259,7 +259,7 @@ func TestGeniusLyricsNotFound(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := genius{}.search(track)
+	lyrics, err := genius{}.search(context.Background(), track)
 	assert.Nil(t, lyrics)
 	assert.NotNil(t, err)
 }
@@ -286,7 +286,7 @@ func TestGeniusLyricsNotParseable(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(genius{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(genius{}.search(context.Background(), track)), "ko")
 }
 
 // func TestScraping(t *testing.T) {
@@ -25,23 +25,13 @@ func init() {
 	// composers = append(composers, &lyricsOvh{})
 }
 
-func (composer lyricsOvh) search(track *entity.Track, ctxs ...context.Context) ([]byte, error) {
-	ctx := context.Background()
-	if len(ctxs) > 0 {
-		ctx = ctxs[0]
-	}
-
-	return composer.get(fmt.Sprintf("https://api.lyrics.ovh/v1/%s/%s",
+func (composer lyricsOvh) search(ctx context.Context, track *entity.Track) ([]byte, error) {
+	return composer.get(ctx, fmt.Sprintf("https://api.lyrics.ovh/v1/%s/%s",
 		url.QueryEscape(track.Artists[0]),
-		url.QueryEscape(track.Title)), ctx)
+		url.QueryEscape(track.Title)))
 }
 
-func (composer lyricsOvh) get(url string, ctxs ...context.Context) ([]byte, error) {
-	ctx := context.Background()
-	if len(ctxs) > 0 {
-		ctx = ctxs[0]
-	}
-
+func (composer lyricsOvh) get(ctx context.Context, url string) ([]byte, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -60,7 +50,7 @@ func (composer lyricsOvh) get(url string, ctxs ...context.Context) ([]byte, erro
 		return nil, nil
 	case response.StatusCode == 429:
 		util.SleepUntilRetry(response.Header)
-		return composer.get(url, ctx)
+		return composer.get(ctx, url)
 	case response.StatusCode != 200:
 		return nil, errors.New("cannot fetch results on lyrics.ovh: " + response.Status)
 	}
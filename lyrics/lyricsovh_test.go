@@ -33,7 +33,7 @@ func TestLyricsOvhSearch(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := lyricsOvh{}.search(track, context.Background())
+	lyrics, err := lyricsOvh{}.search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Equal(t, []byte("lyrics"), lyrics)
 }
@@ -45,7 +45,7 @@ func TestLyricsOvhSearchNewRequestFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)), "ko")
 }
 
 func TestLyricsOvhSearchNewRequestContextCanceled(t *testing.T) {
@@ -55,7 +55,7 @@ func TestLyricsOvhSearchNewRequestContextCanceled(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := lyricsOvh{}.search(track, context.Background())
+	lyrics, err := lyricsOvh{}.search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.Nil(t, lyrics)
 }
@@ -67,7 +67,7 @@ func TestLyricsOvhSearchFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)), "ko")
 }
 
 func TestLyricsOvhSearchNotFound(t *testing.T) {
@@ -81,7 +81,7 @@ func TestLyricsOvhSearchNotFound(t *testing.T) {
 	}).Reset()
 
 	// testing
-	lyrics, err := lyricsOvh{}.search(track)
+	lyrics, err := lyricsOvh{}.search(context.Background(), track)
 	assert.Nil(t, lyrics)
 	assert.Nil(t, err)
 }
@@ -109,7 +109,7 @@ func TestLyricsOvhSearchTooManyRequests(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(lyricsOvh{}.search(track)))
+	assert.Nil(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)))
 }
 
 func TestLyricsOvhSearchInternalError(t *testing.T) {
@@ -123,7 +123,7 @@ func TestLyricsOvhSearchInternalError(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.NotNil(t, util.ErrOnly(lyricsOvh{}.search(track)))
+	assert.NotNil(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)))
 }
 
 func TestLyricsOvhSearchReadFailure(t *testing.T) {
@@ -142,7 +142,7 @@ func TestLyricsOvhSearchReadFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)), "ko")
 }
 
 func TestLyricsOvhSearchJsonFailure(t *testing.T) {
@@ -161,5 +161,5 @@ func TestLyricsOvhSearchJsonFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(lyricsOvh{}.search(context.Background(), track)), "ko")
 }
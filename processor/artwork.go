@@ -3,6 +3,7 @@ package processor
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"image"
 	"image/jpeg"
@@ -19,7 +20,7 @@ func (Artwork) Applies(object interface{}) bool {
 	return ok
 }
 
-func (Artwork) Do(object interface{}) error {
+func (Artwork) Do(_ context.Context, object interface{}) error {
 	data, ok := object.(*[]byte)
 	if !ok {
 		return errors.New("processor does not support such object")
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"image"
 	"image/jpeg"
@@ -28,12 +29,12 @@ func TestArtworkDo(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, Artwork{}.Do(&[]byte{}))
+	assert.Nil(t, Artwork{}.Do(context.Background(), &[]byte{}))
 }
 
 func TestArtworkDoUnsupported(t *testing.T) {
 	// testing
-	assert.NotNil(t, Artwork{}.Do(track))
+	assert.NotNil(t, Artwork{}.Do(context.Background(), track))
 }
 
 func TestEncoderDoDecodeFailure(t *testing.T) {
@@ -43,7 +44,7 @@ func TestEncoderDoDecodeFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, Artwork{}.Do(&[]byte{}), "ko")
+	assert.EqualError(t, Artwork{}.Do(context.Background(), &[]byte{}), "ko")
 }
 
 func TestEncoderDoEncodeFailure(t *testing.T) {
@@ -58,5 +59,5 @@ func TestEncoderDoEncodeFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, Artwork{}.Do(&[]byte{}), "ko")
+	assert.EqualError(t, Artwork{}.Do(context.Background(), &[]byte{}), "ko")
 }
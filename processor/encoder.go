@@ -1,12 +1,13 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"strconv"
+	"time"
 
-	"github.com/bogem/id3v2/v2"
 	"github.com/streambinder/spotitube/entity"
-	"github.com/streambinder/spotitube/entity/id3"
+	"github.com/streambinder/spotitube/entity/tag"
 )
 
 type encoder struct {
@@ -18,13 +19,13 @@ func (encoder) Applies(object interface{}) bool {
 	return ok
 }
 
-func (encoder) Do(object interface{}) error {
+func (encoder) Do(ctx context.Context, object interface{}) error {
 	track, ok := object.(*entity.Track)
 	if !ok {
 		return errors.New("processor does not support such object")
 	}
 
-	tag, err := id3.Open(track.Path().Download(), id3v2.Options{Parse: false})
+	tag, err := tag.Open(track.Path().Download(), false)
 	if err != nil {
 		return err
 	}
@@ -33,13 +34,19 @@ func (encoder) Do(object interface{}) error {
 	tag.SetSpotifyID(track.ID)
 	tag.SetTitle(track.Title)
 	tag.SetArtist(track.Artists[0])
+	tag.SetArtists(track.Artists)
 	tag.SetAlbum(track.Album)
 	tag.SetArtworkURL(track.Artwork.URL)
 	tag.SetAttachedPicture(track.Artwork.Data)
 	tag.SetDuration(strconv.Itoa(track.Duration))
 	tag.SetUnsynchronizedLyrics(track.Title, track.Lyrics)
 	tag.SetTrackNumber(strconv.Itoa(track.Number))
+	tag.SetDiscNumber(strconv.Itoa(track.Disc))
 	tag.SetYear(strconv.Itoa(track.Year))
 	tag.SetUpstreamURL(track.UpstreamURL)
-	return tag.Save()
+	// sortable by players, unlike the collection order
+	if !track.AddedAt.IsZero() {
+		tag.SetAddedDate(track.AddedAt.UTC().Format(time.RFC3339))
+	}
+	return tag.Save(ctx)
 }
@@ -1,8 +1,10 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/bogem/id3v2/v2"
@@ -27,12 +29,70 @@ func TestEncoderDo(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, encoder{}.Do(track))
+	assert.Nil(t, encoder{}.Do(context.Background(), track))
+}
+
+func TestEncoderDoAddedDate(t *testing.T) {
+	var (
+		added = *track
+		date  string
+	)
+	added.AddedAt = time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+			return id3v2.NewEmptyTag(), nil
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Save", func(tag *id3v2.Tag) error {
+			for _, frame := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+				if frame, ok := frame.(id3v2.UserDefinedTextFrame); ok && frame.Description == "Added Date" {
+					date = frame.Value
+				}
+			}
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, encoder{}.Do(context.Background(), &added))
+	assert.Equal(t, "2026-01-02T14:04:05Z", date)
+}
+
+func TestEncoderDoArtists(t *testing.T) {
+	var (
+		featuring = *track
+		artists   string
+		disc      string
+	)
+	featuring.Artists = []string{"Artist", "Guest"}
+	featuring.Disc = 2
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(id3v2.Open, func() (*id3v2.Tag, error) {
+			return id3v2.NewEmptyTag(), nil
+		}).
+		ApplyMethod(&id3v2.Tag{}, "Save", func(tag *id3v2.Tag) error {
+			for _, frame := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+				if frame, ok := frame.(id3v2.UserDefinedTextFrame); ok && frame.Description == "Artists" {
+					artists = frame.Value
+				}
+			}
+			disc = tag.GetTextFrame(tag.CommonID("Part of a set")).Text
+			return nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, encoder{}.Do(context.Background(), &featuring))
+	assert.Equal(t, "Artist; Guest", artists)
+	assert.Equal(t, "2", disc)
 }
 
 func TestEncoderDoUnsupported(t *testing.T) {
 	// testing
-	assert.NotNil(t, encoder{}.Do("hello"))
+	assert.NotNil(t, encoder{}.Do(context.Background(), "hello"))
 }
 
 func TestEncoderDoOpenFailure(t *testing.T) {
@@ -42,5 +102,5 @@ func TestEncoderDoOpenFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, encoder{}.Do(track), "ko")
+	assert.EqualError(t, encoder{}.Do(context.Background(), track), "ko")
 }
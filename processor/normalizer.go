@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"math"
 
@@ -17,13 +18,13 @@ func (normalizer) Applies(object interface{}) bool {
 	return ok
 }
 
-func (normalizer) Do(object interface{}) error {
+func (normalizer) Do(ctx context.Context, object interface{}) error {
 	track, ok := object.(*entity.Track)
 	if !ok {
 		return errors.New("processor does not support such object")
 	}
 
-	volumeDelta, err := cmd.FFmpeg().VolumeDetect(track.Path().Download())
+	volumeDelta, err := cmd.FFmpeg().VolumeDetect(ctx, track.Path().Download())
 	if err != nil {
 		return err
 	}
@@ -35,5 +36,5 @@ func (normalizer) Do(object interface{}) error {
 		volumeDelta = math.Abs(volumeDelta)
 	}
 
-	return cmd.FFmpeg().VolumeAdd(track.Path().Download(), volumeDelta)
+	return cmd.FFmpeg().VolumeAdd(ctx, track.Path().Download(), volumeDelta)
 }
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -27,7 +28,7 @@ func TestNormalizerDo(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, normalizer{}.Do(track))
+	assert.Nil(t, normalizer{}.Do(context.Background(), track))
 }
 
 func TestNormalizerDoReverse(t *testing.T) {
@@ -42,12 +43,12 @@ func TestNormalizerDoReverse(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, normalizer{}.Do(track))
+	assert.Nil(t, normalizer{}.Do(context.Background(), track))
 }
 
 func TestNormalizerDoUnsupported(t *testing.T) {
 	// testing
-	assert.NotNil(t, normalizer{}.Do("hello"))
+	assert.NotNil(t, normalizer{}.Do(context.Background(), "hello"))
 }
 
 func TestNormalizerDoFailure(t *testing.T) {
@@ -57,5 +58,5 @@ func TestNormalizerDoFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, normalizer{}.Do(track), "ko")
+	assert.EqualError(t, normalizer{}.Do(context.Background(), track), "ko")
 }
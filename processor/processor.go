@@ -1,18 +1,20 @@
 package processor
 
+import "context"
+
 type Processor interface {
-	Do(interface{}) error
+	Do(context.Context, interface{}) error
 	Applies(interface{}) bool
 }
 
-func Do(object interface{}) error {
+func Do(ctx context.Context, object interface{}) error {
 	for _, processor := range []Processor{
 		Artwork{},
 		normalizer{},
 		encoder{},
 	} {
 		if supported := processor.Applies(object); supported {
-			if err := processor.Do(object); err != nil {
+			if err := processor.Do(ctx, object); err != nil {
 				return err
 			}
 		}
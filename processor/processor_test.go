@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -39,7 +40,7 @@ func TestProcessorDo(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, Do(track))
+	assert.Nil(t, Do(context.Background(), track))
 }
 
 func TestProcessorDoFailure(t *testing.T) {
@@ -54,5 +55,5 @@ func TestProcessorDoFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, Do(track), "ko")
+	assert.EqualError(t, Do(context.Background(), track), "ko")
 }
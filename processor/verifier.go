@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util/cmd"
+)
+
+// Verifier ensures the downloaded blob actually is an audio file
+// lasting as long as the track it has been downloaded for,
+// give or take Tolerance seconds: if so, the measured duration
+// replaces the one declared upstream
+type Verifier struct {
+	Tolerance int
+}
+
+var _ Processor = Verifier{}
+
+func (Verifier) Applies(object interface{}) bool {
+	_, ok := object.(*entity.Track)
+	return ok
+}
+
+func (verifier Verifier) Do(ctx context.Context, object interface{}) error {
+	track, ok := object.(*entity.Track)
+	if !ok {
+		return errors.New("processor does not support such object")
+	}
+
+	probe, err := cmd.FFprobe().Probe(ctx, track.Path().Download())
+	if err != nil {
+		return err
+	}
+
+	if !probe.Audio() {
+		return errors.New("blob does not carry any audio stream")
+	}
+
+	duration := int(math.Round(probe.Duration))
+	// tracks with no declared duration cannot be checked against
+	if track.Duration > 0 && int(math.Abs(float64(duration-track.Duration))) > verifier.Tolerance {
+		return fmt.Errorf("blob lasts %ds while track %ds", duration, track.Duration)
+	}
+
+	track.Duration = duration
+	return nil
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/util/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func stubProbe(duration float64, streams ...cmd.ProbeStream) func() (*cmd.Probe, error) {
+	return func() (*cmd.Probe, error) {
+		return &cmd.Probe{Duration: duration, Streams: streams}, nil
+	}
+}
+
+func BenchmarkVerifier(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestVerifierDo(&testing.T{})
+	}
+}
+
+func TestVerifierDo(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe",
+		stubProbe(189.6, cmd.ProbeStream{Type: "audio", Channels: 2})).Reset()
+
+	// testing
+	verifiedTrack := *track
+	assert.True(t, Verifier{}.Applies(&verifiedTrack))
+	assert.Nil(t, Verifier{Tolerance: 10}.Do(context.Background(), &verifiedTrack))
+	assert.Equal(t, 190, verifiedTrack.Duration)
+}
+
+func TestVerifierDoUnknownDuration(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe",
+		stubProbe(600, cmd.ProbeStream{Type: "audio", Channels: 2})).Reset()
+
+	// testing
+	verifiedTrack := entity.Track{ID: "123", Title: "Title", Artists: []string{"Artist"}}
+	assert.Nil(t, Verifier{}.Do(context.Background(), &verifiedTrack))
+	assert.Equal(t, 600, verifiedTrack.Duration)
+}
+
+func TestVerifierDoUnsupported(t *testing.T) {
+	assert.False(t, Verifier{}.Applies("hello"))
+	assert.NotNil(t, Verifier{}.Do(context.Background(), "hello"))
+}
+
+func TestVerifierDoProbeFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe", func() (*cmd.Probe, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	verifiedTrack := *track
+	assert.EqualError(t, Verifier{}.Do(context.Background(), &verifiedTrack), "ko")
+}
+
+func TestVerifierDoNoAudio(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe",
+		stubProbe(180, cmd.ProbeStream{Type: "video"})).Reset()
+
+	// testing
+	verifiedTrack := *track
+	assert.Error(t, Verifier{}.Do(context.Background(), &verifiedTrack))
+}
+
+func TestVerifierDoOutOfTolerance(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(cmd.FFprobeCmd{}, "Probe",
+		stubProbe(30, cmd.ProbeStream{Type: "audio", Channels: 2})).Reset()
+
+	// testing
+	verifiedTrack := *track
+	assert.EqualError(t, Verifier{Tolerance: 10}.Do(context.Background(), &verifiedTrack), "blob lasts 30s while track 180s")
+	assert.Equal(t, 180, verifiedTrack.Duration)
+}
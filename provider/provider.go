@@ -13,24 +13,22 @@ var (
 	misleading = []string{"cover", "live", "karaoke", "performance", "studio", "instrumental", "remix", "acoustic"}
 )
 
-type Match struct {
-	URL   string
-	Score int
-}
+type Match = entity.Match
 
 type Provider interface {
-	search(track *entity.Track) ([]*Match, error)
+	search(ctx context.Context, track *entity.Track) ([]*Match, error)
 }
 
-func Search(track *entity.Track) ([]*Match, error) {
+func Search(ctx context.Context, track *entity.Track) ([]*Match, error) {
 	var (
 		workers []nursery.ConcurrentJob
 		matches []*Match
 	)
+
 	for _, provider := range providers {
 		workers = append(workers, func(p Provider) func(ctx context.Context, ch chan error) {
-			return func(_ context.Context, ch chan error) {
-				scopedMatches, err := p.search(track)
+			return func(ctx context.Context, ch chan error) {
+				scopedMatches, err := p.search(ctx, track)
 				if err != nil {
 					ch <- err
 					return
@@ -40,7 +38,7 @@ func Search(track *entity.Track) ([]*Match, error) {
 		}(provider))
 	}
 
-	if err := nursery.RunConcurrently(workers...); err != nil {
+	if err := nursery.RunConcurrentlyWithContext(ctx, workers...); err != nil {
 		return nil, err
 	}
 
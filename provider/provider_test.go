@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -38,7 +39,7 @@ func TestSearch(t *testing.T) {
 	}).Reset()
 
 	// testing
-	matches, err := Search(track)
+	matches, err := Search(context.Background(), track)
 	assert.Nil(t, err)
 	assert.NotEmpty(t, matches)
 }
@@ -50,5 +51,5 @@ func TestSearchFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(Search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(Search(context.Background(), track)), "ko")
 }
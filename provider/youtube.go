@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -100,13 +101,18 @@ func init() {
 	providers = append(providers, youTube{})
 }
 
-func (provider youTube) search(track *entity.Track) ([]*Match, error) {
+func (provider youTube) search(ctx context.Context, track *entity.Track) ([]*Match, error) {
 	query := track.Title
 	for _, artist := range track.Artists {
 		query = fmt.Sprintf("%s %s", query, artist)
 	}
 
-	response, err := http.Get("https://www.youtube.com/results?search_query=" + url.QueryEscape(query) + "&sp=EgIQAQ%253D%253D")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.youtube.com/results?search_query="+url.QueryEscape(query)+"&sp=EgIQAQ%253D%253D", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +120,7 @@ func (provider youTube) search(track *entity.Track) ([]*Match, error) {
 
 	if response.StatusCode == 429 {
 		util.SleepUntilRetry(response.Header)
-		return provider.search(track)
+		return provider.search(ctx, track)
 	} else if response.StatusCode != 200 {
 		return nil, errors.New("cannot fetch results on youtube: " + response.Status)
 	}
@@ -193,7 +199,7 @@ func (provider youTube) parseResults(track *entity.Track, query string, body io.
 					}).MetadataBadgeRenderer.Icon.IconType),
 				}
 				if match.compliant(track) {
-					matches = append(matches, &Match{fmt.Sprintf("https://youtu.be/%s", match.id), match.score()})
+					matches = append(matches, &Match{URL: fmt.Sprintf("https://youtu.be/%s", match.id), Score: match.score()})
 				}
 			}
 		}
@@ -1,11 +1,13 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -85,7 +87,7 @@ func BenchmarkYouTube(b *testing.B) {
 
 func TestYouTubeSearch(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 200,
 			Body: io.NopCloser(strings.NewReader(
@@ -104,12 +106,12 @@ func TestYouTubeSearch(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(youTube{}.search(track)))
+	assert.Nil(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
 }
 
 func TestYouTubeSearchMalformedData(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 200,
 			Body:       io.NopCloser(strings.NewReader(`<script>var ytInitialData = {"content": {}`)),
@@ -117,12 +119,12 @@ func TestYouTubeSearchMalformedData(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.NotNil(t, util.ErrOnly(youTube{}.search(track)))
+	assert.NotNil(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
 }
 
 func TestYouTubeSearchPartialData(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 200,
 			Body: io.NopCloser(strings.NewReader(fmt.Sprintf(
@@ -139,26 +141,28 @@ func TestYouTubeSearchPartialData(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(youTube{}.search(track)))
+	assert.Nil(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
 }
 
 func TestYouTubeSearchTooManyRequests(t *testing.T) {
 	// monkey patching
+	statuses := []int{429, 200}
 	defer gomonkey.NewPatches().
 		ApplyFunc(time.Sleep, func() {}).
-		ApplyMethodSeq(http.DefaultClient, "Get", []gomonkey.OutputCell{
-			{Values: gomonkey.Params{&http.Response{StatusCode: 429, Body: io.NopCloser(strings.NewReader(""))}, nil}},
-			{Values: gomonkey.Params{&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil}},
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
+			status := statuses[0]
+			statuses = statuses[1:]
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
 		}).
 		Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(youTube{}.search(track)))
+	assert.Nil(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
 }
 
 func TestYouTubeSearchNoData(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 200,
 			Body:       io.NopCloser(strings.NewReader("<script>some unmatching script</script>")),
@@ -166,27 +170,37 @@ func TestYouTubeSearchNoData(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Nil(t, util.ErrOnly(youTube{}.search(track)))
+	assert.Nil(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
+}
+
+func TestYouTubeSearchRequestFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(http.NewRequestWithContext, func() (*http.Request, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(youTube{}.search(context.Background(), track)), "ko")
 }
 
 func TestYouTubeSearchFailingRequest(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return nil, errors.New("ko")
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(youTube{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(youTube{}.search(context.Background(), track)), "ko")
 }
 
 func TestYouTubeSearchFailingRequestStatus(t *testing.T) {
 	// monkey patching
-	defer gomonkey.ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 		return &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader(""))}, nil
 	}).Reset()
 
 	// testing
-	assert.Error(t, util.ErrOnly(youTube{}.search(track)))
+	assert.Error(t, util.ErrOnly(youTube{}.search(context.Background(), track)))
 }
 
 func TestYouTubeSearchFailingGoQuery(t *testing.T) {
@@ -195,7 +209,7 @@ func TestYouTubeSearchFailingGoQuery(t *testing.T) {
 		ApplyFunc(goquery.NewDocumentFromReader, func() (*goquery.Document, error) {
 			return nil, errors.New("ko")
 		}).
-		ApplyMethod(http.DefaultClient, "Get", func() (*http.Response, error) {
+		ApplyPrivateMethod(reflect.TypeOf(http.DefaultClient), "do", func() (*http.Response, error) {
 			return &http.Response{
 				StatusCode: 200,
 				Body:       io.NopCloser(strings.NewReader("<script>some unmatching script</script>")),
@@ -204,7 +218,7 @@ func TestYouTubeSearchFailingGoQuery(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(youTube{}.search(track)), "ko")
+	assert.EqualError(t, util.ErrOnly(youTube{}.search(context.Background(), track)), "ko")
 }
 
 func TestScraping(t *testing.T) {
@@ -213,7 +227,7 @@ func TestScraping(t *testing.T) {
 	}
 
 	// testing
-	matches, err := youTube{}.search(&entity.Track{
+	matches, err := youTube{}.search(context.Background(), &entity.Track{
 		Title:    "White Christmas",
 		Artists:  []string{"Bing Crosby"},
 		Duration: 183,
@@ -30,6 +30,12 @@ func (client *Client) Album(target string, channels ...chan interface{}) (*entit
 		return nil, err
 	}
 
+	return client.albumTracks(ctx, fullAlbum, channels...)
+}
+
+// albumTracks pages through the tracks of the given album,
+// returning it whole
+func (client *Client) albumTracks(ctx context.Context, fullAlbum *spotify.FullAlbum, channels ...chan interface{}) (*entity.Album, error) {
 	album := albumEntity(fullAlbum)
 	for {
 		for _, albumTrack := range fullAlbum.Tracks.Tracks {
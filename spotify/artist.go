@@ -0,0 +1,171 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/gosimple/slug"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	tracksBatchSize           = 50 // the most tracks Spotify serves at once
+	releasesDurationTolerance = 2  // seconds
+)
+
+var (
+	// AlbumTypes are the types of the releases an artist takes part in
+	AlbumTypes = []string{"album", "single", "compilation", "appears_on"}
+	albumTypes = map[string]spotify.AlbumType{
+		"album":       spotify.AlbumTypeAlbum,
+		"single":      spotify.AlbumTypeSingle,
+		"compilation": spotify.AlbumTypeCompilation,
+		"appears_on":  spotify.AlbumTypeAppearsOn,
+	}
+	idPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+)
+
+// releases keeps track of the tracks already found across the releases
+// of an artist, telling the same recording by ISRC or by title and duration
+type releases struct {
+	isrcs     map[string]bool
+	durations map[string][]int // by title
+}
+
+func (client *Client) artistID(target string) (spotify.ID, error) {
+	if id := id(target); idPattern.MatchString(id.String()) {
+		return id, nil
+	}
+
+	search, err := client.Search(context.Background(), target, spotify.SearchTypeArtist, spotify.Limit(1))
+	if err != nil {
+		return "", err
+	}
+	if search.Artists == nil || len(search.Artists.Artists) == 0 {
+		return "", errors.New("artist not found: " + target)
+	}
+	return search.Artists.Artists[0].ID, nil
+}
+
+// Artist fetches the discography of the given artist, i.e. the tracks the artist takes
+// part in among the releases of the given types, each recording appearing only once
+func (client *Client) Artist(target string, types []string, channels ...chan interface{}) ([]*entity.Track, error) {
+	filter := []spotify.AlbumType{}
+	for _, albumType := range types {
+		if _, ok := albumTypes[albumType]; !ok {
+			return nil, errors.New("unsupported album type: " + albumType)
+		}
+		filter = append(filter, albumTypes[albumType])
+	}
+
+	artistID, err := client.artistID(target)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := client.artistTracksIDs(artistID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// only full tracks tell their ISRC
+	var (
+		ctx      = context.Background()
+		releases = releases{make(map[string]bool), make(map[string][]int)}
+		tracks   = []*entity.Track{}
+	)
+	for offset := 0; offset < len(ids); offset += tracksBatchSize {
+		fullTracks, err := client.GetTracks(ctx, ids[offset:min(offset+tracksBatchSize, len(ids))])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fullTrack := range fullTracks {
+			if fullTrack == nil || releases.seen(fullTrack) {
+				continue
+			}
+			track := trackEntity(*fullTrack)
+			tracks = append(tracks, track)
+			for _, ch := range channels {
+				ch <- track
+			}
+		}
+	}
+	return tracks, nil
+}
+
+// artistTracksIDs returns the IDs of the tracks the given artist
+// takes part in, among its releases of the given types
+func (client *Client) artistTracksIDs(artistID spotify.ID, filter []spotify.AlbumType) ([]spotify.ID, error) {
+	ctx := context.Background()
+	albums, err := client.GetArtistAlbums(ctx, artistID, filter, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+
+	albumsIDs := []spotify.ID{}
+	for {
+		for _, album := range albums.Albums {
+			albumsIDs = append(albumsIDs, album.ID)
+		}
+
+		if err := client.NextPage(ctx, albums); errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	ids := []spotify.ID{}
+	for _, albumID := range albumsIDs {
+		fullAlbum, err := client.GetAlbum(ctx, albumID)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			// compilations and appearances feature other artists' tracks too
+			for _, albumTrack := range fullAlbum.Tracks.Tracks {
+				for _, artist := range albumTrack.Artists {
+					if artist.ID == artistID {
+						ids = append(ids, albumTrack.ID)
+						break
+					}
+				}
+			}
+
+			if err := client.NextPage(ctx, &fullAlbum.Tracks); errors.Is(err, spotify.ErrNoMorePages) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+// seen tells whether the given track has already been found,
+// taking note of it otherwise
+func (releases releases) seen(track *spotify.FullTrack) bool {
+	var (
+		isrc     = track.ExternalIDs["isrc"]
+		title    = slug.Make(track.Name)
+		duration = int(track.Duration) / 1000
+	)
+	if len(isrc) > 0 && releases.isrcs[isrc] {
+		return true
+	}
+	for _, seenDuration := range releases.durations[title] {
+		if max(seenDuration-duration, duration-seenDuration) <= releasesDurationTolerance {
+			return true
+		}
+	}
+
+	if len(isrc) > 0 {
+		releases.isrcs[isrc] = true
+	}
+	releases.durations[title] = append(releases.durations[title], duration)
+	return false
+}
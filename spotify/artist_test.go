@@ -0,0 +1,210 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/zmb3/spotify/v2"
+)
+
+var (
+	artistID     = spotify.ID("0TnOYISbd1XYRBk9myaseg")
+	artist       = spotify.SimpleArtist{ID: artistID, Name: "Artist"}
+	artistTracks = map[spotify.ID]*spotify.FullTrack{
+		"1": artistTrack("1", "Title", 180, "ISRC1", artist),
+		"2": artistTrack("2", "Other Title", 200, "", artist),
+		"3": artistTrack("3", "Title (Single)", 180, "ISRC1", artist),
+		"4": artistTrack("4", "Someone Else's", 240, "ISRC4", spotify.SimpleArtist{ID: "other", Name: "Other"}),
+		"5": artistTrack("5", "Other Title", 201, "ISRC5", artist),
+		"6": artistTrack("6", "Title", 420, "ISRC6", artist),
+	}
+	artistAlbums = map[spotify.ID][]spotify.ID{
+		"album":       {"1", "2"},
+		"single":      {"3", "6"},
+		"compilation": {"4", "5"},
+	}
+)
+
+// artistTrack returns a full track of the given artists, as returned by Spotify
+func artistTrack(id spotify.ID, title string, duration int, isrc string, artists ...spotify.SimpleArtist) *spotify.FullTrack {
+	return &spotify.FullTrack{
+		SimpleTrack: spotify.SimpleTrack{ID: id, Name: title, Artists: artists, Duration: spotify.Numeric(duration * 1000)},
+		Album:       spotify.SimpleAlbum{Name: "Album", ReleaseDate: "1970"},
+		ExternalIDs: map[string]string{"isrc": isrc},
+	}
+}
+
+func BenchmarkArtist(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestArtist(&testing.T{})
+	}
+}
+
+func TestArtist(t *testing.T) {
+	var (
+		client  = testClient()
+		channel = make(chan interface{}, 10)
+		types   []spotify.AlbumType
+	)
+	defer close(channel)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func(_ *spotify.Client, _ context.Context, id spotify.ID, ts []spotify.AlbumType, _ ...spotify.RequestOption) (*spotify.SimpleAlbumPage, error) {
+			assert.Equal(t, artistID, id)
+			types = append([]spotify.AlbumType{}, ts...)
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "album"}, {ID: "single"}, {ID: "compilation"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func(_ *spotify.Client, _ context.Context, id spotify.ID, _ ...spotify.RequestOption) (*spotify.FullAlbum, error) {
+			album := &spotify.FullAlbum{}
+			for _, trackID := range artistAlbums[id] {
+				album.Tracks.Tracks = append(album.Tracks.Tracks, artistTracks[trackID].SimpleTrack)
+			}
+			return album, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetTracks", func(_ *spotify.Client, _ context.Context, ids []spotify.ID, _ ...spotify.RequestOption) ([]*spotify.FullTrack, error) {
+			assert.Equal(t, []spotify.ID{"1", "2", "3", "6", "5"}, ids)
+			fullTracks := []*spotify.FullTrack{}
+			for _, id := range ids {
+				fullTracks = append(fullTracks, artistTracks[id])
+			}
+			return fullTracks, nil
+		}).
+		Reset()
+
+	// testing
+	tracks, err := client.Artist("https://open.spotify.com/artist/"+artistID.String()+"?si=abc", AlbumTypes, channel)
+	assert.Nil(t, err)
+	assert.Equal(t, []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle, spotify.AlbumTypeCompilation, spotify.AlbumTypeAppearsOn}, types)
+	assert.Equal(t, 3, len(tracks))
+	assert.Equal(t, []string{"1", "2", "6"}, []string{tracks[0].ID, tracks[1].ID, tracks[2].ID})
+	for range tracks {
+		assert.Contains(t, tracks, <-channel)
+	}
+}
+
+func TestArtistByName(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+			return &spotify.SearchResult{Artists: &spotify.FullArtistPage{Artists: []spotify.FullArtist{{SimpleArtist: artist}}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func(_ *spotify.Client, _ context.Context, id spotify.ID, _ []spotify.AlbumType, _ ...spotify.RequestOption) (*spotify.SimpleAlbumPage, error) {
+			assert.Equal(t, artistID, id)
+			return &spotify.SimpleAlbumPage{}, nil
+		}).
+		Reset()
+
+	// testing
+	tracks, err := testClient().Artist("Artist", []string{"album"})
+	assert.Nil(t, err)
+	assert.Empty(t, tracks)
+}
+
+func TestArtistFailure(t *testing.T) {
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist(artistID.String(), []string{"live"})), "unsupported album type: live")
+}
+
+func TestArtistSearchFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist("Artist", AlbumTypes)), "ko")
+}
+
+func TestArtistNotFound(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+		return &spotify.SearchResult{}, nil
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist("Artist", AlbumTypes)), "artist not found: Artist")
+}
+
+func TestArtistGetArtistAlbumsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist(artistID.String(), AlbumTypes)), "ko")
+}
+
+func TestArtistGetArtistAlbumsNextPageFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "NextPage", func() error {
+			return errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist(artistID.String(), AlbumTypes)), "ko")
+}
+
+func TestArtistGetAlbumFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "album"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func() (*spotify.FullAlbum, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist(artistID.String(), AlbumTypes)), "ko")
+}
+
+func TestArtistGetAlbumNextPageFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "album"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func() (*spotify.FullAlbum, error) {
+			album := &spotify.FullAlbum{}
+			album.Tracks.Next = "http://0.0.0.0"
+			return album, nil
+		}).
+		Reset()
+
+	// testing
+	assert.True(t, errors.Is(util.ErrOnly(testClient().Artist(artistID.String(), AlbumTypes)), syscall.ECONNREFUSED))
+}
+
+func TestArtistGetTracksFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "album"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func() (*spotify.FullAlbum, error) {
+			return &spotify.FullAlbum{Tracks: spotify.SimpleTrackPage{Tracks: []spotify.SimpleTrack{artistTracks["1"].SimpleTrack}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetTracks", func() ([]*spotify.FullTrack, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Artist(artistID.String(), AlbumTypes)), "ko")
+}
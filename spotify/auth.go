@@ -5,18 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/arunsworld/nursery"
 	"github.com/streambinder/spotitube/util"
 	"github.com/streambinder/spotitube/util/cmd"
-	"github.com/thanhpk/randstr"
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 const (
@@ -24,9 +26,13 @@ const (
 	closeTabHTML  = "<!DOCTYPE html><html><head><script>open(location, '_self').close();</script></head></html>"
 )
 
+// ErrUserRequired is returned when asking a client authenticated via
+// client credentials for anything which belongs to a user
+var ErrUserRequired = errors.New("user authentication required, not available with client credentials")
+
 var (
 	port               = 65535
-	tokenPath          = util.CacheFile(TokenBasename)
+	tokenPath          = func() string { return util.ProfileFile(TokenBasename) }
 	fallbackSpotifyID  = ""
 	fallbackSpotifyKey = ""
 )
@@ -36,64 +42,36 @@ type Client struct {
 	authenticator *spotifyauth.Authenticator
 	state         string
 	cache         map[string]interface{}
+	app           bool // authenticated as the application, with no user
+}
+
+// tokenSource refreshes the token it is given once expired, persisting
+// each refreshed one, as refresh tokens may get rotated (PKCE ones always are)
+type tokenSource struct {
+	authenticator *spotifyauth.Authenticator
+	token         *oauth2.Token
+	lock          sync.Mutex
 }
 
 func Authenticate(urlProcessor func(string) error, callbacks ...string) (*Client, error) {
 	var (
-		client    Client
-		serverMux = http.NewServeMux()
-		server    = &http.Server{
-			Addr:              fmt.Sprintf("0.0.0.0:%d", port),
-			Handler:           serverMux,
-			ReadHeaderTimeout: 2 * time.Second,
-		}
-		state         = randstr.Hex(20)
-		callback      = "127.0.0.1"
-		clientChannel = make(chan *spotify.Client, 1)
+		client        *Client
+		serverMux     = http.NewServeMux()
+		server        = Server(serverMux, util.First(callbacks, "127.0.0.1") != "127.0.0.1")
+		clientChannel = make(chan *Client, 1)
 		errChannel    = make(chan error, 1)
+		flow          = NewFlow(func(client *Client, err error) {
+			clientChannel <- client
+			errChannel <- err
+		}, callbacks...)
 	)
 	defer close(clientChannel)
 	defer close(errChannel)
 
-	if len(callbacks) > 0 {
-		callback = callbacks[0]
-	}
-
-	authenticator := spotifyauth.New(
-		spotifyauth.WithRedirectURL(fmt.Sprintf("http://%s:%d/callback", callback, port)),
-		spotifyauth.WithScopes(
-			spotifyauth.ScopeUserLibraryRead,
-			spotifyauth.ScopeUserLibraryModify,
-			spotifyauth.ScopePlaylistReadPrivate,
-			spotifyauth.ScopePlaylistReadCollaborative,
-			spotifyauth.ScopePlaylistModifyPublic,
-			spotifyauth.ScopePlaylistModifyPrivate,
-		),
-		spotifyauth.WithClientID(util.Fallback(os.Getenv("SPOTIFY_ID"), fallbackSpotifyID)),
-		spotifyauth.WithClientSecret(util.Fallback(os.Getenv("SPOTIFY_KEY"), fallbackSpotifyKey)),
-	)
-	if client, err := Recover(authenticator, state); err == nil {
+	if client, err := flow.Recover(); err == nil {
 		return client, client.Persist()
 	}
-
-	serverMux.HandleFunc("/callback", func(writer http.ResponseWriter, request *http.Request) {
-		fmt.Fprintln(writer, closeTabHTML)
-		token, err := authenticator.Token(request.Context(), state, request)
-		if err != nil {
-			clientChannel <- nil
-			errChannel <- errors.New(http.StatusText(http.StatusForbidden))
-		} else if requestState := request.FormValue("state"); requestState != state {
-			clientChannel <- nil
-			errChannel <- errors.New(http.StatusText(http.StatusNotFound))
-		} else {
-			client := spotify.New(
-				authenticator.Client(request.Context(), token),
-				spotify.WithRetry(true),
-			)
-			clientChannel <- client
-			errChannel <- nil
-		}
-	})
+	flow.Handle(serverMux.HandleFunc)
 
 	if err := nursery.RunConcurrently(
 		// spawn web server to handle login redirection
@@ -110,7 +88,7 @@ func Authenticate(urlProcessor func(string) error, callbacks ...string) (*Client
 				return
 			}
 
-			if err := urlProcessor(authenticator.AuthURL(state)); err != nil {
+			if err := urlProcessor(flow.AuthURL()); err != nil {
 				ch <- err
 			}
 		},
@@ -120,18 +98,71 @@ func Authenticate(urlProcessor func(string) error, callbacks ...string) (*Client
 			if err != nil {
 				ch <- err
 			} else {
-				client = Client{c, authenticator, state, make(map[string]interface{})}
+				client = c
 			}
 			ch <- server.Shutdown(ctx)
 		}); err != nil {
 		return nil, err
 	}
 
-	return &client, client.Persist()
+	return client, client.Persist()
+}
+
+// AuthenticateApp returns a client authenticated as the application itself,
+// via client credentials: no user has to log in, but nothing belonging to
+// users can be accessed, e.g. their library or their private playlists
+func AuthenticateApp() (*Client, error) {
+	secret := util.Fallback(os.Getenv("SPOTIFY_KEY"), fallbackSpotifyKey)
+	if len(secret) == 0 {
+		return nil, errors.New("client credentials require a client secret")
+	}
+
+	var (
+		ctx    = context.Background()
+		config = &clientcredentials.Config{
+			ClientID:     util.Fallback(os.Getenv("SPOTIFY_ID"), fallbackSpotifyID),
+			ClientSecret: secret,
+			TokenURL:     spotifyauth.TokenURL,
+		}
+	)
+	token, err := config.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{spotify.New(
+		oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, config.TokenSource(ctx))),
+		spotify.WithRetry(true),
+	), nil, "", make(map[string]interface{}), true}, nil
+}
+
+// Server returns a web server listening on the very same port
+// Spotify redirects to during authentication, serving the given handler:
+// it listens on loopback only, unless it has to be reached remotely
+func Server(handler http.Handler, remote bool) *http.Server {
+	host := "127.0.0.1"
+	if remote {
+		host = "0.0.0.0"
+	}
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", host, port),
+		Handler:           handler,
+		ReadHeaderTimeout: 2 * time.Second,
+	}
 }
 
 func Recover(authenticator *spotifyauth.Authenticator, state string) (*Client, error) {
-	data, err := os.ReadFile(tokenPath)
+	token, err := StoredToken()
+	if err != nil {
+		return nil, err
+	}
+	return newClient(authenticator, state, token), nil
+}
+
+// StoredToken returns the token persisted by a previous
+// authentication as it is, i.e. with no refresh whatsoever
+func StoredToken() (*oauth2.Token, error) {
+	data, err := os.ReadFile(tokenPath())
 	if err != nil {
 		return nil, err
 	}
@@ -140,15 +171,44 @@ func Recover(authenticator *spotifyauth.Authenticator, state string) (*Client, e
 	if err := json.Unmarshal(data, &token); err != nil {
 		return nil, err
 	}
+	return &token, nil
+}
 
+func newClient(authenticator *spotifyauth.Authenticator, state string, token *oauth2.Token) *Client {
 	return &Client{spotify.New(
-		authenticator.Client(context.Background(), &token),
+		oauth2.NewClient(context.Background(), &tokenSource{authenticator: authenticator, token: token}),
 		spotify.WithRetry(true),
-	), authenticator, state, make(map[string]interface{})}, nil
+	), authenticator, state, make(map[string]interface{}), false}
+}
+
+func (source *tokenSource) Token() (*oauth2.Token, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+	if source.token.Valid() {
+		return source.token, nil
+	}
+
+	token, err := source.authenticator.RefreshToken(context.Background(), source.token)
+	if err != nil {
+		return nil, err
+	}
+	source.token = token
+	// the refreshed token is valid anyway: failing to persist it
+	// only means having to authenticate again next time
+	util.ErrSuppress(persist(token))
+	return token, nil
+}
+
+// Logout drops the session persisted by a previous authentication, if any
+func Logout() error {
+	if err := os.Remove(tokenPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
 }
 
 func (client *Client) Persist() error {
-	if err := os.MkdirAll(filepath.Dir(tokenPath), 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(tokenPath()), 0o755); err != nil {
 		return err
 	}
 
@@ -156,8 +216,11 @@ func (client *Client) Persist() error {
 	if err != nil {
 		return err
 	}
+	return persist(token)
+}
 
-	file, err := os.OpenFile(tokenPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+func persist(token *oauth2.Token) error {
+	file, err := os.OpenFile(tokenPath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return err
 	}
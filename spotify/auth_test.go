@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"testing"
@@ -22,6 +23,7 @@ import (
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 const (
@@ -37,7 +39,7 @@ var (
 )
 
 func testClient() *Client {
-	return &Client{spotify.New(http.DefaultClient), &spotifyauth.Authenticator{}, "", make(map[string]interface{})}
+	return &Client{spotify.New(http.DefaultClient), &spotifyauth.Authenticator{}, "", make(map[string]interface{}), false}
 }
 
 func getPort() int {
@@ -439,3 +441,84 @@ func TestAuthenticateServerUnserving(t *testing.T) {
 	// testing
 	assert.EqualError(t, util.ErrOnly(Authenticate(nil)), "ko")
 }
+
+func TestServer(t *testing.T) {
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", port), Server(http.NewServeMux(), false).Addr)
+	assert.Equal(t, fmt.Sprintf("0.0.0.0:%d", port), Server(http.NewServeMux(), true).Addr)
+}
+
+func TestTokenSource(t *testing.T) {
+	defaultTokenPath := tokenPath
+	t.Cleanup(func() { tokenPath = defaultTokenPath })
+	path := filepath.Join(t.TempDir(), TokenBasename)
+	tokenPath = func() string { return path }
+
+	var (
+		expired   = &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+		refreshed = &oauth2.Token{AccessToken: "access", RefreshToken: "rotated", Expiry: time.Now().Add(time.Hour)}
+		refreshes int
+	)
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(spotifyauth.Authenticator{}, "RefreshToken", func() (*oauth2.Token, error) {
+		refreshes++
+		return refreshed, nil
+	}).Reset()
+
+	// testing
+	source := &tokenSource{authenticator: &spotifyauth.Authenticator{}, token: expired}
+	for i := 0; i < 2; i++ {
+		token, err := source.Token()
+		assert.Nil(t, err)
+		assert.Equal(t, refreshed, token)
+	}
+	assert.Equal(t, 1, refreshes)
+
+	var persisted oauth2.Token
+	assert.Nil(t, json.Unmarshal(util.ErrWrap([]byte{})(os.ReadFile(path)), &persisted))
+	assert.Equal(t, "rotated", persisted.RefreshToken)
+}
+
+func TestTokenSourceFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(spotifyauth.Authenticator{}, "RefreshToken", func() (*oauth2.Token, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	source := &tokenSource{authenticator: &spotifyauth.Authenticator{}, token: &oauth2.Token{}}
+	assert.EqualError(t, util.ErrOnly(source.Token()), "ko")
+}
+
+func TestAuthenticateApp(t *testing.T) {
+	t.Setenv("SPOTIFY_KEY", "secret")
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&clientcredentials.Config{}, "Token", func() (*oauth2.Token, error) {
+		return &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}, nil
+	}).Reset()
+
+	// testing
+	client, err := AuthenticateApp()
+	assert.Nil(t, err)
+	assert.True(t, client.app)
+}
+
+func TestAuthenticateAppFailure(t *testing.T) {
+	t.Setenv("SPOTIFY_KEY", "secret")
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&clientcredentials.Config{}, "Token", func() (*oauth2.Token, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(AuthenticateApp()), "ko")
+}
+
+func TestAuthenticateAppNoSecret(t *testing.T) {
+	t.Setenv("SPOTIFY_KEY", "")
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(AuthenticateApp()), "client credentials require a client secret")
+}
@@ -0,0 +1,196 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streambinder/spotitube/util"
+	"github.com/thanhpk/randstr"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	scopes = []string{
+		spotifyauth.ScopeUserLibraryRead,
+		spotifyauth.ScopeUserLibraryModify,
+		spotifyauth.ScopeUserFollowRead,
+		spotifyauth.ScopePlaylistReadPrivate,
+		spotifyauth.ScopePlaylistReadCollaborative,
+		spotifyauth.ScopePlaylistModifyPublic,
+		spotifyauth.ScopePlaylistModifyPrivate,
+	}
+	landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Spotitube</title></head>
+<body>
+<h1>Spotitube</h1>
+{{if .Session}}
+<p>Authenticated as <b>{{.Session.User}}</b>, with a session expiring on {{.Session.Expiry.Format "2006-01-02 15:04:05 MST"}}.</p>
+<p>Scopes: {{range .Session.Scopes}}<code>{{.}}</code> {{end}}</p>
+<form method="post" action="/logout"><input type="hidden" name="state" value="{{.State}}"><button type="submit">Logout</button></form>
+{{else}}
+<p>Not authenticated{{with .Error}}: {{.}}{{end}}.</p>
+<p><a href="{{.URL}}">Authenticate with Spotify</a></p>
+{{end}}
+</body>
+</html>
+`))
+)
+
+// Session describes an established Spotify session
+type Session struct {
+	User   string
+	Expiry time.Time
+	Scopes []string
+}
+
+// Flow is the web authentication to Spotify: it serves a landing page showing
+// the status of the session, along with either a link to authenticate or a
+// button to logout, and the callback Spotify redirects to once authenticated
+type Flow struct {
+	authenticator *spotifyauth.Authenticator
+	state         string
+	verifier      string // PKCE one, if no client secret is configured
+	client        *Client
+	authenticated func(*Client, error)
+	lock          sync.RWMutex
+}
+
+// NewFlow returns a flow which calls the given function on each authentication
+// attempt: the callback is expected to be reached at the given host (if any).
+// With no client secret configured, the flow goes through PKCE
+func NewFlow(authenticated func(*Client, error), callbacks ...string) *Flow {
+	secret := util.Fallback(os.Getenv("SPOTIFY_KEY"), fallbackSpotifyKey)
+	flow := &Flow{
+		authenticator: spotifyauth.New(
+			spotifyauth.WithRedirectURL(fmt.Sprintf("http://%s:%d/callback", util.First(callbacks, "127.0.0.1"), port)),
+			spotifyauth.WithScopes(scopes...),
+			spotifyauth.WithClientID(util.Fallback(os.Getenv("SPOTIFY_ID"), fallbackSpotifyID)),
+			spotifyauth.WithClientSecret(secret),
+		),
+		state:         randstr.Hex(20),
+		authenticated: authenticated,
+	}
+	if len(secret) == 0 {
+		flow.verifier = oauth2.GenerateVerifier()
+	}
+	return flow
+}
+
+// Recover restores the session persisted by a previous authentication
+func (flow *Flow) Recover() (*Client, error) {
+	client, err := Recover(flow.authenticator, flow.state)
+	if err != nil {
+		return nil, err
+	}
+
+	flow.lock.Lock()
+	defer flow.lock.Unlock()
+	flow.client = client
+	return client, nil
+}
+
+// Client returns the client of the current session, if any
+func (flow *Flow) Client() *Client {
+	flow.lock.RLock()
+	defer flow.lock.RUnlock()
+	return flow.client
+}
+
+// AuthURL returns the URL to authenticate at
+func (flow *Flow) AuthURL() string {
+	return flow.authenticator.AuthURL(flow.state, flow.pkce(oauth2.S256ChallengeOption)...)
+}
+
+// pkce returns the given option for the verifier, if going through PKCE
+func (flow *Flow) pkce(option func(string) oauth2.AuthCodeOption) []oauth2.AuthCodeOption {
+	if len(flow.verifier) == 0 {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{option(flow.verifier)}
+}
+
+// Handle registers the pages of the flow by means of the given function,
+// i.e. the HandleFunc method of a mux or anything wrapping it
+func (flow *Flow) Handle(handle func(string, func(http.ResponseWriter, *http.Request))) {
+	handle("GET /{$}", flow.landing)
+	handle("/callback", flow.callback)
+	handle("POST /logout", flow.logout)
+}
+
+func (flow *Flow) landing(writer http.ResponseWriter, request *http.Request) {
+	data := struct {
+		Session *Session
+		Error   error
+		URL     string
+		State   string
+	}{URL: flow.AuthURL(), State: flow.state}
+	if client := flow.Client(); client != nil {
+		data.Session, data.Error = client.Session(request.Context())
+	}
+	util.ErrSuppress(landingTemplate.Execute(writer, data))
+}
+
+func (flow *Flow) callback(writer http.ResponseWriter, request *http.Request) {
+	fmt.Fprintln(writer, closeTabHTML)
+	token, err := flow.authenticator.Token(request.Context(), flow.state, request, flow.pkce(oauth2.VerifierOption)...)
+	if err != nil {
+		flow.authenticated(nil, errors.New(http.StatusText(http.StatusForbidden)))
+		return
+	} else if requestState := request.FormValue("state"); requestState != flow.state {
+		flow.authenticated(nil, errors.New(http.StatusText(http.StatusNotFound)))
+		return
+	}
+
+	client := newClient(flow.authenticator, flow.state, token)
+	flow.lock.Lock()
+	flow.client = client
+	flow.lock.Unlock()
+	flow.authenticated(client, nil)
+}
+
+func (flow *Flow) logout(writer http.ResponseWriter, request *http.Request) {
+	// the state is only known to the landing page, which
+	// prevents other sites from forging logout requests
+	if request.PostFormValue("state") != flow.state {
+		http.Error(writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if err := Logout(); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	flow.lock.Lock()
+	flow.client = nil
+	flow.lock.Unlock()
+	http.Redirect(writer, request, "/", http.StatusSeeOther)
+}
+
+// Session returns the status of the session of the client
+func (client *Client) Session(ctx context.Context) (*Session, error) {
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := client.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{util.Fallback(user.DisplayName, user.ID), token.Expiry, scopes}
+	// tokens tell the scopes actually granted only when fresh
+	if granted, ok := token.Extra("scope").(string); ok && len(granted) > 0 {
+		session.Scopes = strings.Fields(granted)
+	}
+	return session, nil
+}
@@ -0,0 +1,206 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+func BenchmarkFlow(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestFlow(&testing.T{})
+	}
+}
+
+// testFlow issues the given request to the pages of the given flow
+func testFlow(flow *Flow, method, target string) *httptest.ResponseRecorder {
+	var (
+		mux      = http.NewServeMux()
+		recorder = httptest.NewRecorder()
+	)
+	flow.Handle(mux.HandleFunc)
+	mux.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder
+}
+
+// testFlowLogout submits the logout form of the given flow with the given state
+func testFlowLogout(flow *Flow, state string) *httptest.ResponseRecorder {
+	var (
+		mux      = http.NewServeMux()
+		recorder = httptest.NewRecorder()
+		request  = httptest.NewRequest(http.MethodPost, "/logout", strings.NewReader(url.Values{"state": {state}}.Encode()))
+	)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	flow.Handle(mux.HandleFunc)
+	mux.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestFlow(t *testing.T) {
+	var (
+		clients []*Client
+		flow    = NewFlow(func(client *Client, err error) {
+			assert.Nil(t, err)
+			clients = append(clients, client)
+		})
+	)
+	defaultTokenPath := tokenPath
+	t.Cleanup(func() { tokenPath = defaultTokenPath })
+	path := filepath.Join(t.TempDir(), TokenBasename)
+	tokenPath = func() string { return path }
+	assert.Nil(t, os.WriteFile(path, []byte(token), 0o600))
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(spotifyauth.Authenticator{}, "Token", func() (*oauth2.Token, error) {
+			return &oauth2.Token{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{User: spotify.User{DisplayName: "User"}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Token", func() (*oauth2.Token, error) {
+			return (&oauth2.Token{Expiry: time.Now()}).WithExtra(map[string]interface{}{"scope": "scope-a scope-b"}), nil
+		}).
+		Reset()
+
+	// testing
+	assert.Contains(t, testFlow(flow, http.MethodGet, "/").Body.String(), "Authenticate with Spotify")
+	assert.Equal(t, http.StatusOK, testFlow(flow, http.MethodGet, "/callback?code=C0D3&state="+flow.state).Code)
+	assert.Equal(t, 1, len(clients))
+	assert.Equal(t, clients[0], flow.Client())
+
+	body := testFlow(flow, http.MethodGet, "/").Body.String()
+	assert.Contains(t, body, "Authenticated as <b>User</b>")
+	assert.Contains(t, body, "<code>scope-b</code>")
+	assert.Contains(t, body, "Logout")
+	assert.Contains(t, body, `name="state" value="`+flow.state+`"`)
+
+	assert.Equal(t, http.StatusForbidden, testFlow(flow, http.MethodPost, "/logout").Code)
+	assert.Equal(t, http.StatusForbidden, testFlowLogout(flow, "forged").Code)
+	assert.Equal(t, http.StatusForbidden, testFlow(flow, http.MethodPost, "/logout?state="+flow.state).Code)
+	assert.NotNil(t, flow.Client())
+	assert.FileExists(t, path)
+	assert.Equal(t, http.StatusSeeOther, testFlowLogout(flow, flow.state).Code)
+	assert.Nil(t, flow.Client())
+	assert.NoFileExists(t, path)
+	assert.Equal(t, http.StatusSeeOther, testFlowLogout(flow, flow.state).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, testFlow(flow, http.MethodGet, "/logout").Code)
+	assert.Equal(t, http.StatusNotFound, testFlow(flow, http.MethodGet, "/unknown").Code)
+}
+
+func TestFlowRecover(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(Recover, func() (*Client, error) {
+		return testClient(), nil
+	}).Reset()
+
+	// testing
+	flow := NewFlow(nil)
+	client, err := flow.Recover()
+	assert.Nil(t, err)
+	assert.Equal(t, client, flow.Client())
+}
+
+func TestFlowRecoverFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(Recover, func() (*Client, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	flow := NewFlow(nil)
+	_, err := flow.Recover()
+	assert.EqualError(t, err, "ko")
+	assert.Nil(t, flow.Client())
+}
+
+func TestFlowSessionFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(Recover, func() (*Client, error) {
+			return testClient(), nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	flow := NewFlow(nil)
+	_, err := flow.Recover()
+	assert.Nil(t, err)
+	assert.Contains(t, testFlow(flow, http.MethodGet, "/").Body.String(), "Not authenticated: ko")
+}
+
+func TestFlowLogoutFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.Remove, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	flow := NewFlow(nil)
+	assert.Equal(t, http.StatusInternalServerError, testFlowLogout(flow, flow.state).Code)
+}
+
+func TestSessionTokenFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{User: spotify.User{ID: "user"}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Token", func() (*oauth2.Token, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	_, err := testClient().Session(context.Background())
+	assert.EqualError(t, err, "ko")
+}
+
+func TestSessionScopes(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{User: spotify.User{ID: "user"}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "Token", func() (*oauth2.Token, error) {
+			return &oauth2.Token{}, nil
+		}).
+		Reset()
+
+	// testing
+	session, err := testClient().Session(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "user", session.User)
+	assert.Equal(t, scopes, session.Scopes)
+}
+
+func TestFlowPKCE(t *testing.T) {
+	t.Setenv("SPOTIFY_KEY", "")
+
+	// testing
+	flow := NewFlow(nil)
+	assert.NotEmpty(t, flow.verifier)
+	assert.Contains(t, flow.AuthURL(), "code_challenge="+oauth2.S256ChallengeFromVerifier(flow.verifier))
+	assert.Contains(t, flow.AuthURL(), "code_challenge_method=S256")
+
+	t.Setenv("SPOTIFY_KEY", "secret")
+	flow = NewFlow(nil)
+	assert.Empty(t, flow.verifier)
+	assert.NotContains(t, flow.AuthURL(), "code_challenge")
+}
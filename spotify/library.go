@@ -3,11 +3,28 @@ package spotify
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/streambinder/spotitube/entity"
 	"github.com/zmb3/spotify/v2"
 )
 
-func (client *Client) Library(limit int, channels ...chan interface{}) error {
+// releasesTypes are the types of the releases of the followed artists
+// looked into for new ones: appearances and compilations would bring in
+// tracks of other artists too
+var releasesTypes = []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}
+
+const libraryBatchSize = 50 // the most tracks Spotify saves at once
+
+// Library fetches the tracks saved into the library, the latest saved
+// first, stopping at the given number of tracks, if positive, or at
+// the first track saved before the given time
+func (client *Client) Library(limit int, since time.Time, channels ...chan interface{}) error {
+	if client.app {
+		return ErrUserRequired
+	}
+
 	var (
 		ctx          = context.Background()
 		library, err = client.CurrentUsersTracks(ctx)
@@ -20,6 +37,11 @@ func (client *Client) Library(limit int, channels ...chan interface{}) error {
 	for {
 		for _, libraryTrack := range library.Tracks {
 			track := trackEntity(libraryTrack.FullTrack)
+			track.AddedAt = addedAt(libraryTrack.AddedAt)
+			// the rest of the library is even older
+			if track.AddedAt.Before(since) {
+				return nil
+			}
 			for _, ch := range channels {
 				ch <- track
 			}
@@ -37,3 +59,152 @@ func (client *Client) Library(limit int, channels ...chan interface{}) error {
 
 	return nil
 }
+
+// LibrarySnapshot returns an identifier of the current state of the library:
+// as it has no version of its own, it is made of its size and latest track
+func (client *Client) LibrarySnapshot() (string, error) {
+	if client.app {
+		return "", ErrUserRequired
+	}
+
+	library, err := client.CurrentUsersTracks(context.Background(), spotify.Limit(1))
+	if err != nil {
+		return "", err
+	}
+
+	if len(library.Tracks) == 0 {
+		return fmt.Sprint(library.Total), nil
+	}
+	return fmt.Sprintf("%d:%s:%s", library.Total, library.Tracks[0].ID, library.Tracks[0].AddedAt), nil
+}
+
+// Like saves the tracks of the given IDs into the library, i.e. among the liked
+// songs: those which are already there are left as they are
+func (client *Client) Like(ids ...string) error {
+	if client.app {
+		return ErrUserRequired
+	}
+
+	ctx := context.Background()
+	for offset := 0; offset < len(ids); offset += libraryBatchSize {
+		batch := []spotify.ID{}
+		for _, id := range ids[offset:min(offset+libraryBatchSize, len(ids))] {
+			batch = append(batch, spotify.ID(id))
+		}
+		if err := client.AddTracksToLibrary(ctx, batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavedAlbums fetches the albums saved into the library, each one whole
+func (client *Client) SavedAlbums(channels ...chan interface{}) ([]*entity.Album, error) {
+	if client.app {
+		return nil, ErrUserRequired
+	}
+
+	var (
+		ctx        = context.Background()
+		saved, err = client.CurrentUsersAlbums(ctx, spotify.Limit(50))
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := []*entity.Album{}
+	for {
+		for _, savedAlbum := range saved.Albums {
+			album, err := client.albumTracks(ctx, &savedAlbum.FullAlbum, channels...)
+			if err != nil {
+				return nil, err
+			}
+			albums = append(albums, album)
+		}
+
+		if err := client.NextPage(ctx, saved); errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return albums, nil
+}
+
+// NewReleases fetches the albums and singles of the followed artists
+// released on the day of the given time or later, each one whole
+func (client *Client) NewReleases(since time.Time, channels ...chan interface{}) ([]*entity.Album, error) {
+	if client.app {
+		return nil, ErrUserRequired
+	}
+
+	artists, err := client.followedArtists()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx    = context.Background()
+		day    = since.UTC().Truncate(24 * time.Hour)
+		seen   = make(map[spotify.ID]bool) // releases shared by followed artists
+		albums = []*entity.Album{}
+	)
+	for _, artistID := range artists {
+		releases, err := client.GetArtistAlbums(ctx, artistID, releasesTypes, spotify.Limit(50))
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			for _, release := range releases.Albums {
+				if seen[release.ID] || release.ReleaseDateTime().Before(day) {
+					continue
+				}
+				seen[release.ID] = true
+
+				fullAlbum, err := client.GetAlbum(ctx, release.ID)
+				if err != nil {
+					return nil, err
+				}
+				album, err := client.albumTracks(ctx, fullAlbum, channels...)
+				if err != nil {
+					return nil, err
+				}
+				albums = append(albums, album)
+			}
+
+			if err := client.NextPage(ctx, releases); errors.Is(err, spotify.ErrNoMorePages) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return albums, nil
+}
+
+// followedArtists returns the IDs of the artists the user follows
+func (client *Client) followedArtists() ([]spotify.ID, error) {
+	var (
+		ctx     = context.Background()
+		ids     = []spotify.ID{}
+		options = []spotify.RequestOption{spotify.Limit(50)}
+	)
+	for {
+		// followed artists are paged by cursor, rather than by offset
+		followed, err := client.CurrentUsersFollowedArtists(ctx, options...)
+		if err != nil {
+			return nil, err
+		}
+		for _, artist := range followed.Artists {
+			ids = append(ids, artist.ID)
+		}
+
+		if len(followed.Next) == 0 || len(followed.Cursor.After) == 0 {
+			return ids, nil
+		}
+		options = []spotify.RequestOption{spotify.Limit(50), spotify.After(followed.Cursor.After)}
+	}
+}
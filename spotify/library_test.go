@@ -1,7 +1,9 @@
 package spotify
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"syscall"
 	"testing"
 	"time"
@@ -13,11 +15,17 @@ import (
 	"github.com/zmb3/spotify/v2"
 )
 
-var library = &spotify.SavedTrackPage{
-	Tracks: []spotify.SavedTrack{
-		{FullTrack: fullTrack},
-	},
-}
+var (
+	library = &spotify.SavedTrackPage{
+		Tracks: []spotify.SavedTrack{
+			{FullTrack: fullTrack},
+		},
+	}
+	savedAlbum = spotify.FullAlbum{
+		SimpleAlbum: spotify.SimpleAlbum{Name: "Album", ID: "123"},
+		Tracks:      spotify.SimpleTrackPage{Tracks: []spotify.SimpleTrack{fullTrack.SimpleTrack}},
+	}
+)
 
 func BenchmarkLibrary(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -35,7 +43,7 @@ func TestLibrary(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Nil(t, testClient().Library(0))
+	assert.Nil(t, testClient().Library(0, time.Time{}))
 }
 
 func TestLibraryChannel(t *testing.T) {
@@ -50,11 +58,39 @@ func TestLibraryChannel(t *testing.T) {
 	// testing
 	channel := make(chan interface{}, 1)
 	defer close(channel)
-	err := testClient().Library(1, channel)
+	err := testClient().Library(1, time.Time{}, channel)
 	assert.Nil(t, err)
 	assert.Equal(t, library.Tracks[0].Name, ((<-channel).(*entity.Track)).Title)
 }
 
+func TestLibrarySince(t *testing.T) {
+	var (
+		channel = make(chan interface{}, 2)
+		page    = &spotify.SavedTrackPage{
+			Tracks: []spotify.SavedTrack{
+				{AddedAt: "2026-01-03T00:00:00Z", FullTrack: fullTrack},
+				{AddedAt: "2026-01-01T00:00:00Z", FullTrack: fullTrack},
+			},
+		}
+	)
+	defer close(channel)
+	// older tracks would not be paged through
+	page.Next = "http://0.0.0.0"
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersTracks", func() (*spotify.SavedTrackPage, error) {
+			return page, nil
+		}).
+		Reset()
+
+	// testing
+	assert.Nil(t, testClient().Library(0, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), channel))
+	assert.Equal(t, 1, len(channel))
+	assert.Equal(t, time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), ((<-channel).(*entity.Track)).AddedAt)
+}
+
 func TestLibraryFailure(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
@@ -65,7 +101,7 @@ func TestLibraryFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testClient().Library(0)), "ko")
+	assert.EqualError(t, util.ErrOnly(testClient().Library(0, time.Time{})), "ko")
 }
 
 func TestLibraryNextPageFailure(t *testing.T) {
@@ -84,5 +120,272 @@ func TestLibraryNextPageFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.True(t, errors.Is(util.ErrOnly(client.Library(0)), syscall.ECONNREFUSED))
+	assert.True(t, errors.Is(util.ErrOnly(client.Library(0, time.Time{})), syscall.ECONNREFUSED))
+}
+
+func TestLibrarySnapshot(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersTracks", func() (*spotify.SavedTrackPage, error) {
+		page := &spotify.SavedTrackPage{Tracks: []spotify.SavedTrack{{AddedAt: "2006-01-02T15:04:05Z", FullTrack: fullTrack}}}
+		page.Total = 10
+		return page, nil
+	}).Reset()
+
+	// testing
+	snapshot, err := testClient().LibrarySnapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, "10:"+fullTrack.ID.String()+":2006-01-02T15:04:05Z", snapshot)
+}
+
+func TestLibrarySnapshotEmpty(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersTracks", func() (*spotify.SavedTrackPage, error) {
+		return &spotify.SavedTrackPage{}, nil
+	}).Reset()
+
+	// testing
+	snapshot, err := testClient().LibrarySnapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, "0", snapshot)
+}
+
+func TestLibrarySnapshotFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersTracks", func() (*spotify.SavedTrackPage, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().LibrarySnapshot()), "ko")
+}
+
+func TestLibraryApp(t *testing.T) {
+	client := testClient()
+	client.app = true
+
+	// testing
+	assert.ErrorIs(t, client.Library(0, time.Time{}), ErrUserRequired)
+	assert.ErrorIs(t, util.ErrOnly(client.LibrarySnapshot()), ErrUserRequired)
+	assert.ErrorIs(t, util.ErrOnly(client.SavedAlbums()), ErrUserRequired)
+	assert.ErrorIs(t, util.ErrOnly(client.NewReleases(time.Now())), ErrUserRequired)
+	assert.ErrorIs(t, client.Like("123"), ErrUserRequired)
+}
+
+func TestLike(t *testing.T) {
+	var (
+		ids     []string
+		batches [][]spotify.ID
+	)
+	for i := 0; i < 120; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "AddTracksToLibrary", func(_ *spotify.Client, _ context.Context, ids ...spotify.ID) error {
+		batches = append(batches, append([]spotify.ID{}, ids...))
+		return nil
+	}).Reset()
+
+	// testing
+	assert.Nil(t, testClient().Like(ids...))
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, []int{50, 50, 20}, []int{len(batches[0]), len(batches[1]), len(batches[2])})
+	assert.Equal(t, spotify.ID("0"), batches[0][0])
+	assert.Equal(t, spotify.ID("119"), batches[2][19])
+}
+
+func TestLikeFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "AddTracksToLibrary", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, testClient().Like("123"), "ko")
+}
+
+func TestSavedAlbums(t *testing.T) {
+	channel := make(chan interface{}, 1)
+	defer close(channel)
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersAlbums", func() (*spotify.SavedAlbumPage, error) {
+		return &spotify.SavedAlbumPage{Albums: []spotify.SavedAlbum{{FullAlbum: savedAlbum}}}, nil
+	}).Reset()
+
+	// testing
+	albums, err := testClient().SavedAlbums(channel)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(albums))
+	assert.Equal(t, savedAlbum.ID.String(), albums[0].ID)
+	assert.Equal(t, albums[0].Tracks[0], <-channel)
+	assert.Equal(t, int(fullTrack.TrackNumber), albums[0].Tracks[0].Number)
+}
+
+func TestSavedAlbumsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersAlbums", func() (*spotify.SavedAlbumPage, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().SavedAlbums()), "ko")
+}
+
+func TestSavedAlbumsTracksFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersAlbums", func() (*spotify.SavedAlbumPage, error) {
+			album := spotify.SavedAlbum{}
+			album.Tracks.Next = "http://0.0.0.0"
+			return &spotify.SavedAlbumPage{Albums: []spotify.SavedAlbum{album}}, nil
+		}).
+		Reset()
+
+	// testing
+	assert.True(t, errors.Is(util.ErrOnly(testClient().SavedAlbums()), syscall.ECONNREFUSED))
+}
+
+func TestSavedAlbumsNextPageFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersAlbums", func() (*spotify.SavedAlbumPage, error) {
+			page := &spotify.SavedAlbumPage{}
+			page.Next = "http://0.0.0.0"
+			return page, nil
+		}).
+		Reset()
+
+	// testing
+	assert.True(t, errors.Is(util.ErrOnly(testClient().SavedAlbums()), syscall.ECONNREFUSED))
+}
+
+func TestNewReleases(t *testing.T) {
+	var (
+		channel = make(chan interface{}, 10)
+		cursors []string
+	)
+	defer close(channel)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func(_ *spotify.Client, _ context.Context, opts ...spotify.RequestOption) (*spotify.FullArtistCursorPage, error) {
+			page := &spotify.FullArtistCursorPage{}
+			if len(opts) == 1 {
+				page.Artists = []spotify.FullArtist{{SimpleArtist: spotify.SimpleArtist{ID: "artist1"}}}
+				page.Next, page.Cursor.After = "next", "artist1"
+			} else {
+				page.Artists = []spotify.FullArtist{{SimpleArtist: spotify.SimpleArtist{ID: "artist2"}}}
+			}
+			cursors = append(cursors, page.Cursor.After)
+			return page, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func(_ *spotify.Client, _ context.Context, id spotify.ID, ts []spotify.AlbumType, _ ...spotify.RequestOption) (*spotify.SimpleAlbumPage, error) {
+			assert.Equal(t, []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}, ts)
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{
+				{ID: "old", ReleaseDate: "2023-04-14", ReleaseDatePrecision: "day"},
+				{ID: "today", ReleaseDate: "2023-04-15", ReleaseDatePrecision: "day"},
+				{ID: spotify.ID("new-" + id), ReleaseDate: "2023-05-01", ReleaseDatePrecision: "day"},
+			}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func(_ *spotify.Client, _ context.Context, id spotify.ID, _ ...spotify.RequestOption) (*spotify.FullAlbum, error) {
+			album := savedAlbum
+			album.ID = id
+			return &album, nil
+		}).
+		Reset()
+
+	// testing
+	albums, err := testClient().NewReleases(time.Date(2023, 4, 15, 12, 0, 0, 0, time.UTC), channel)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"artist1", ""}, cursors)
+	assert.Equal(t, 3, len(albums))
+	assert.Equal(t, []string{"today", "new-artist1", "new-artist2"}, []string{albums[0].ID, albums[1].ID, albums[2].ID})
+	for range albums {
+		assert.Equal(t, fullTrack.Name, ((<-channel).(*entity.Track)).Title)
+	}
+}
+
+func TestNewReleasesFollowedArtistsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func() (*spotify.FullArtistCursorPage, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().NewReleases(time.Now())), "ko")
+}
+
+func TestNewReleasesGetArtistAlbumsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func() (*spotify.FullArtistCursorPage, error) {
+			return &spotify.FullArtistCursorPage{Artists: []spotify.FullArtist{{SimpleArtist: artist}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().NewReleases(time.Now())), "ko")
+}
+
+func TestNewReleasesGetAlbumFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func() (*spotify.FullArtistCursorPage, error) {
+			return &spotify.FullArtistCursorPage{Artists: []spotify.FullArtist{{SimpleArtist: artist}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "new", ReleaseDate: "2999", ReleaseDatePrecision: "year"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func() (*spotify.FullAlbum, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().NewReleases(time.Now())), "ko")
+}
+
+func TestNewReleasesTracksFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func() (*spotify.FullArtistCursorPage, error) {
+			return &spotify.FullArtistCursorPage{Artists: []spotify.FullArtist{{SimpleArtist: artist}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			return &spotify.SimpleAlbumPage{Albums: []spotify.SimpleAlbum{{ID: "new", ReleaseDate: "2999", ReleaseDatePrecision: "year"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetAlbum", func() (*spotify.FullAlbum, error) {
+			album := &spotify.FullAlbum{}
+			album.Tracks.Next = "http://0.0.0.0"
+			return album, nil
+		}).
+		Reset()
+
+	// testing
+	assert.True(t, errors.Is(util.ErrOnly(testClient().NewReleases(time.Now())), syscall.ECONNREFUSED))
+}
+
+func TestNewReleasesNextPageFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersFollowedArtists", func() (*spotify.FullArtistCursorPage, error) {
+			return &spotify.FullArtistCursorPage{Artists: []spotify.FullArtist{{SimpleArtist: artist}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetArtistAlbums", func() (*spotify.SimpleAlbumPage, error) {
+			page := &spotify.SimpleAlbumPage{}
+			page.Next = "http://0.0.0.0"
+			return page, nil
+		}).
+		Reset()
+
+	// testing
+	assert.True(t, errors.Is(util.ErrOnly(testClient().NewReleases(time.Now())), syscall.ECONNREFUSED))
 }
@@ -3,6 +3,9 @@ package spotify
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/streambinder/spotitube/entity/playlist"
@@ -19,10 +22,16 @@ func playlistEntity(fullPlaylist spotify.FullPlaylist) *playlist.Playlist {
 		Name:          fullPlaylist.Name,
 		Owner:         fullPlaylist.Owner.ID,
 		Collaborative: fullPlaylist.Collaborative,
+		SnapshotID:    fullPlaylist.SnapshotID,
 	}
 }
 
 func (client *Client) personalPlaylistNameToID(target string) (spotify.ID, error) {
+	// with no user, there are no personal playlists
+	if client.app {
+		return id(target), nil
+	}
+
 	playlistsMap, ok := client.cache[personalPlaylistsCacheID]
 	if !ok {
 		playlistsMap = make(map[string]string)
@@ -67,7 +76,10 @@ func (client *Client) personalPlaylists() ([]*playlist.Playlist, error) {
 	return playlists, nil
 }
 
-func (client *Client) Playlist(target string, channels ...chan interface{}) (*playlist.Playlist, error) {
+// Playlist fetches the given playlist: only those of its tracks
+// added at the given time or later are passed to the channels,
+// but the playlist holds them all
+func (client *Client) Playlist(target string, since time.Time, channels ...chan interface{}) (*playlist.Playlist, error) {
 	var (
 		ctx     = context.Background()
 		id, err = client.personalPlaylistNameToID(target)
@@ -78,14 +90,19 @@ func (client *Client) Playlist(target string, channels ...chan interface{}) (*pl
 
 	fullPlaylist, err := client.GetPlaylist(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, client.playlistError(target, err)
 	}
 
 	playlist := playlistEntity(*fullPlaylist)
 	for {
 		for _, playlistTrack := range fullPlaylist.Tracks.Tracks {
 			track := trackEntity(playlistTrack.Track)
+			track.AddedAt = addedAt(playlistTrack.AddedAt)
 			playlist.Tracks = append(playlist.Tracks, track)
+			// playlists are not sorted by addition
+			if track.AddedAt.Before(since) {
+				continue
+			}
 			for _, ch := range channels {
 				ch <- track
 			}
@@ -100,3 +117,28 @@ func (client *Client) Playlist(target string, channels ...chan interface{}) (*pl
 
 	return playlist, nil
 }
+
+// PlaylistSnapshot returns the snapshot ID of the given playlist, i.e. its
+// version identifier, which changes whenever the playlist does
+func (client *Client) PlaylistSnapshot(target string) (string, error) {
+	id, err := client.personalPlaylistNameToID(target)
+	if err != nil {
+		return "", err
+	}
+
+	fullPlaylist, err := client.GetPlaylist(context.Background(), id, spotify.Fields("snapshot_id"))
+	if err != nil {
+		return "", client.playlistError(target, err)
+	}
+	return fullPlaylist.SnapshotID, nil
+}
+
+// playlistError tells playlists which are not found apart, when authenticated
+// via client credentials, as those may just be private ones
+func (client *Client) playlistError(target string, err error) error {
+	var apiErr spotify.Error
+	if client.app && errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return fmt.Errorf("playlist %s not found, if private: %w", target, ErrUserRequired)
+	}
+	return err
+}
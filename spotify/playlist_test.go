@@ -2,6 +2,7 @@ package spotify
 
 import (
 	"errors"
+	"net/http"
 	"syscall"
 	"testing"
 	"time"
@@ -14,9 +15,10 @@ import (
 
 var fullPlaylist = &spotify.FullPlaylist{
 	SimplePlaylist: spotify.SimplePlaylist{
-		ID:    spotify.ID("123"),
-		Name:  "Playlist",
-		Owner: spotify.User{ID: "User"},
+		ID:         spotify.ID("123"),
+		Name:       "Playlist",
+		Owner:      spotify.User{ID: "User"},
+		SnapshotID: "snapshot",
 	},
 	Tracks: spotify.PlaylistTrackPage{
 		Tracks: []spotify.PlaylistTrack{
@@ -47,13 +49,14 @@ func TestPlaylist(t *testing.T) {
 
 	// testing
 	client := testClient()
-	_, err := client.Playlist(fullPlaylist.Name)
+	_, err := client.Playlist(fullPlaylist.Name, time.Time{})
 	assert.Nil(t, err)
-	playlist, err := client.Playlist(fullPlaylist.Name)
+	playlist, err := client.Playlist(fullPlaylist.Name, time.Time{})
 	assert.Nil(t, err)
 	assert.Equal(t, fullPlaylist.ID.String(), playlist.ID)
 	assert.Equal(t, fullPlaylist.Name, playlist.Name)
 	assert.Equal(t, fullPlaylist.Owner.ID, playlist.Owner)
+	assert.Equal(t, fullPlaylist.SnapshotID, playlist.SnapshotID)
 	assert.Equal(t, len(fullPlaylist.Tracks.Tracks), len(playlist.Tracks))
 	assert.Equal(t, fullPlaylist.Tracks.Tracks[0].Track.ID.String(), playlist.Tracks[0].ID)
 	assert.Equal(t, fullPlaylist.Tracks.Tracks[0].Track.Name, playlist.Tracks[0].Title)
@@ -74,11 +77,43 @@ func TestPlaylistChannel(t *testing.T) {
 	// testing
 	channel := make(chan interface{}, 1)
 	defer close(channel)
-	playlist, err := testClient().Playlist(fullPlaylist.ID.String(), channel)
+	playlist, err := testClient().Playlist(fullPlaylist.ID.String(), time.Time{}, channel)
 	assert.Nil(t, err)
 	assert.Equal(t, playlist.Tracks[0], <-channel)
 }
 
+func TestPlaylistSince(t *testing.T) {
+	channel := make(chan interface{}, 2)
+	defer close(channel)
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyFunc(time.Sleep, func() {}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetPlaylist", func() (*spotify.FullPlaylist, error) {
+			return &spotify.FullPlaylist{
+				Tracks: spotify.PlaylistTrackPage{
+					Tracks: []spotify.PlaylistTrack{
+						{AddedAt: "2026-01-01T00:00:00Z", Track: fullTrack},
+						{AddedAt: "2026-01-03T00:00:00Z", Track: fullTrack},
+						{Track: fullTrack},
+					},
+				},
+			}, nil
+		}).
+		Reset()
+
+	// testing
+	playlist, err := testClient().Playlist("123", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), channel)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(playlist.Tracks))
+	assert.Equal(t, 1, len(channel))
+	assert.Equal(t, playlist.Tracks[1], <-channel)
+	assert.True(t, playlist.Tracks[2].AddedAt.IsZero())
+}
+
 func TestPlaylistCurrentUsersPlaylistsFailure(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
@@ -89,7 +124,7 @@ func TestPlaylistCurrentUsersPlaylistsFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Error(t, util.ErrOnly(testClient().Playlist(fullPlaylist.ID.String())))
+	assert.Error(t, util.ErrOnly(testClient().Playlist(fullPlaylist.ID.String(), time.Time{})))
 }
 
 func TestPlaylistCurrentUsersPlaylistsNextPageFailure(t *testing.T) {
@@ -108,7 +143,7 @@ func TestPlaylistCurrentUsersPlaylistsNextPageFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.True(t, errors.Is(util.ErrOnly(client.Playlist(fullPlaylist.ID.String())), syscall.ECONNREFUSED))
+	assert.True(t, errors.Is(util.ErrOnly(client.Playlist(fullPlaylist.ID.String(), time.Time{})), syscall.ECONNREFUSED))
 }
 
 func TestPlaylistGetPlaylistFailure(t *testing.T) {
@@ -124,7 +159,7 @@ func TestPlaylistGetPlaylistFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.EqualError(t, util.ErrOnly(testClient().Playlist(fullPlaylist.ID.String())), "ko")
+	assert.EqualError(t, util.ErrOnly(testClient().Playlist(fullPlaylist.ID.String(), time.Time{})), "ko")
 }
 
 func TestPlaylistGetPlaylistNextPageFailure(t *testing.T) {
@@ -146,5 +181,81 @@ func TestPlaylistGetPlaylistNextPageFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.True(t, errors.Is(util.ErrOnly(client.Playlist(fullPlaylist.ID.String())), syscall.ECONNREFUSED))
+	assert.True(t, errors.Is(util.ErrOnly(client.Playlist(fullPlaylist.ID.String(), time.Time{})), syscall.ECONNREFUSED))
+}
+
+func TestPlaylistSnapshot(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetPlaylist", func() (*spotify.FullPlaylist, error) {
+			return &spotify.FullPlaylist{SimplePlaylist: spotify.SimplePlaylist{SnapshotID: "snapshot"}}, nil
+		}).
+		Reset()
+
+	// testing
+	snapshot, err := testClient().PlaylistSnapshot(fullPlaylist.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot", snapshot)
+}
+
+func TestPlaylistSnapshotFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "GetPlaylist", func() (*spotify.FullPlaylist, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().PlaylistSnapshot(fullPlaylist.ID.String())), "ko")
+}
+
+func TestPlaylistSnapshotCurrentUsersPlaylistsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().PlaylistSnapshot(fullPlaylist.ID.String())), "ko")
+}
+
+func TestPlaylistApp(t *testing.T) {
+	client := testClient()
+	client.app = true
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return nil, errors.New("ko")
+		}).
+		ApplyMethod(&spotify.Client{}, "GetPlaylist", func() (*spotify.FullPlaylist, error) {
+			return fullPlaylist, nil
+		}).
+		Reset()
+
+	// testing
+	playlist, err := client.Playlist(fullPlaylist.ID.String(), time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, fullPlaylist.Name, playlist.Name)
+}
+
+func TestPlaylistAppNotFound(t *testing.T) {
+	client := testClient()
+	client.app = true
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "GetPlaylist", func() (*spotify.FullPlaylist, error) {
+		return nil, spotify.Error{Message: "Resource not found", Status: http.StatusNotFound}
+	}).Reset()
+
+	// testing
+	assert.ErrorIs(t, util.ErrOnly(client.Playlist("private", time.Time{})), ErrUserRequired)
+	assert.ErrorIs(t, util.ErrOnly(client.PlaylistSnapshot("private")), ErrUserRequired)
 }
@@ -0,0 +1,101 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gosimple/slug"
+	"github.com/streambinder/spotitube/entity"
+	"github.com/streambinder/spotitube/entity/playlist"
+	"github.com/zmb3/spotify/v2"
+)
+
+const playlistItemsBatchSize = 100 // the most items Spotify takes at once
+
+// SearchTrack returns the track best matching the given title and artist,
+// if any: nil is returned if none could be found
+func (client *Client) SearchTrack(title, artist string) (*entity.Track, error) {
+	query := "track:" + title
+	if len(artist) > 0 {
+		query += " artist:" + artist
+	}
+
+	search, err := client.Search(context.Background(), query, spotify.SearchTypeTrack, spotify.Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if search.Tracks == nil || len(search.Tracks.Tracks) == 0 {
+		return nil, nil
+	}
+	return trackEntity(search.Tracks.Tracks[0]), nil
+}
+
+// Publish makes the tracks of the given IDs, in the given order, the content
+// of the personal playlist of the given name (or ID), which gets created
+// if missing: the playlist is returned, with no tracks
+func (client *Client) Publish(target string, ids []string) (*playlist.Playlist, error) {
+	if client.app {
+		return nil, ErrUserRequired
+	}
+
+	ctx := context.Background()
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := client.publishTarget(target, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if published == nil {
+		fullPlaylist, err := client.CreatePlaylistForUser(ctx, user.ID, target, "", false, false)
+		if err != nil {
+			return nil, err
+		}
+		published = playlistEntity(*fullPlaylist)
+	}
+
+	// the first batch replaces whatever the playlist held,
+	// the following ones get appended to it
+	uris := []spotify.URI{}
+	for _, id := range ids {
+		uris = append(uris, spotify.URI("spotify:track:"+id))
+	}
+	snapshot, err := client.ReplacePlaylistItems(ctx, spotify.ID(published.ID), uris[:min(playlistItemsBatchSize, len(uris))]...)
+	if err != nil {
+		return nil, err
+	}
+	for offset := playlistItemsBatchSize; offset < len(ids); offset += playlistItemsBatchSize {
+		batch := []spotify.ID{}
+		for _, id := range ids[offset:min(offset+playlistItemsBatchSize, len(ids))] {
+			batch = append(batch, spotify.ID(id))
+		}
+		if snapshot, err = client.AddTracksToPlaylist(ctx, spotify.ID(published.ID), batch...); err != nil {
+			return nil, err
+		}
+	}
+
+	published.SnapshotID = snapshot
+	return published, nil
+}
+
+// publishTarget returns the personal playlist of the given name (or ID),
+// if any, as long as the given user is allowed to modify it
+func (client *Client) publishTarget(target, user string) (*playlist.Playlist, error) {
+	personalPlaylists, err := client.personalPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range personalPlaylists {
+		if playlist.ID != string(id(target)) && slug.Make(playlist.Name) != slug.Make(target) {
+			continue
+		}
+		if playlist.Owner != user && !playlist.Collaborative {
+			return nil, fmt.Errorf("playlist %s is owned by %s", target, playlist.Owner)
+		}
+		return playlist, nil
+	}
+	return nil, nil
+}
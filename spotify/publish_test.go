@@ -0,0 +1,227 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/zmb3/spotify/v2"
+)
+
+func BenchmarkPublish(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestPublish(&testing.T{})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	var (
+		replaced []spotify.URI
+		added    []spotify.ID
+		ids      []string
+	)
+	for i := 0; i < 150; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{User: spotify.User{ID: "user"}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{Playlists: []spotify.SimplePlaylist{
+				{ID: "other", Name: "Other", Owner: spotify.User{ID: "user"}},
+				{ID: "123", Name: "Playlist", Owner: spotify.User{ID: "user"}},
+				{ID: "456", Name: "Shared", Owner: spotify.User{ID: "other"}, Collaborative: true},
+				{ID: "789", Name: "Followed", Owner: spotify.User{ID: "other"}},
+			}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "ReplacePlaylistItems", func(_ *spotify.Client, _ context.Context, _ spotify.ID, items ...spotify.URI) (string, error) {
+			replaced = append([]spotify.URI{}, items...)
+			return "replaced", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "AddTracksToPlaylist", func(_ *spotify.Client, _ context.Context, _ spotify.ID, ids ...spotify.ID) (string, error) {
+			added = append(added, ids...)
+			return "added", nil
+		}).
+		Reset()
+
+	// testing
+	playlist, err := testClient().Publish("playlist", ids)
+	assert.Nil(t, err)
+	assert.Equal(t, "123", playlist.ID)
+	assert.Equal(t, "added", playlist.SnapshotID)
+	assert.Equal(t, 100, len(replaced))
+	assert.Equal(t, spotify.URI("spotify:track:0"), replaced[0])
+	assert.Equal(t, 50, len(added))
+	assert.Equal(t, spotify.ID("100"), added[0])
+	playlist, err = testClient().Publish("Shared", []string{"1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "456", playlist.ID)
+	assert.Equal(t, []spotify.URI{"spotify:track:1"}, replaced)
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("789", []string{"1"})), "playlist 789 is owned by other")
+}
+
+func TestPublishCreate(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{User: spotify.User{ID: "user"}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CreatePlaylistForUser", func(_ *spotify.Client, _ context.Context, user, name, _ string, public, _ bool) (*spotify.FullPlaylist, error) {
+			assert.Equal(t, "user", user)
+			assert.False(t, public)
+			return &spotify.FullPlaylist{SimplePlaylist: spotify.SimplePlaylist{ID: "created", Name: name}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "ReplacePlaylistItems", func(_ *spotify.Client, _ context.Context, id spotify.ID, items ...spotify.URI) (string, error) {
+			assert.Equal(t, spotify.ID("created"), id)
+			assert.Empty(t, items)
+			return "replaced", nil
+		}).
+		Reset()
+
+	// testing
+	playlist, err := testClient().Publish("Playlist", []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, "created", playlist.ID)
+	assert.Equal(t, "Playlist", playlist.Name)
+	assert.Equal(t, "replaced", playlist.SnapshotID)
+}
+
+func TestPublishApp(t *testing.T) {
+	client := testClient()
+	client.app = true
+
+	// testing
+	assert.ErrorIs(t, util.ErrOnly(client.Publish("Playlist", []string{})), ErrUserRequired)
+}
+
+func TestPublishCurrentUserFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("Playlist", []string{})), "ko")
+}
+
+func TestPublishCurrentUsersPlaylistsFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("Playlist", []string{})), "ko")
+}
+
+func TestPublishCreatePlaylistFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CreatePlaylistForUser", func() (*spotify.FullPlaylist, error) {
+			return nil, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("Playlist", []string{})), "ko")
+}
+
+func TestPublishReplaceFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{Playlists: []spotify.SimplePlaylist{{ID: "123", Name: "Playlist"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "ReplacePlaylistItems", func() (string, error) {
+			return "", errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("Playlist", []string{})), "ko")
+}
+
+func TestPublishAddFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&spotify.Client{}, "CurrentUser", func() (*spotify.PrivateUser, error) {
+			return &spotify.PrivateUser{}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "CurrentUsersPlaylists", func() (*spotify.SimplePlaylistPage, error) {
+			return &spotify.SimplePlaylistPage{Playlists: []spotify.SimplePlaylist{{ID: "123", Name: "Playlist"}}}, nil
+		}).
+		ApplyMethod(&spotify.Client{}, "ReplacePlaylistItems", func() (string, error) {
+			return "replaced", nil
+		}).
+		ApplyMethod(&spotify.Client{}, "AddTracksToPlaylist", func() (string, error) {
+			return "", errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().Publish("Playlist", make([]string, 101))), "ko")
+}
+
+func TestSearchTrack(t *testing.T) {
+	var query string
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "Search", func(_ *spotify.Client, _ context.Context, q string, _ spotify.SearchType, _ ...spotify.RequestOption) (*spotify.SearchResult, error) {
+		query = q
+		return &spotify.SearchResult{Tracks: &spotify.FullTrackPage{Tracks: []spotify.FullTrack{fullTrack}}}, nil
+	}).Reset()
+
+	// testing
+	track, err := testClient().SearchTrack("Title", "Artist")
+	assert.Nil(t, err)
+	assert.Equal(t, fullTrack.ID.String(), track.ID)
+	assert.Equal(t, "track:Title artist:Artist", query)
+	assert.Nil(t, util.ErrOnly(testClient().SearchTrack("Title", "")))
+	assert.Equal(t, "track:Title", query)
+}
+
+func TestSearchTrackNotFound(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+		return &spotify.SearchResult{}, nil
+	}).Reset()
+
+	// testing
+	track, err := testClient().SearchTrack("Title", "Artist")
+	assert.Nil(t, err)
+	assert.Nil(t, track)
+}
+
+func TestSearchTrackFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&spotify.Client{}, "Search", func() (*spotify.SearchResult, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(testClient().SearchTrack("Title", "Artist")), "ko")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/streambinder/spotitube/entity"
 	"github.com/streambinder/spotitube/util"
@@ -35,11 +36,18 @@ func trackEntity(track spotify.FullTrack) *entity.Track {
 		Duration:    int(track.Duration) / 1000,
 		Lyrics:      "",
 		Number:      int(track.TrackNumber),
+		Disc:        int(track.DiscNumber),
 		Year:        util.ErrWrap(0)(strconv.Atoi(strings.Split(track.Album.ReleaseDate, "-")[0])),
 		UpstreamURL: "",
 	}
 }
 
+// addedAt returns the time of the addition of a track to a
+// collection the given timestamp tells, zero if it tells none
+func addedAt(timestamp string) time.Time {
+	return util.ErrWrap(time.Time{})(time.Parse(spotify.TimestampLayout, timestamp))
+}
+
 func (client *Client) Track(target string, channels ...chan interface{}) (*entity.Track, error) {
 	fullTrack, err := client.GetTrack(context.Background(), id(target))
 	if err != nil {
@@ -24,6 +24,21 @@ func formatAlias(alias string) string {
 }
 
 func (lot *Lot) Print(message string) {
+	// idle lots are not worth an event
+	if message != idle {
+		event := lot.window.emit("info", lot.alias, message)
+		if lot.window.logging() {
+			lot.window.log(event)
+		}
+	}
+
+	if lot.window.logging() {
+		lot.window.lock.Lock()
+		lot.data = message
+		lot.window.lock.Unlock()
+		return
+	}
+
 	lot.window.lock.Lock()
 	defer lot.window.lock.Unlock()
 	defer cursor.Bottom()
@@ -54,5 +69,5 @@ func (lot *Lot) write() {
 	if lot.data == idle {
 		dataStyle = idleColor
 	}
-	fmt.Print(lot.style.Sprint(formatAlias(lot.alias)), dataStyle.Sprint(lot.data))
+	fmt.Fprint(lot.window.writer(), lot.style.Sprint(formatAlias(lot.alias)), dataStyle.Sprint(lot.data))
 }
@@ -2,10 +2,14 @@ package anchor
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"atomicgo.dev/cursor"
 	"github.com/fatih/color"
@@ -27,6 +31,19 @@ const (
 	cursorDefault
 )
 
+const (
+	FormatAuto = "auto" // tty if the output is a terminal, text otherwise
+	FormatTTY  = "tty"  // lots and anchors redrawn in place
+	FormatText = "text" // timestamped log lines
+	FormatJSON = "json" // log events, one JSON object per line
+)
+
+var (
+	Formats = []string{FormatAuto, FormatTTY, FormatText, FormatJSON}
+	// tracks are referred to by ID as in "(id: ...)"
+	trackIDPattern = regexp.MustCompile(`\(id: ([^)\s]+)\)`)
+)
+
 type Color color.Attribute
 
 type Window struct {
@@ -34,9 +51,22 @@ type Window struct {
 	lots        []*Lot
 	aliases     map[string]int
 	anchorColor *color.Color
+	format      string
+	output      *os.File // the standard output, unless set
+	listeners   map[int]func(Event)
+	listened    int // listeners ever added, to identify them
 	lock        sync.RWMutex
 }
 
+// Event is whatever the window shows, as a single message
+type Event struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Lot     string `json:"lot,omitempty"`
+	Message string `json:"message"`
+	Track   string `json:"track,omitempty"`
+}
+
 type anchor struct {
 	data   string
 	window *Window
@@ -48,10 +78,55 @@ func New(anchorColors ...color.Attribute) *Window {
 		lots:        []*Lot{},
 		aliases:     make(map[string]int),
 		anchorColor: color.New(util.First(anchorColors, Normal)),
+		format:      autoFormat(os.Stdout),
+		listeners:   make(map[int]func(Event)),
 		lock:        sync.RWMutex{},
 	}
 }
 
+// autoFormat picks the format fitting the given output:
+// cursor movements make sense on terminals only
+func autoFormat(output *os.File) string {
+	if info, err := output.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return FormatTTY
+	}
+	return FormatText
+}
+
+// SetOutput makes the window write to the given file,
+// e.g. to leave the standard output to something else:
+// a nil one restores the standard output
+func (window *Window) SetOutput(output *os.File) {
+	window.lock.Lock()
+	defer window.lock.Unlock()
+	window.output = output
+	cursor.SetTarget(window.writer())
+}
+
+// writer returns the file the window writes to:
+// it expects the window to be locked already
+func (window *Window) writer() *os.File {
+	if window.output != nil {
+		return window.output
+	}
+	return os.Stdout
+}
+
+// SetFormat switches the window to the given output format
+func (window *Window) SetFormat(format string) error {
+	window.lock.Lock()
+	defer window.lock.Unlock()
+	switch format {
+	case FormatAuto:
+		format = autoFormat(window.writer())
+	case FormatTTY, FormatText, FormatJSON:
+	default:
+		return errors.New("unsupported log format: " + format)
+	}
+	window.format = format
+	return nil
+}
+
 func (window *Window) Lot(alias string) *Lot {
 	window.lock.Lock()
 	defer window.lock.Unlock()
@@ -71,16 +146,89 @@ func (window *Window) Lot(alias string) *Lot {
 	}
 	window.aliases[alias] = len(window.lots)
 	window.lots = append(window.lots, lot)
-	fmt.Println()
+	if window.format == FormatTTY {
+		fmt.Fprintln(window.writer())
+	}
 	return lot
 }
 
+// Listen makes the given function receive every event shown
+// by the window, until the returned function gets called
+func (window *Window) Listen(listener func(Event)) func() {
+	window.lock.Lock()
+	defer window.lock.Unlock()
+	id := window.listened
+	window.listened++
+	window.listeners[id] = listener
+	return func() {
+		window.lock.Lock()
+		defer window.lock.Unlock()
+		delete(window.listeners, id)
+	}
+}
+
 func (window *Window) Printf(format string, a ...any) {
-	window.print(false, fmt.Sprintf(format, a...))
+	event := window.emit("info", "", fmt.Sprintf(format, a...))
+	if window.logging() {
+		window.log(event)
+		return
+	}
+	window.print(false, event.Message)
 }
 
 func (window *Window) AnchorPrintf(format string, a ...any) {
-	window.print(true, window.anchorColor.Sprintf(format, a...))
+	event := window.emit("error", "", fmt.Sprintf(format, a...))
+	if window.logging() {
+		window.log(event)
+		return
+	}
+	window.print(true, window.anchorColor.Sprint(event.Message))
+}
+
+// emit passes the event made of the given message
+// to every listener and returns it
+func (window *Window) emit(level, alias, message string) Event {
+	event := Event{time.Now().Format(time.RFC3339), level, alias, message, ""}
+	if match := trackIDPattern.FindStringSubmatch(message); match != nil {
+		event.Track = match[1]
+	}
+
+	window.lock.RLock()
+	listeners := make([]func(Event), 0, len(window.listeners))
+	for _, listener := range window.listeners {
+		listeners = append(listeners, listener)
+	}
+	window.lock.RUnlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+	return event
+}
+
+// logging tells whether messages are logged line by line,
+// rather than drawn on the window
+func (window *Window) logging() bool {
+	window.lock.RLock()
+	defer window.lock.RUnlock()
+	return window.format != FormatTTY
+}
+
+// log writes the given event as a line on its own,
+// either plain and timestamped or as JSON
+func (window *Window) log(event Event) {
+	window.lock.Lock()
+	defer window.lock.Unlock()
+
+	if window.format == FormatJSON {
+		fmt.Fprintln(window.writer(), string(util.ErrWrap([]byte{})(json.Marshal(event))))
+		return
+	}
+
+	message := event.Message
+	if len(event.Lot) > 0 {
+		message = formatAlias(event.Lot) + message
+	}
+	fmt.Fprintln(window.writer(), event.Time, strings.ToUpper(event.Level), message)
 }
 
 func (window *Window) up(lines ...int) {
@@ -98,7 +246,7 @@ func (window *Window) shift(lines int) {
 		return
 	}
 
-	fmt.Println()
+	fmt.Fprintln(window.writer())
 	window.up()
 
 	if lines == cursorAnchor {
@@ -112,7 +260,7 @@ func (window *Window) shift(lines int) {
 			window.lots[len(window.lots)-1-i].write()
 		} else {
 			i := i - len(window.lots)
-			fmt.Print(window.anchors[len(window.anchors)-1-i].data)
+			fmt.Fprint(window.writer(), window.anchors[len(window.anchors)-1-i].data)
 		}
 		window.up()
 	}
@@ -129,15 +277,17 @@ func (window *Window) print(doAnchor bool, data string) {
 	} else {
 		window.shift(cursorDefault)
 	}
-	fmt.Print(data)
+	fmt.Fprint(window.writer(), data)
 }
 
 func (window *Window) Reads(label string, a ...interface{}) (value string) {
 	window.lock.Lock()
 	defer window.lock.Unlock()
-	defer cursor.Bottom()
-	window.shift(cursorDefault)
-	fmt.Printf(label+" ", a...)
+	if window.format == FormatTTY {
+		defer cursor.Bottom()
+		window.shift(cursorDefault)
+	}
+	fmt.Fprintf(window.writer(), label+" ", a...)
 	value = util.ErrWrap("")(bufio.NewReader(os.Stdin).ReadString('\n'))
 	value = strings.TrimSpace(value)
 	value = strings.Trim(value, "\n")
@@ -1,8 +1,10 @@
 package anchor
 
 import (
+	"encoding/json"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/streambinder/spotitube/util"
@@ -34,10 +36,9 @@ func TestWindow(t *testing.T) {
 	assert.Nil(t, util.ErrOnly(stdinFile.Write([]byte("input\n"))))
 	os.Stdin = stdinFile
 
-	var (
-		window = New(Normal)
-		lot    = window.Lot("lot")
-	)
+	window := New(Normal)
+	assert.Nil(t, window.SetFormat(FormatTTY))
+	lot := window.Lot("lot")
 	lot.Printf("lot text 1")
 	window.Printf("default text 1")
 	window.AnchorPrintf("anchor text")
@@ -59,3 +60,121 @@ func TestWindow(t *testing.T) {
 	assert.Contains(t, string(output), "closure")
 	assert.Contains(t, string(output), "prompt")
 }
+
+// testWindowLog returns what a window in the given format
+// writes on the standard output, for a bit of everything
+func testWindowLog(t *testing.T, format string) string {
+	stdout := os.Stdout
+	defer func() {
+		os.Stdout = stdout
+	}()
+	reader, writer, err := os.Pipe()
+	assert.Nil(t, err)
+	os.Stdout = writer
+
+	window := New(Red)
+	assert.Nil(t, window.SetFormat(format))
+	testWindowShow(window)
+	assert.Nil(t, writer.Close())
+
+	output, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	return string(output)
+}
+
+// testWindowShow shows a bit of everything on the given window
+func testWindowShow(window *Window) {
+	lot := window.Lot("lot")
+	lot.Printf("lot text")
+	lot.Wipe()
+	lot.Close()
+	window.Printf("default text")
+	window.AnchorPrintf("Title by Artist (id: 123) not found")
+}
+
+func TestWindowText(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(testWindowLog(t, FormatText), "\n"), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Regexp(t, `^\S+ INFO \(lot\) lot text$`, lines[0])
+	assert.Regexp(t, `^\S+ INFO \(lot\) done$`, lines[1])
+	assert.Regexp(t, `^\S+ INFO default text$`, lines[2])
+	assert.Regexp(t, `^\S+ ERROR Title by Artist \(id: 123\) not found$`, lines[3])
+	assert.NotContains(t, strings.Join(lines, "\n"), "\x1b")
+}
+
+func TestWindowJSON(t *testing.T) {
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSuffix(testWindowLog(t, FormatJSON), "\n"), "\n") {
+		var event Event
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		assert.NotEmpty(t, event.Time)
+		event.Time = ""
+		events = append(events, event)
+	}
+	assert.Equal(t, []Event{
+		{"", "info", "lot", "lot text", ""},
+		{"", "info", "lot", "done", ""},
+		{"", "info", "", "default text", ""},
+		{"", "error", "", "Title by Artist (id: 123) not found", "123"},
+	}, events)
+}
+
+func TestSetFormat(t *testing.T) {
+	stdout := os.Stdout
+	defer func() {
+		os.Stdout = stdout
+	}()
+	_, writer, err := os.Pipe()
+	assert.Nil(t, err)
+	defer writer.Close()
+	os.Stdout = writer
+
+	window := New()
+	assert.Nil(t, window.SetFormat(FormatTTY))
+	assert.Nil(t, window.SetFormat(FormatAuto))
+	assert.Equal(t, FormatText, window.format)
+	assert.EqualError(t, window.SetFormat("xml"), "unsupported log format: xml")
+}
+
+func TestSetOutput(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	assert.Nil(t, err)
+
+	window := New()
+	window.SetOutput(writer)
+	assert.Nil(t, window.SetFormat(FormatAuto))
+	assert.Equal(t, FormatText, window.format)
+	window.Printf("default text")
+	window.SetOutput(nil)
+	assert.Equal(t, os.Stdout, window.writer())
+	assert.Nil(t, writer.Close())
+
+	output, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Contains(t, string(output), "default text")
+}
+
+func TestAutoFormat(t *testing.T) {
+	// null device is a character one, as terminals are
+	null, err := os.Open(os.DevNull)
+	assert.Nil(t, err)
+	defer null.Close()
+	assert.Equal(t, FormatTTY, autoFormat(null))
+}
+
+func TestListen(t *testing.T) {
+	var (
+		events []Event
+		window = New()
+	)
+	assert.Nil(t, window.SetFormat(FormatText))
+	stop := window.Listen(func(event Event) {
+		events = append(events, event)
+	})
+	testWindowShow(window)
+	stop()
+	window.Printf("unheard")
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, "lot", events[0].Lot)
+	assert.Equal(t, "123", events[3].Track)
+}
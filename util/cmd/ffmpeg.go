@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -17,15 +18,17 @@ import (
 
 type FFmpegCmd struct{}
 
+// FFmpeg returns a wrapper around the ffmpeg binary: its processes
+// get killed as soon as the context each call is given is done
 func FFmpeg() FFmpegCmd {
 	return FFmpegCmd{}
 }
 
-func (FFmpegCmd) VolumeDetect(path string) (float64, error) {
+func (FFmpegCmd) VolumeDetect(ctx context.Context, path string) (float64, error) {
 	var (
 		output bytes.Buffer
 		regex  = regexp.MustCompile(`max_volume:\s[\-\.0-9]+\sdB`)
-		cmd    = exec.Command("ffmpeg",
+		cmd    = exec.CommandContext(ctx, "ffmpeg",
 			"-i", path,
 			"-af", "volumedetect",
 			"-f", "null",
@@ -49,7 +52,7 @@ func (FFmpegCmd) VolumeDetect(path string) (float64, error) {
 	return volume, nil
 }
 
-func (FFmpegCmd) VolumeAdd(path string, delta float64) error {
+func (FFmpegCmd) VolumeAdd(ctx context.Context, path string, delta float64) error {
 	if delta == 0 {
 		return nil
 	}
@@ -57,7 +60,7 @@ func (FFmpegCmd) VolumeAdd(path string, delta float64) error {
 	var (
 		output bytes.Buffer
 		temp   = util.FileBaseStem(path) + ".norm" + filepath.Ext(path)
-		cmd    = exec.Command("ffmpeg", // nolint:gosec
+		cmd    = exec.CommandContext(ctx, "ffmpeg", // nolint:gosec
 			"-i", path,
 			"-af", fmt.Sprintf("volume=%.1fdB", math.Abs(delta)),
 			"-y", temp,
@@ -66,7 +69,79 @@ func (FFmpegCmd) VolumeAdd(path string, delta float64) error {
 	cmd.Stdout = &output
 	cmd.Stderr = &output
 	if err := cmd.Run(); err != nil {
+		// do not leave half-normalized blobs behind
+		util.ErrSuppress(os.Remove(temp))
 		return errors.New(output.String())
 	}
 	return os.Rename(temp, path)
 }
+
+// Metadata rewrites the whole set of metadata of the given file,
+// possibly attaching the image at the given cover path to it
+func (FFmpegCmd) Metadata(ctx context.Context, path string, metadata map[string]string, cover string) error {
+	input, err := os.CreateTemp("", "ffmetadata")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(input.Name())
+
+	escaper := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+	data := ";FFMETADATA1\n"
+	for key, value := range metadata {
+		data += escaper.Replace(key) + "=" + escaper.Replace(value) + "\n"
+	}
+	if _, err := input.WriteString(data); err != nil {
+		input.Close()
+		return err
+	}
+	if err := input.Close(); err != nil {
+		return err
+	}
+
+	var (
+		output bytes.Buffer
+		temp   = util.FileBaseStem(path) + ".meta" + filepath.Ext(path)
+		args   = []string{"-i", path, "-i", input.Name()}
+	)
+	if len(cover) > 0 {
+		args = append(args, "-i", cover, "-map", "0:a", "-map", "2", "-disposition:v", "attached_pic")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args,
+		"-map_metadata", "1",
+		"-map_metadata:s:a", "1:g",
+		"-c", "copy",
+		"-y", temp,
+	)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...) // nolint:gosec
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		util.ErrSuppress(os.Remove(temp))
+		return errors.New(output.String())
+	}
+	return os.Rename(temp, path)
+}
+
+// Picture extracts the image attached to the given file
+func (FFmpegCmd) Picture(ctx context.Context, path string) ([]byte, error) {
+	var (
+		output bytes.Buffer
+		stderr bytes.Buffer
+		cmd    = exec.CommandContext(ctx, "ffmpeg",
+			"-i", path,
+			"-map", "0:v:0",
+			"-c", "copy",
+			"-f", "image2pipe",
+			"-",
+		)
+	)
+	cmd.Stdout = &output
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(stderr.String())
+	}
+	return output.Bytes(), nil
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -31,6 +35,8 @@ func BenchmarkFFmpeg(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		TestVolumeDetect(&testing.T{})
 		TestVolumeAdd(&testing.T{})
+		TestMetadata(&testing.T{})
+		TestPicture(&testing.T{})
 	}
 }
 
@@ -41,7 +47,7 @@ func TestVolumeDetect(t *testing.T) {
 	}).Reset()
 
 	// testing
-	delta, err := FFmpeg().VolumeDetect("/dev/null")
+	delta, err := FFmpeg().VolumeDetect(context.Background(), "/dev/null")
 	assert.Nil(t, err)
 	assert.Equal(t, -5.0, delta)
 }
@@ -53,7 +59,7 @@ func TestVolumeDetectFFmpegFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Error(t, util.ErrOnly(FFmpeg().VolumeDetect("/dev/null")))
+	assert.Error(t, util.ErrOnly(FFmpeg().VolumeDetect(context.Background(), "/dev/null")))
 }
 
 func TestVolumeDetectParseFloatFailure(t *testing.T) {
@@ -68,7 +74,7 @@ func TestVolumeDetectParseFloatFailure(t *testing.T) {
 		Reset()
 
 	// testing
-	assert.Error(t, util.ErrOnly(FFmpeg().VolumeDetect("/dev/null")))
+	assert.Error(t, util.ErrOnly(FFmpeg().VolumeDetect(context.Background(), "/dev/null")))
 }
 
 func TestVolumeAdd(t *testing.T) {
@@ -78,11 +84,11 @@ func TestVolumeAdd(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.EqualError(t, FFmpeg().VolumeAdd("/dev/null", -1), "rename /dev/null.norm /dev/null: no such file or directory")
+	assert.EqualError(t, FFmpeg().VolumeAdd(context.Background(), "/dev/null", -1), "rename /dev/null.norm /dev/null: no such file or directory")
 }
 
 func TestVolumeAddNothing(t *testing.T) {
-	assert.Nil(t, FFmpeg().VolumeAdd("/dev/null", 0))
+	assert.Nil(t, FFmpeg().VolumeAdd(context.Background(), "/dev/null", 0))
 }
 
 func TestVolumeAddFFmpegFailure(t *testing.T) {
@@ -92,5 +98,97 @@ func TestVolumeAddFFmpegFailure(t *testing.T) {
 	}).Reset()
 
 	// testing
-	assert.Error(t, FFmpeg().VolumeAdd("/dev/null", -1))
+	assert.Error(t, FFmpeg().VolumeAdd(context.Background(), "/dev/null", -1))
+}
+
+func TestMetadata(t *testing.T) {
+	var metadata string
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		metadata = string(util.ErrWrap([]byte{})(os.ReadFile(cmd.Args[4])))
+		assert.Contains(t, cmd.Args, "attached_pic")
+		return os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte{}, 0o644)
+	}).Reset()
+
+	// testing
+	path := filepath.Join(t.TempDir(), "track.opus")
+	assert.Nil(t, os.WriteFile(path, []byte{}, 0o644))
+	assert.Nil(t, FFmpeg().Metadata(context.Background(), path, map[string]string{"title": "a=b;c#d\\e\nf"}, "cover.jpg"))
+	assert.Equal(t, ";FFMETADATA1\ntitle=a\\=b\\;c\\#d\\\\e\\\nf\n", metadata)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(path), "track.meta.opus"))
+}
+
+func TestMetadataNoCover(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		assert.NotContains(t, cmd.Args, "attached_pic")
+		return nil
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FFmpeg().Metadata(context.Background(), "/dev/null", map[string]string{}, ""), "rename /dev/null.meta /dev/null: no such file or directory")
+}
+
+func TestMetadataCreateTempFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.CreateTemp, func() (*os.File, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FFmpeg().Metadata(context.Background(), "/dev/null", map[string]string{}, ""), "ko")
+}
+
+func TestMetadataWriteFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&os.File{}, "Write", func() (int, error) {
+		return 0, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FFmpeg().Metadata(context.Background(), "/dev/null", map[string]string{}, ""), "ko")
+}
+
+func TestMetadataCloseFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyPrivateMethod(reflect.TypeOf(os.File{}).Field(0).Type, "close", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FFmpeg().Metadata(context.Background(), "/dev/null", map[string]string{}, ""), "ko")
+}
+
+func TestMetadataFFmpegFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		util.ErrSuppress(util.ErrOnly(cmd.Stderr.Write([]byte("ko"))))
+		return errors.New("exit status 1")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FFmpeg().Metadata(context.Background(), "/dev/null", map[string]string{}, ""), "ko")
+}
+
+func TestPicture(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		return util.ErrOnly(cmd.Stdout.Write([]byte("picture")))
+	}).Reset()
+
+	// testing
+	picture, err := FFmpeg().Picture(context.Background(), "/dev/null")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("picture"), picture)
+}
+
+func TestPictureFFmpegFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		util.ErrSuppress(util.ErrOnly(cmd.Stderr.Write([]byte("ko"))))
+		return errors.New("exit status 1")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(FFmpeg().Picture(context.Background(), "/dev/null")), "ko")
 }
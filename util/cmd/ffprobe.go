@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/streambinder/spotitube/util"
+)
+
+type FFprobeCmd struct{}
+
+// Probe describes the actual content of a media file
+type Probe struct {
+	Duration float64 // in seconds
+	Streams  []ProbeStream
+	Tags     map[string]string // by lowercase key, either of the container or of its streams
+}
+
+type ProbeStream struct {
+	Type       string // audio, video, ...
+	Codec      string
+	Channels   int
+	SampleRate int
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType  string            `json:"codec_type"`
+		CodecName  string            `json:"codec_name"`
+		Channels   int               `json:"channels"`
+		SampleRate string            `json:"sample_rate"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"streams"`
+}
+
+// FFprobe returns a wrapper around the ffprobe binary: its processes
+// get killed as soon as the context each call is given is done
+func FFprobe() FFprobeCmd {
+	return FFprobeCmd{}
+}
+
+func (FFprobeCmd) Probe(ctx context.Context, path string) (*Probe, error) {
+	var (
+		output bytes.Buffer
+		stderr bytes.Buffer
+		cmd    = exec.CommandContext(ctx, "ffprobe",
+			"-v", "error",
+			"-print_format", "json",
+			"-show_format",
+			"-show_streams",
+			path,
+		)
+	)
+	cmd.Stdout = &output
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(stderr.String())
+	}
+
+	var data ffprobeOutput
+	if err := json.Unmarshal(output.Bytes(), &data); err != nil {
+		return nil, err
+	}
+
+	duration, err := strconv.ParseFloat(data.Format.Duration, 64)
+	if err != nil {
+		return nil, errors.New("cannot parse duration for given track")
+	}
+
+	probe := &Probe{Duration: duration, Tags: make(map[string]string)}
+	for _, stream := range data.Streams {
+		probe.Streams = append(probe.Streams, ProbeStream{
+			stream.CodecType,
+			stream.CodecName,
+			stream.Channels,
+			util.ErrWrap(0)(strconv.Atoi(stream.SampleRate)),
+		})
+		// some containers (e.g. Ogg) hold tags in the
+		// audio stream rather than in the container itself
+		if stream.CodecType != "audio" {
+			continue
+		}
+		for key, value := range stream.Tags {
+			probe.Tags[strings.ToLower(key)] = value
+		}
+	}
+	for key, value := range data.Format.Tags {
+		probe.Tags[strings.ToLower(key)] = value
+	}
+	return probe, nil
+}
+
+// Audio returns whether the probed file carries any audio stream
+func (probe *Probe) Audio() bool {
+	for _, stream := range probe.Streams {
+		if stream.Type == "audio" && stream.Channels > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/streambinder/spotitube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const probeOutput = `{
+    "streams": [
+        {
+            "index": 0,
+            "codec_name": "mp3",
+            "codec_type": "audio",
+            "sample_rate": "44100",
+            "channels": 2,
+            "tags": {
+                "TITLE": "Stream title",
+                "ALBUM": "Album"
+            }
+        },
+        {
+            "index": 1,
+            "codec_name": "mjpeg",
+            "codec_type": "video",
+            "tags": {
+                "comment": "Cover (front)"
+            }
+        }
+    ],
+    "format": {
+        "filename": "fname.mp3",
+        "duration": "180.480000",
+        "tags": {
+            "title": "Title",
+            "ARTIST": "Artist"
+        }
+    }
+}`
+
+func BenchmarkFFprobe(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		TestProbe(&testing.T{})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		return util.ErrOnly(cmd.Stdout.Write([]byte(probeOutput)))
+	}).Reset()
+
+	// testing
+	probe, err := FFprobe().Probe(context.Background(), "/dev/null")
+	assert.Nil(t, err)
+	assert.Equal(t, 180.48, probe.Duration)
+	assert.Len(t, probe.Streams, 2)
+	assert.Equal(t, ProbeStream{"audio", "mp3", 2, 44100}, probe.Streams[0])
+	assert.True(t, probe.Audio())
+	assert.Equal(t, map[string]string{"title": "Title", "artist": "Artist", "album": "Album"}, probe.Tags)
+}
+
+func TestProbeNoAudio(t *testing.T) {
+	assert.False(t, (&Probe{Streams: []ProbeStream{{Type: "video"}}}).Audio())
+}
+
+func TestProbeFFprobeFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.Error(t, util.ErrOnly(FFprobe().Probe(context.Background(), "/dev/null")))
+}
+
+func TestProbeUnmarshalFailure(t *testing.T) {
+	// stub ffprobe binary printing malformed output
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "ffprobe"), []byte("#!/bin/sh\necho '{\"format\":'\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	// testing
+	assert.EqualError(t, util.ErrOnly(FFprobe().Probe(context.Background(), "/dev/null")), "unexpected end of JSON input")
+}
+
+func TestProbeParseFloatFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.NewPatches().
+		ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+			return util.ErrOnly(cmd.Stdout.Write([]byte(probeOutput)))
+		}).
+		ApplyFunc(strconv.ParseFloat, func() (float64, error) {
+			return 0, errors.New("ko")
+		}).
+		Reset()
+
+	// testing
+	assert.Error(t, util.ErrOnly(FFprobe().Probe(context.Background(), "/dev/null")))
+}
@@ -2,23 +2,35 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/streambinder/spotitube/util"
 )
 
-func YouTubeDl(url, path string) error {
+func YouTubeDl(ctx context.Context, url, path string) error {
 	var (
 		output bytes.Buffer
 		ext    = filepath.Ext(path)[1:]
 		stem   = strings.TrimSuffix(util.FileBaseStem(path), "."+ext)
-		cmd    = exec.Command("yt-dlp",
+		format = ext
+	)
+	// yt-dlp names codecs rather than containers
+	// whenever the two do not match
+	if format == "ogg" {
+		format = "vorbis"
+	}
+
+	var (
+		cmd = exec.CommandContext(ctx, "yt-dlp",
 			"--format", "bestaudio",
 			"--extract-audio",
-			"--audio-format", ext,
+			"--audio-format", format,
 			"--audio-quality", "0",
 			"--output", stem+".%(ext)s",
 			"--continue",
@@ -30,7 +42,19 @@ func YouTubeDl(url, path string) error {
 	)
 	cmd.Stdout = &output
 	cmd.Stderr = &output
+	// other assets may share the same stem
+	siblings := util.ErrWrap([]string{})(filepath.Glob(stem + ".*"))
 	if err := cmd.Run(); err != nil {
+		// an interrupted download leaves partial
+		// fragments behind which are of no use
+		if ctx.Err() != nil {
+			for _, fragment := range util.ErrWrap([]string{})(filepath.Glob(stem + ".*")) {
+				if !slices.Contains(siblings, fragment) {
+					util.ErrSuppress(os.Remove(fragment))
+				}
+			}
+			return ctx.Err()
+		}
 		return errors.New(output.String())
 	}
 	return nil
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
@@ -20,7 +23,19 @@ func TestYouTubeDlDownload(t *testing.T) {
 	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func() error { return nil }).Reset()
 
 	// testing
-	assert.Nil(t, YouTubeDl("http://localhost", "fname.txt"))
+	assert.Nil(t, YouTubeDl(context.Background(), "http://localhost", "fname.txt"))
+}
+
+func TestYouTubeDlDownloadVorbis(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func(cmd *exec.Cmd) error {
+		assert.Contains(t, cmd.Args, "vorbis")
+		assert.NotContains(t, cmd.Args, "ogg")
+		return nil
+	}).Reset()
+
+	// testing
+	assert.Nil(t, YouTubeDl(context.Background(), "http://localhost", "fname.ogg"))
 }
 
 func TestYouTubeDlDownloadFailure(t *testing.T) {
@@ -28,5 +43,27 @@ func TestYouTubeDlDownloadFailure(t *testing.T) {
 	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func() error { return errors.New("ko") }).Reset()
 
 	// testing
-	assert.Error(t, YouTubeDl("http://localhost", "fname.txt"))
+	assert.Error(t, YouTubeDl(context.Background(), "http://localhost", "fname.txt"))
+}
+
+func TestYouTubeDlDownloadInterrupted(t *testing.T) {
+	var (
+		path           = filepath.Join(t.TempDir(), "fname.mp3")
+		sibling        = filepath.Join(filepath.Dir(path), "fname.txt")
+		fragment       = filepath.Join(filepath.Dir(path), "fname.webm.part")
+		ctx, ctxCancel = context.WithCancel(context.Background())
+	)
+	assert.Nil(t, os.WriteFile(sibling, []byte{}, 0o600))
+
+	// monkey patching
+	defer gomonkey.ApplyMethod(&exec.Cmd{}, "Run", func() error {
+		ctxCancel()
+		assert.Nil(t, os.WriteFile(fragment, []byte{}, 0o600))
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.ErrorIs(t, YouTubeDl(ctx, "http://localhost", path), context.Canceled)
+	assert.NoFileExists(t, fragment)
+	assert.FileExists(t, sibling)
 }
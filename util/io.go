@@ -2,18 +2,31 @@ package util
 
 import (
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/adrg/xdg"
 )
 
+const ProfilesDirname = "profiles"
+
+var (
+	profile        string // the default one, if empty
+	profilePattern = regexp.MustCompile(`^[\w-]+$`)
+)
+
 func FileMoveOrCopy(source, destination string, overwrite ...bool) error {
 	if _, err := os.Stat(destination); err == nil && !First(overwrite, false) {
 		return errors.New("destination already exists: " + destination)
 	}
 
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return err
+	}
+
 	if err := os.Rename(source, destination); err == nil {
 		return nil
 	}
@@ -41,3 +54,52 @@ func CacheDirectory() string {
 func CacheFile(filename string) string {
 	return filepath.Join(CacheDirectory(), filename)
 }
+
+// SetProfile makes the given profile the one in use, the default one if empty
+func SetProfile(name string) error {
+	if len(name) > 0 && !profilePattern.MatchString(name) {
+		return errors.New("invalid profile name: " + name)
+	}
+	profile = name
+	return nil
+}
+
+func Profile() string {
+	return profile
+}
+
+// Profiles returns the names of the profiles which have been used so far,
+// besides the default one
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(CacheFile(ProfilesDirname))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	profiles := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && profilePattern.MatchString(entry.Name()) {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	return profiles, nil
+}
+
+// ProfileFile returns the path of the given cache file for the profile in use:
+// the default profile keeps its files straight in the cache directory
+func ProfileFile(filename string) string {
+	if len(profile) == 0 {
+		return CacheFile(filename)
+	}
+	return CacheFile(filepath.Join(ProfilesDirname, profile, filename))
+}
+
+// MusicDirectory returns the default output directory for the profile in use
+func MusicDirectory() string {
+	if len(profile) == 0 {
+		return xdg.UserDirs.Music
+	}
+	return filepath.Join(xdg.UserDirs.Music, profile)
+}
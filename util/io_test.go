@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/adrg/xdg"
@@ -29,6 +30,26 @@ func TestFileMove(t *testing.T) {
 	assert.Nil(t, os.Remove(dst))
 }
 
+func TestFileMoveNested(t *testing.T) {
+	var (
+		src = filepath.Join(t.TempDir(), "src")
+		dst = filepath.Join(t.TempDir(), "Artist", "Album", "dst")
+	)
+	assert.Nil(t, os.WriteFile(src, []byte{}, 0o600))
+	assert.Nil(t, FileMoveOrCopy(src, dst))
+	assert.FileExists(t, dst)
+}
+
+func TestFileMoveMkdirFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.MkdirAll, func() error {
+		return errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, FileMoveOrCopy("/a", "/b/c"), "ko")
+}
+
 func TestFileCopy(t *testing.T) {
 	// monkey patching
 	defer gomonkey.NewPatches().
@@ -136,3 +157,48 @@ func TestCacheFileFallback(t *testing.T) {
 	// testing
 	assert.Equal(t, "/tmp/spotitube/fname.txt", CacheFile("fname.txt"))
 }
+
+func TestProfile(t *testing.T) {
+	t.Cleanup(func() { ErrSuppress(SetProfile("")) })
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(xdg.CacheFile, func() (string, error) {
+		return "/dir/spotitube", nil
+	}).Reset()
+
+	// testing
+	assert.Equal(t, "/dir/spotitube/fname.txt", ProfileFile("fname.txt"))
+	assert.Equal(t, xdg.UserDirs.Music, MusicDirectory())
+	assert.Nil(t, SetProfile("alice"))
+	assert.Equal(t, "alice", Profile())
+	assert.Equal(t, "/dir/spotitube/profiles/alice/fname.txt", ProfileFile("fname.txt"))
+	assert.Equal(t, filepath.Join(xdg.UserDirs.Music, "alice"), MusicDirectory())
+	assert.EqualError(t, SetProfile("../bob"), "invalid profile name: ../bob")
+	assert.Equal(t, "alice", Profile())
+}
+
+func TestProfiles(t *testing.T) {
+	cache := t.TempDir()
+
+	// monkey patching
+	defer gomonkey.ApplyFunc(xdg.CacheFile, func() (string, error) {
+		return cache, nil
+	}).Reset()
+
+	// testing
+	assert.Equal(t, []string{}, ErrWrap([]string(nil))(Profiles()))
+	assert.Nil(t, os.MkdirAll(filepath.Join(cache, ProfilesDirname, "alice"), 0o755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(cache, ProfilesDirname, "bob"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(cache, ProfilesDirname, "fname.txt"), []byte{}, 0o644))
+	assert.Equal(t, []string{"alice", "bob"}, ErrWrap([]string(nil))(Profiles()))
+}
+
+func TestProfilesFailure(t *testing.T) {
+	// monkey patching
+	defer gomonkey.ApplyFunc(os.ReadDir, func() ([]fs.DirEntry, error) {
+		return nil, errors.New("ko")
+	}).Reset()
+
+	// testing
+	assert.EqualError(t, ErrOnly(Profiles()), "ko")
+}